@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"kafka-board/confluentRegistryAPI"
+	"kafka-board/registryBackup"
+	"kafka-board/registrySync"
+	"kafka-board/schemaDirectory"
+)
+
+// runCommand runs a command line subcommand instead of the server and returns the
+// exit code:
+//
+//	kafka-board export [-registry name] <directory or .tar.gz>
+//	kafka-board restore [-registry name] <directory or .tar.gz>
+//	kafka-board sync -from name -to name [-dry-run] [-incremental] [subject pattern...]
+//	kafka-board diff [-registry name] [-naming convention] [-json] <directory>
+func runCommand(args []string) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch args[0] {
+	case "export":
+		err = runExport(ctx, args[1:])
+	case "restore":
+		err = runRestore(ctx, args[1:])
+	case "sync":
+		err = runSync(ctx, args[1:])
+	case "diff":
+		err = runDiff(ctx, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, expected export, restore, sync or diff\n", args[0])
+		return 2
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// runExport writes a backup of a registry to a directory or tarball
+func runExport(ctx context.Context, args []string) error {
+	registryAPI, backupPath, err := parseBackupFlags("export", args)
+	if err != nil {
+		return err
+	}
+
+	backup, err := registryBackup.Export(ctx, registryAPI, registryAPI.Name())
+	if err != nil {
+		return err
+	}
+	if err := registryBackup.Write(backup, backupPath); err != nil {
+		return err
+	}
+
+	versions, deleted := backup.VersionCount()
+	fmt.Printf("Exported %d subjects and %d versions (%d soft-deleted) from %s to %s\n",
+		len(backup.Subjects), versions, deleted, registryAPI.Name(), backupPath)
+	return nil
+}
+
+// runRestore restores a backup into an empty registry
+func runRestore(ctx context.Context, args []string) error {
+	registryAPI, backupPath, err := parseBackupFlags("restore", args)
+	if err != nil {
+		return err
+	}
+
+	backup, err := registryBackup.Read(backupPath)
+	if err != nil {
+		return err
+	}
+
+	report, err := registryBackup.Restore(ctx, registryAPI, backup)
+	if err != nil {
+		return fmt.Errorf("%w (%d versions imported before the error)", err, report.Versions)
+	}
+
+	fmt.Printf("Restored %d subjects and %d versions (%d soft-deleted), %d configs and %d modes from %s into %s\n",
+		report.Subjects, report.Versions, report.Deleted, report.Configs, report.Modes, backupPath, registryAPI.Name())
+	return nil
+}
+
+// parseBackupFlags returns the registry picked with -registry, the first one by
+// default, and the backup path
+func parseBackupFlags(command string, args []string) (*confluentRegistryAPI.RegistryAPI, string, error) {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	registryName := flags.String("registry", "", "name of the registry, the first configured one by default")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: kafka-board %s [-registry name] <directory or .tar.gz>\n", command)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, "", err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return nil, "", flag.ErrHelp
+	}
+
+	registryAPI, err := selectRegistry(*registryName)
+	return registryAPI, flags.Arg(0), err
+}
+
+// runSync copies the subjects matching the patterns from one registry to another,
+// or only prints what it would copy with -dry-run
+func runSync(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	from := flags.String("from", "", "name of the source registry")
+	to := flags.String("to", "", "name of the target registry")
+	dryRun := flags.Bool("dry-run", false, "print the differences without writing to the target")
+	incremental := flags.Bool("incremental", false, "only compare the latest version of each target subject and push the versions after it")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: kafka-board sync -from name -to name [-dry-run] [-incremental] [subject pattern...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" || *from == *to {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	options := registrySync.Options{Subjects: flags.Args(), Incremental: *incremental}
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	registryAPIs, err := confluentRegistryAPI.ReturnRegistryAPIs(logger)
+	if err != nil {
+		return fmt.Errorf("could not configure registry API: %w", err)
+	}
+	source, err := findRegistry(registryAPIs, *from)
+	if err != nil {
+		return err
+	}
+	target, err := findRegistry(registryAPIs, *to)
+	if err != nil {
+		return err
+	}
+
+	plan, err := registrySync.Diff(ctx, source, target, options)
+	if err != nil {
+		return err
+	}
+	if err := plan.WriteDiff(os.Stdout); err != nil {
+		return err
+	}
+	if *dryRun {
+		return nil
+	}
+
+	report := registrySync.Apply(ctx, target, plan)
+	if err := report.WriteSummary(os.Stdout); err != nil {
+		return err
+	}
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("%d subjects failed to sync", failed)
+	}
+	return nil
+}
+
+// runDiff compares a directory of schema files with the registry and fails when a
+// proposed schema would be refused, so it can gate merges in CI
+func runDiff(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	registryName := flags.String("registry", "", "name of the registry, the first configured one by default")
+	naming := flags.String("naming", schemaDirectory.DefaultNaming, "subject naming convention, {name} is replaced by the file name without extension")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: kafka-board diff [-registry name] [-naming convention] [-json] <directory>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	schemas, err := schemaDirectory.Load(flags.Arg(0), *naming)
+	if err != nil {
+		return err
+	}
+
+	registryAPI, err := selectRegistry(*registryName)
+	if err != nil {
+		return err
+	}
+
+	report, err := schemaDirectory.Compare(ctx, registryAPI, schemas)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("%d proposed schemas would be refused or could not be checked", failed)
+	}
+	return nil
+}
+
+// selectRegistry returns the configured registry with the given name, the first one
+// when the name is empty. Commands read the registry directly, never a cache.
+func selectRegistry(name string) (*confluentRegistryAPI.RegistryAPI, error) {
+	registryAPIs, err := confluentRegistryAPI.ReturnRegistryAPIs(logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not configure registry API: %w", err)
+	}
+	if name == "" && len(registryAPIs) > 0 {
+		return registryAPIs[0], nil
+	}
+	return findRegistry(registryAPIs, name)
+}
+
+// findRegistry returns the configured registry with the given name
+func findRegistry(registryAPIs []*confluentRegistryAPI.RegistryAPI, name string) (*confluentRegistryAPI.RegistryAPI, error) {
+	if len(registryAPIs) == 0 {
+		return nil, fmt.Errorf("no registry configured")
+	}
+	for _, registryAPI := range registryAPIs {
+		if registryAPI.Name() == name {
+			return registryAPI, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown registry: %s", name)
+}
@@ -1,19 +1,36 @@
 package confluentRegistryAPI
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"kafka-board/helpers"
+	"kafka-board/registryErrors"
 	"kafka-board/types"
 	"log/slog"
 	"net/http"
 	"os"
+	"sort"
+	"sync"
+	"time"
 )
 
 type RegistryAPI struct {
 	logger          *slog.Logger
+	name            string
 	baseRegistryURL string
+	client          *http.Client
+
+	// Number of subject configs fetched at the same time
+	configConcurrency int
+
+	// Deadline of each registry call
+	requestTimeout time.Duration
+
+	// Opened while the registry is down, nil when calls are not guarded
+	breaker *circuitBreaker
 }
 
 func getBaseRegistryURL() string {
@@ -24,16 +41,96 @@ func getBaseRegistryURL() string {
 	return os.Getenv("REGISTRY_BASE_URL")
 }
 
-func ReturnRegistryAPI(logger *slog.Logger) *RegistryAPI {
-	return &RegistryAPI{logger: logger, baseRegistryURL: getBaseRegistryURL()}
+// ReturnRegistryAPI creates a RegistryAPI configured from the environment
+func ReturnRegistryAPI(logger *slog.Logger) (*RegistryAPI, error) {
+	return ReturnRegistryAPIForConfig(logger, getRegistryConfig())
 }
 
-func (r *RegistryAPI) ReturnSubjects() ([]string, error) {
-	// Create HTTP client
-	client := &http.Client{}
+// ReturnRegistryAPIForConfig creates a RegistryAPI for one registry.
+// Authentication and TLS are set up once on a client shared by every method.
+func ReturnRegistryAPIForConfig(logger *slog.Logger, config RegistryConfig) (*RegistryAPI, error) {
+	client, err := newRegistryClient(config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("error configuring registry client: %w", err)
+	}
+
+	requestTimeout := defaultRequestTimeout
+	if config.RequestTimeout != "" {
+		requestTimeout, err = time.ParseDuration(config.RequestTimeout)
+		if err != nil || requestTimeout <= 0 {
+			return nil, fmt.Errorf("invalid request timeout %q", config.RequestTimeout)
+		}
+	}
+
+	breakerCooldown := defaultBreakerCooldown
+	if config.BreakerCooldown != "" {
+		breakerCooldown, err = time.ParseDuration(config.BreakerCooldown)
+		if err != nil || breakerCooldown <= 0 {
+			return nil, fmt.Errorf("invalid breaker cooldown %q", config.BreakerCooldown)
+		}
+	}
+
+	breakerThreshold := defaultBreakerThreshold
+	if config.BreakerThreshold > 0 {
+		breakerThreshold = config.BreakerThreshold
+	}
+
+	maxRetries := defaultMaxRetries
+	if config.MaxRetries != nil {
+		maxRetries = *config.MaxRetries
+	}
+
+	// Failed reads are retried and a registry that keeps failing is no longer called
+	logger = logger.With("registry", config.Name)
+	breaker := newCircuitBreaker(breakerThreshold, breakerCooldown)
+	client.Transport = &resilientTransport{
+		base:       client.Transport,
+		breaker:    breaker,
+		maxRetries: maxRetries,
+		baseDelay:  defaultRetryBaseDelay,
+		maxDelay:   defaultRetryMaxDelay,
+		logger:     logger,
+	}
+
+	return &RegistryAPI{
+		logger:            logger,
+		name:              config.Name,
+		baseRegistryURL:   config.BaseURL,
+		client:            client,
+		configConcurrency: config.ConfigConcurrency,
+		requestTimeout:    requestTimeout,
+		breaker:           breaker,
+	}, nil
+}
+
+// withDeadline bounds a registry call by the request timeout, on top of any
+// deadline already set by the caller
+func (r *RegistryAPI) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.requestTimeout <= 0 {
+		return context.WithTimeout(ctx, defaultRequestTimeout)
+	}
+	return context.WithTimeout(ctx, r.requestTimeout)
+}
+
+// Name returns the name the registry is listed under
+func (r *RegistryAPI) Name() string {
+	return r.name
+}
+
+// BreakerStatus returns the state of the circuit breaker guarding the registry calls
+func (r *RegistryAPI) BreakerStatus() types.BreakerStatus {
+	if r.breaker == nil {
+		return types.BreakerStatus{State: breakerClosed}
+	}
+	return r.breaker.status()
+}
+
+func (r *RegistryAPI) ReturnSubjects(ctx context.Context) ([]string, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 
 	// Create request
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/subjects", r.baseRegistryURL), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/subjects", r.baseRegistryURL), nil)
 	if helpers.CheckErr(err) {
 		r.logger.Debug("ReturnSubjects - Error creating request",
 			"error", err)
@@ -45,21 +142,25 @@ func (r *RegistryAPI) ReturnSubjects() ([]string, error) {
 	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
 
 	// Send request
-	resp, err := client.Do(req)
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
 	if helpers.CheckErr(err) {
 		r.logger.Debug("ReturnSubjects - Error making request",
 			"error", err)
 
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		err := registryErrors.FromResponse(resp.StatusCode, body)
+
 		r.logger.Debug("ReturnSubjects - Unexpected status code",
-			"status", resp.StatusCode)
+			"status", resp.StatusCode,
+			"error", err)
 
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, err
 	}
 
 	// Read response body
@@ -85,101 +186,169 @@ func (r *RegistryAPI) ReturnSubjects() ([]string, error) {
 	return subjects, nil
 }
 
-func (r *RegistryAPI) ReturnSubjectConfigs(subjectNames []string) ([]types.SubjectConfigInterface, error) {
-	var configs []types.SubjectConfigInterface
-	client := &http.Client{}
+// ReturnSubjectConfigs fetches the config of every subject concurrently, at most
+// configConcurrency at a time. Configs are returned in the order of subjectNames.
+// A subject whose config cannot be fetched is returned as a SubjectConfigError.
+func (r *RegistryAPI) ReturnSubjectConfigs(ctx context.Context, subjectNames []string) ([]types.SubjectConfigInterface, error) {
+	configs, err := fetchEach(ctx, r, subjectNames, r.getSubjectConfig, func(subjectName string, err error) types.SubjectConfigInterface {
+		return types.SubjectConfigError{
+			Name:  subjectName,
+			Error: err.Error(),
+		}
+	})
+	if err != nil {
+		r.logger.Debug("ReturnSubjectConfigs - Stopped before every config was fetched",
+			"error", err)
 
-	for _, subjectName := range subjectNames {
-		// Create request with URL-encoded subject name
-		url := r.baseRegistryURL + "/config/" + subjectName
-		r.logger.Debug("ReturnSubjectConfigs - Requesting URL",
-			"url", url)
+		return nil, err
+	}
 
-		req, err := http.NewRequest("GET", url, nil)
+	r.logger.Debug("ReturnSubjectConfigs - Configs returned by returnSubjectConfigs",
+		"configs", configs)
 
-		if helpers.CheckErr(err) {
-			r.logger.Debug("ReturnSubjectConfigs - Error creating request",
-				"error", err)
+	return configs, nil
+}
+
+// concurrency returns the number of registry calls a request makes at the same
+// time, configConcurrency or its default when it is not set
+func (r *RegistryAPI) concurrency() int {
+	if r.configConcurrency <= 0 {
+		return defaultConfigConcurrency
+	}
+	return r.configConcurrency
+}
+
+// fetchEach calls fetch for every subject concurrently, at most configConcurrency at
+// a time, and returns the results in the order of subjectNames. A failed fetch is
+// turned into a result by failed. It stops handing out subjects once ctx is done.
+func fetchEach[T any](ctx context.Context, r *RegistryAPI, subjectNames []string, fetch func(context.Context, string) (T, error), failed func(string, error) T) ([]T, error) {
+	results := make([]T, len(subjectNames))
+
+	workers := min(r.concurrency(), len(subjectNames))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := fetch(ctx, subjectNames[i])
+				if helpers.CheckErr(err) {
+					result = failed(subjectNames[i], err)
+				}
+				results[i] = result
+			}
+		}()
+	}
 
-			return nil, fmt.Errorf("error creating request: %v", err)
+	// Stop handing out subjects once the caller is gone
+dispatch:
+	for i := range subjectNames {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break dispatch
 		}
+	}
+	close(indexes)
+	wg.Wait()
 
-		// Set headers
-		req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if ctx.Err() != nil {
+		return nil, helpers.RequestContextError(ctx, ctx.Err())
+	}
 
-		// Send request
-		resp, err := client.Do(req)
+	return results, nil
+}
 
-		if helpers.CheckErr(err) {
-			r.logger.Debug("ReturnSubjectConfigs - Error making request",
-				"error", err)
+// getSubjectConfig fetches the config of one subject. Subjects without a config
+// of their own take the global default.
+func (r *RegistryAPI) getSubjectConfig(ctx context.Context, subjectName string) (types.SubjectConfigInterface, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 
-			return nil, fmt.Errorf("error making request: %v", err)
-		}
-		defer resp.Body.Close()
+	// Create request with URL-encoded subject name
+	url := r.baseRegistryURL + "/config/" + escapeSubject(subjectName)
+	r.logger.Debug("getSubjectConfig - Requesting URL",
+		"url", url)
 
-		// Check status code
-		if resp.StatusCode == http.StatusNotFound {
-			r.logger.Debug("ReturnSubjectConfigs - Subject config not found",
-				"subject", subjectName)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 
-			config := types.SubjectGlobalConfig{
-				Name:               subjectName,
-				TakesGlobalDefault: true,
-			}
+	if helpers.CheckErr(err) {
+		r.logger.Debug("getSubjectConfig - Error creating request",
+			"error", err)
 
-			configs = append(configs, config)
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
 
-			continue
-		}
+	// Set headers
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
+	// Send request
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
 
-			r.logger.Debug("ReturnSubjectConfigs - Unexpected status code",
-				"status", resp.StatusCode,
-				"body", string(body))
+	if helpers.CheckErr(err) {
+		r.logger.Debug("getSubjectConfig - Error making request",
+			"error", err)
 
-			return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
-		}
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
 
-		// Read response body
-		body, err := io.ReadAll(resp.Body)
-		if helpers.CheckErr(err) {
-			r.logger.Debug("ReturnSubjectConfigs - Error reading response",
-				"error", err)
+	// Check status code
+	if resp.StatusCode == http.StatusNotFound {
+		r.logger.Debug("getSubjectConfig - Subject config not found",
+			"subject", subjectName)
 
-			return nil, fmt.Errorf("error reading response: %v", err)
-		}
+		return types.SubjectGlobalConfig{
+			Name:               subjectName,
+			TakesGlobalDefault: true,
+		}, nil
+	}
 
-		// Parse JSON response
-		config := types.SubjectConfig{
-			Name: subjectName,
-		}
-		if helpers.CheckErr(json.Unmarshal(body, &config)) {
-			r.logger.Debug("ReturnSubjectConfigs - Error parsing JSON",
-				"error", err)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		err := registryErrors.FromResponse(resp.StatusCode, body)
 
-			return nil, fmt.Errorf("error parsing JSON: %v", err)
-		}
-		config.SetDefaultNone()
-		r.logger.Debug("ReturnSubjectConfigs - Config returned by returnSubjectConfigs for subject",
-			"subject", subjectName)
+		r.logger.Debug("getSubjectConfig - Unexpected status code",
+			"status", resp.StatusCode,
+			"error", err)
 
-		configs = append(configs, config)
+		return nil, err
 	}
 
-	r.logger.Debug("ReturnSubjectConfigs - Configs returned by returnSubjectConfigs",
-		"configs", configs)
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("getSubjectConfig - Error reading response",
+			"error", err)
 
-	return configs, nil
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	// Parse JSON response
+	config := types.SubjectConfig{
+		Name: subjectName,
+	}
+	if err := json.Unmarshal(body, &config); err != nil {
+		r.logger.Debug("getSubjectConfig - Error parsing JSON",
+			"error", err)
+
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+	config.SetDefaultNone()
+	r.logger.Debug("getSubjectConfig - Config returned for subject",
+		"subject", subjectName)
+
+	return config, nil
 }
 
-func (r *RegistryAPI) GetGlobalConfig() (types.GlobalConfig, error) {
-	client := &http.Client{}
+func (r *RegistryAPI) GetGlobalConfig(ctx context.Context) (types.GlobalConfig, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
 
 	url := r.baseRegistryURL + "/config"
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 
 	if helpers.CheckErr(err) {
 		r.logger.Debug("GetGlobalConfig -Error creating request",
@@ -190,22 +359,26 @@ func (r *RegistryAPI) GetGlobalConfig() (types.GlobalConfig, error) {
 
 	//Preparing Request
 	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
-	resp, err := client.Do(req)
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
 
 	if helpers.CheckErr(err) {
 		r.logger.Debug("GetGlobalConfig - Error making request",
 			"error", err)
 
-		return types.GlobalConfig{}, fmt.Errorf("error making request: %v", err)
+		return types.GlobalConfig{}, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	//Checking Status Code
 	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		err := registryErrors.FromResponse(resp.StatusCode, body)
+
 		r.logger.Debug("GetGlobalConfig - Unexpected status code",
-			"status", resp.StatusCode)
+			"status", resp.StatusCode,
+			"error", err)
 
-		return types.GlobalConfig{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return types.GlobalConfig{}, err
 	}
 
 	//Reading Response Body
@@ -238,15 +411,63 @@ func (r *RegistryAPI) GetGlobalConfig() (types.GlobalConfig, error) {
 	return globalConfig, nil
 }
 
-func (r *RegistryAPI) GetSchemas(subjectName string) ([]types.Schema, error) {
-	var allSchemas []types.Schema
-	client := &http.Client{}
+// GetSchemas lists the versions of a subject, oldest first, without their schema
+// bodies. Bodies are loaded one version at a time with GetSubjectVersion.
+// Soft-deleted versions are included and flagged when includeDeleted is set.
+func (r *RegistryAPI) GetSchemas(ctx context.Context, subjectName string, includeDeleted bool) ([]types.Schema, error) {
+	versions, err := r.GetSubjectVersions(ctx, subjectName, false)
+	if helpers.CheckErr(err) && !(includeDeleted && errors.Is(err, registryErrors.ErrSubjectNotFound)) {
+		r.logger.Debug("GetSchemas - Error listing versions",
+			"error", err)
 
-	url := r.baseRegistryURL + "/schemas"
-	req, err := http.NewRequest("GET", url, nil)
+		return nil, err
+	}
 
+	// Versions only listed with deleted=true are soft-deleted
+	live := make(map[int]bool, len(versions))
+	for _, version := range versions {
+		live[version] = true
+	}
+
+	if includeDeleted {
+		versions, err = r.GetSubjectVersions(ctx, subjectName, true)
+		if helpers.CheckErr(err) {
+			r.logger.Debug("GetSchemas - Error listing versions including deleted ones",
+				"error", err)
+
+			return nil, err
+		}
+	}
+
+	schemas := make([]types.Schema, 0, len(versions))
+	for _, version := range versions {
+		schemas = append(schemas, types.Schema{
+			Subject: subjectName,
+			Version: version,
+			Deleted: !live[version],
+		})
+	}
+
+	r.logger.Debug("GetSchemas - Versions returned by getSchemas",
+		"subject", subjectName,
+		"versions", versions)
+
+	return schemas, nil
+}
+
+// GetSubjectVersions returns the version numbers of a subject in ascending order
+func (r *RegistryAPI) GetSubjectVersions(ctx context.Context, subjectName string, includeDeleted bool) ([]int, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+
+	url := r.baseRegistryURL + "/subjects/" + escapeSubject(subjectName) + "/versions"
+	if includeDeleted {
+		url += "?deleted=true"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if helpers.CheckErr(err) {
-		r.logger.Debug("GetSchemas - Error creating request",
+		r.logger.Debug("GetSubjectVersions - Error creating request",
 			"error", err)
 
 		return nil, fmt.Errorf("error creating request: %v", err)
@@ -254,70 +475,117 @@ func (r *RegistryAPI) GetSchemas(subjectName string) ([]types.Schema, error) {
 
 	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
 
-	resp, err := client.Do(req)
-
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
 	if helpers.CheckErr(err) {
-		r.logger.Debug("GetSchemas - Error making request",
+		r.logger.Debug("GetSubjectVersions - Error making request",
 			"error", err)
 
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		r.logger.Debug("GetSchemas - Unexpected status code",
-			"status", resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		err := registryErrors.FromResponse(resp.StatusCode, body)
+
+		r.logger.Debug("GetSubjectVersions - Unexpected status code",
+			"status", resp.StatusCode,
+			"error", err)
 
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, err
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if helpers.CheckErr(err) {
-		r.logger.Debug("GetSchemas - Error reading response",
+		r.logger.Debug("GetSubjectVersions - Error reading response",
 			"error", err)
 
 		return nil, fmt.Errorf("error reading response: %v", err)
 	}
 
-	if err := json.Unmarshal(body, &allSchemas); err != nil {
+	var versions []int
+	if err := json.Unmarshal(body, &versions); err != nil {
+		r.logger.Debug("GetSubjectVersions - Error parsing JSON",
+			"error", err)
+
 		return nil, fmt.Errorf("error parsing JSON: %v", err)
 	}
+	sort.Ints(versions)
 
-	// Filter schemas by subject
-	var filteredSchemas []types.Schema
-	for _, schema := range allSchemas {
-		if schema.Subject == subjectName {
-			filteredSchemas = append(filteredSchemas, schema)
-		}
-	}
+	return versions, nil
+}
 
-	r.logger.Debug("GetSchemas - Filtered Schemas returned by getSchemas",
-		"schemas", filteredSchemas)
+// TestSchema checks the compatibility of a proposed schema against a version of the subject.
+// The proposed schema carries its type and references; Protobuf schemas are .proto text.
+func (r *RegistryAPI) TestSchema(ctx context.Context, subjectName string, version int, proposed types.Schema) (types.Response, error) {
+	payload, resp, err := r.compatibilityPayload(ctx, proposed)
+	if helpers.CheckErr(err) {
+		return resp, err
+	}
 
-	return filteredSchemas, nil
+	return r.checkCompatibility(ctx, subjectName, version, payload)
 }
 
-// transformJSONToSchemaFormat takes a JSON string and wraps it in the Schema Registry format
-func (r *RegistryAPI) TestSchema(subjectName string, version int, testJSON string) (types.Response, error) {
+// compatibilityPayload builds the body of a compatibility check of the proposed schema.
+// On failure, the response to report is returned along with the error.
+func (r *RegistryAPI) compatibilityPayload(ctx context.Context, proposed types.Schema) (string, types.Response, error) {
+	// Referenced schemas are needed to check the proposed schema locally
+	resolved, err := r.ResolveReferences(ctx, proposed)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("compatibilityPayload - Error resolving references",
+			"error", err)
+
+		resp := helpers.CreateResponseObject(nil, fmt.Sprintf("Error resolving schema references: %v", err), http.StatusInternalServerError, http.StatusInternalServerError)
+
+		return "", resp, err
+	}
 
 	helper := helpers.ReturnHelpers(r.logger)
-	payload, err := helper.TransformJSONToSchemaFormat(testJSON)
+	payload, err := helper.TransformToSchemaFormat(proposed, resolved)
 
 	if helpers.CheckErr(err) {
-		r.logger.Debug("TestSchema - Error transforming JSON to Schema Registry format",
+		r.logger.Debug("compatibilityPayload - Error transforming JSON to Schema Registry format",
 			"error", err)
 
 		resp := helpers.CreateResponseObject(nil, fmt.Sprintf("Error transforming JSON to Schema Registry format. Invalid JSON string: %v", err), http.StatusBadRequest, http.StatusBadRequest)
 
-		return resp, err
+		return "", resp, err
 	}
-	r.logger.Debug("TestSchema - Transformed JSON returned by transformJSONToSchemaFormat",
+	r.logger.Debug("compatibilityPayload - Transformed JSON returned by transformJSONToSchemaFormat",
 		"payload", payload)
-	// Create the request
-	req, err := createTestSchemaRequest(subjectName, version, payload, r.baseRegistryURL)
+
+	return payload, types.Response{}, nil
+}
+
+// checkCompatibility asks the registry whether a payload built by
+// TransformToSchemaFormat is compatible with a version of the subject
+func (r *RegistryAPI) checkCompatibility(ctx context.Context, subjectName string, version int, payload string) (types.Response, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+
+	req, err := createTestSchemaRequest(ctx, subjectName, version, payload, r.baseRegistryURL)
+
+	return r.sendCompatibilityCheck(ctx, req, err)
+}
+
+// dryRunCompatibility asks the registry whether registering a payload built by
+// TransformToSchemaFormat would pass the compatibility level of the subject
+func (r *RegistryAPI) dryRunCompatibility(ctx context.Context, subjectName string, payload string, normalize bool) (types.Response, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+
+	req, err := createDryRunRequest(ctx, subjectName, payload, normalize, r.baseRegistryURL)
+
+	return r.sendCompatibilityCheck(ctx, req, err)
+}
+
+// sendCompatibilityCheck sends a compatibility check built with the error of
+// building it, and turns the answer of the registry into a response
+func (r *RegistryAPI) sendCompatibilityCheck(ctx context.Context, req *http.Request, err error) (types.Response, error) {
+	helper := helpers.ReturnHelpers(r.logger)
 
 	if helpers.CheckErr(err) {
-		r.logger.Debug("TestSchema - Error creating request",
+		r.logger.Debug("checkCompatibility - Error creating request",
 			"error", err)
 
 		resp := helpers.CreateResponseObject(nil, fmt.Sprintf("Error creating request: %v", err), http.StatusInternalServerError, http.StatusInternalServerError)
@@ -325,9 +593,9 @@ func (r *RegistryAPI) TestSchema(subjectName string, version int, testJSON strin
 	}
 
 	// Make the request
-	resp, err := helpers.MakeHTTPRequest(req)
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
 	if helpers.CheckErr(err) {
-		r.logger.Debug("TestSchema - Error making request",
+		r.logger.Debug("checkCompatibility - Error making request",
 			"error", err)
 
 		resp := helpers.CreateResponseObject(nil, fmt.Sprintf("Error making request: %v", err), http.StatusInternalServerError, http.StatusInternalServerError)
@@ -337,7 +605,7 @@ func (r *RegistryAPI) TestSchema(subjectName string, version int, testJSON strin
 	// Read the response body
 	body, err := helpers.ReadResponseBody(resp)
 	if helpers.CheckErr(err) {
-		r.logger.Debug("TestSchema - Error reading response",
+		r.logger.Debug("checkCompatibility - Error reading response",
 			"error", err)
 
 		resp := helpers.CreateResponseObject(nil, fmt.Sprintf("Error reading response: %v", err), http.StatusInternalServerError, http.StatusInternalServerError)
@@ -347,9 +615,15 @@ func (r *RegistryAPI) TestSchema(subjectName string, version int, testJSON strin
 	// Process the response
 	result, err := helper.ProcessResponse(body, resp.StatusCode)
 	if helpers.CheckErr(err) {
-		r.logger.Debug("TestSchema - Error processing response",
+		r.logger.Debug("checkCompatibility - Error processing response",
 			"error", err)
 
+		// Errors reported by the registry keep their code and message
+		var registryErr *registryErrors.Error
+		if errors.As(err, &registryErr) {
+			return result, err
+		}
+
 		resp := helpers.CreateResponseObject(nil, fmt.Sprintf("Error processing response: %v", err), http.StatusInternalServerError, http.StatusInternalServerError)
 		return resp, err
 	}
@@ -359,14 +633,9 @@ func (r *RegistryAPI) TestSchema(subjectName string, version int, testJSON strin
 		result.Message = "None"
 	}
 
-	// Ensure message is not too long as it will break the UI and Confluent returns long messages
-	if len(result.Message) > 100 {
-		result.Message = result.Message[:100] + "..."
-	}
-
 	// Handle nil IsCompatible pointer - this means the compatibility couldn't be determined
 	if result.IsCompatible == nil {
-		r.logger.Debug("TestSchema - IsCompatible is nil",
+		r.logger.Debug("checkCompatibility - IsCompatible is nil",
 			"result", result)
 
 		resp := helpers.CreateResponseObject(nil, result.Message, result.StatusCode, result.ErrorCode)
@@ -377,12 +646,14 @@ func (r *RegistryAPI) TestSchema(subjectName string, version int, testJSON strin
 	return result, nil
 }
 
-func (r *RegistryAPI) GetSchema(id string) (types.Schema, error) {
+func (r *RegistryAPI) GetSchema(ctx context.Context, id string) (types.Schema, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+
 	schema := types.Schema{}
-	client := &http.Client{}
 
 	url := r.baseRegistryURL + "/schemas/ids/" + id
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if helpers.CheckErr(err) {
 		r.logger.Debug("GetSchema - Error creating request",
 			"error", err)
@@ -392,20 +663,24 @@ func (r *RegistryAPI) GetSchema(id string) (types.Schema, error) {
 
 	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
 
-	resp, err := client.Do(req)
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
 	if helpers.CheckErr(err) {
 		r.logger.Debug("GetSchema - Error making request",
 			"error", err)
 
-		return schema, fmt.Errorf("error making request: %v", err)
+		return schema, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		err := registryErrors.FromResponse(resp.StatusCode, body)
+
 		r.logger.Debug("GetSchema - Unexpected status code",
-			"status", resp.StatusCode)
+			"status", resp.StatusCode,
+			"error", err)
 
-		return schema, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return schema, err
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -429,3 +704,73 @@ func (r *RegistryAPI) GetSchema(id string) (types.Schema, error) {
 
 	return schema, nil
 }
+
+// GetSubjectVersion returns one version of a subject. A version of 0 or less
+// returns the latest version. Soft-deleted versions are found when includeDeleted is set.
+func (r *RegistryAPI) GetSubjectVersion(ctx context.Context, subjectName string, version int, includeDeleted bool) (types.Schema, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+
+	schema := types.Schema{}
+
+	versionStr := "latest"
+	if version > 0 {
+		versionStr = fmt.Sprintf("%d", version)
+	}
+
+	url := r.baseRegistryURL + "/subjects/" + escapeSubject(subjectName) + "/versions/" + versionStr
+	if includeDeleted {
+		url += "?deleted=true"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("GetSubjectVersion - Error creating request",
+			"error", err)
+
+		return schema, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("GetSubjectVersion - Error making request",
+			"error", err)
+
+		return schema, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		err := registryErrors.FromResponse(resp.StatusCode, body)
+
+		r.logger.Debug("GetSubjectVersion - Unexpected status code",
+			"status", resp.StatusCode,
+			"error", err)
+
+		return schema, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("GetSubjectVersion - Error reading response",
+			"error", err)
+
+		return schema, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if err := json.Unmarshal(body, &schema); err != nil {
+		r.logger.Debug("GetSubjectVersion - Error parsing JSON",
+			"error", err)
+
+		return schema, fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	r.logger.Debug("GetSubjectVersion - Schema returned by getSubjectVersion",
+		"subject", subjectName,
+		"version", schema.Version)
+
+	return schema, nil
+}
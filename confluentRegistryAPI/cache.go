@@ -0,0 +1,467 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"kafka-board/helpers"
+	"kafka-board/types"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CacheConfig holds how long each kind of registry read is cached.
+// A TTL of zero disables caching for that endpoint.
+type CacheConfig struct {
+	Subjects     time.Duration
+	Configs      time.Duration
+	GlobalConfig time.Duration
+	Schemas      time.Duration
+	// Schemas by ID and subject versions never change once registered
+	SchemaByID      time.Duration
+	SubjectVersions time.Duration
+	References      time.Duration
+}
+
+// cacheTTLVariables maps each cache TTL to the variable that overrides it
+var cacheTTLVariables = []struct {
+	name string
+	ttl  func(*CacheConfig) *time.Duration
+}{
+	{"REGISTRY_CACHE_TTL_SUBJECTS", func(c *CacheConfig) *time.Duration { return &c.Subjects }},
+	{"REGISTRY_CACHE_TTL_CONFIGS", func(c *CacheConfig) *time.Duration { return &c.Configs }},
+	{"REGISTRY_CACHE_TTL_GLOBAL_CONFIG", func(c *CacheConfig) *time.Duration { return &c.GlobalConfig }},
+	{"REGISTRY_CACHE_TTL_SCHEMAS", func(c *CacheConfig) *time.Duration { return &c.Schemas }},
+	{"REGISTRY_CACHE_TTL_SCHEMA_BY_ID", func(c *CacheConfig) *time.Duration { return &c.SchemaByID }},
+	{"REGISTRY_CACHE_TTL_SUBJECT_VERSION", func(c *CacheConfig) *time.Duration { return &c.SubjectVersions }},
+	{"REGISTRY_CACHE_TTL_REFERENCES", func(c *CacheConfig) *time.Duration { return &c.References }},
+}
+
+// GetCacheConfig reads the cache TTLs from the environment, e.g.
+// REGISTRY_CACHE_TTL_SUBJECTS=1m. Unset variables keep their default.
+func GetCacheConfig() (CacheConfig, error) {
+	config := CacheConfig{
+		Subjects:        30 * time.Second,
+		Configs:         30 * time.Second,
+		GlobalConfig:    30 * time.Second,
+		Schemas:         30 * time.Second,
+		SchemaByID:      time.Hour,
+		SubjectVersions: 10 * time.Minute,
+		References:      10 * time.Minute,
+	}
+
+	for _, variable := range cacheTTLVariables {
+		value := os.Getenv(variable.name)
+		if value == "" {
+			continue
+		}
+
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return CacheConfig{}, fmt.Errorf("invalid duration %q for %s", value, variable.name)
+		}
+		*variable.ttl(&config) = ttl
+	}
+
+	return config, nil
+}
+
+// CachedRegistryAPI wraps a RegistryAPI and keeps its reads in memory.
+// Concurrent identical reads are merged into a single registry call.
+// Cached values are shared between callers and must not be modified.
+type CachedRegistryAPI struct {
+	*RegistryAPI
+	config CacheConfig
+	cache  *registryCache
+}
+
+// ReturnCachedRegistryAPI wraps registryAPI with an in-memory cache
+func ReturnCachedRegistryAPI(registryAPI *RegistryAPI, config CacheConfig) *CachedRegistryAPI {
+	return &CachedRegistryAPI{
+		RegistryAPI: registryAPI,
+		config:      config,
+		cache:       newRegistryCache(registryAPI.logger),
+	}
+}
+
+// FlushCache drops every cached read so the next ones go to the registry
+func (c *CachedRegistryAPI) FlushCache() {
+	c.cache.flush()
+}
+
+func (c *CachedRegistryAPI) ReturnSubjects(ctx context.Context) ([]string, error) {
+	return cached(ctx, c.cache, "subjects", c.config.Subjects, c.RegistryAPI.ReturnSubjects)
+}
+
+// Contexts are listed with the subjects and share their TTL
+func (c *CachedRegistryAPI) ReturnContexts(ctx context.Context) ([]string, error) {
+	return cached(ctx, c.cache, "contexts", c.config.Subjects, c.RegistryAPI.ReturnContexts)
+}
+
+func (c *CachedRegistryAPI) GetGlobalConfig(ctx context.Context) (types.GlobalConfig, error) {
+	return cached(ctx, c.cache, "config", c.config.GlobalConfig, c.RegistryAPI.GetGlobalConfig)
+}
+
+func (c *CachedRegistryAPI) GetSchemas(ctx context.Context, subjectName string, includeDeleted bool) ([]types.Schema, error) {
+	key := fmt.Sprintf("schemas:%t:%s", includeDeleted, subjectName)
+	return cached(ctx, c.cache, key, c.config.Schemas, func(ctx context.Context) ([]types.Schema, error) {
+		return c.RegistryAPI.GetSchemas(ctx, subjectName, includeDeleted)
+	})
+}
+
+func (c *CachedRegistryAPI) GetSubjectVersion(ctx context.Context, subjectName string, version int, includeDeleted bool) (types.Schema, error) {
+	// "latest" moves with every registration, it is cached like the version list
+	ttl := c.config.SubjectVersions
+	if version <= 0 {
+		ttl = c.config.Schemas
+	}
+
+	key := fmt.Sprintf("version:%t:%d:%s", includeDeleted, version, subjectName)
+	return cached(ctx, c.cache, key, ttl, func(ctx context.Context) (types.Schema, error) {
+		return c.RegistryAPI.GetSubjectVersion(ctx, subjectName, version, includeDeleted)
+	})
+}
+
+func (c *CachedRegistryAPI) GetSchema(ctx context.Context, id string) (types.Schema, error) {
+	return cached(ctx, c.cache, "schema:"+id, c.config.SchemaByID, func(ctx context.Context) (types.Schema, error) {
+		return c.RegistryAPI.GetSchema(ctx, id)
+	})
+}
+
+func (c *CachedRegistryAPI) ResolveReferences(ctx context.Context, schema types.Schema) ([]types.ResolvedReference, error) {
+	// Only the references decide the result, schemas sharing them share the entry
+	references, err := json.Marshal(schema.References)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling references: %v", err)
+	}
+
+	return cached(ctx, c.cache, "references:"+string(references), c.config.References, func(ctx context.Context) ([]types.ResolvedReference, error) {
+		return c.RegistryAPI.ResolveReferences(ctx, schema)
+	})
+}
+
+// RegisterSchema registers a new version of a subject and flushes the cache, as the
+// subject list, the version lists and the latest versions are all out of date
+func (c *CachedRegistryAPI) RegisterSchema(ctx context.Context, subjectName string, proposed types.Schema, normalize bool) (types.Response, error) {
+	resp, err := c.RegistryAPI.RegisterSchema(ctx, subjectName, proposed, normalize)
+	if resp.Registered != nil {
+		c.cache.flush()
+	}
+	return resp, err
+}
+
+// ImportSchema imports a schema with its ID. A restore imports every version of a
+// registry, so only the subject list and the entries of the subject and the ID are
+// dropped rather than the whole cache.
+func (c *CachedRegistryAPI) ImportSchema(ctx context.Context, subjectName string, schema types.Schema) error {
+	err := c.RegistryAPI.ImportSchema(ctx, subjectName, schema)
+	if err == nil {
+		c.cache.invalidate(func(key string) bool {
+			switch {
+			case key == "subjects", key == "contexts", key == "schema:"+strconv.Itoa(schema.Id):
+				return true
+			case strings.HasPrefix(key, "schemas:"), strings.HasPrefix(key, "version:"):
+				return strings.HasSuffix(key, ":"+subjectName)
+			}
+			return false
+		})
+	}
+	return err
+}
+
+func (c *CachedRegistryAPI) UpdateCompatibilityLevel(ctx context.Context, subjectName string, level string) error {
+	err := c.RegistryAPI.UpdateCompatibilityLevel(ctx, subjectName, level)
+	if err == nil {
+		c.cache.flush()
+	}
+	return err
+}
+
+func (c *CachedRegistryAPI) UpdateConfig(ctx context.Context, subjectName string, config types.ConfigPayload) error {
+	err := c.RegistryAPI.UpdateConfig(ctx, subjectName, config)
+	if err == nil {
+		c.cache.flush()
+	}
+	return err
+}
+
+func (c *CachedRegistryAPI) DeleteCompatibilityLevel(ctx context.Context, subjectName string) error {
+	err := c.RegistryAPI.DeleteCompatibilityLevel(ctx, subjectName)
+	if err == nil {
+		c.cache.flush()
+	}
+	return err
+}
+
+func (c *CachedRegistryAPI) GetMode(ctx context.Context, subjectName string) (types.SubjectMode, error) {
+	return cached(ctx, c.cache, "mode:"+subjectName, c.config.Configs, func(ctx context.Context) (types.SubjectMode, error) {
+		return c.RegistryAPI.GetMode(ctx, subjectName)
+	})
+}
+
+func (c *CachedRegistryAPI) UpdateMode(ctx context.Context, subjectName string, mode string, force bool) error {
+	err := c.RegistryAPI.UpdateMode(ctx, subjectName, mode, force)
+	if err == nil {
+		c.cache.flush()
+	}
+	return err
+}
+
+func (c *CachedRegistryAPI) DeleteMode(ctx context.Context, subjectName string) error {
+	err := c.RegistryAPI.DeleteMode(ctx, subjectName)
+	if err == nil {
+		c.cache.flush()
+	}
+	return err
+}
+
+func (c *CachedRegistryAPI) DeleteSubject(ctx context.Context, subjectName string, permanent bool) ([]int, error) {
+	deleted, err := c.RegistryAPI.DeleteSubject(ctx, subjectName, permanent)
+	if err == nil {
+		c.cache.flush()
+	}
+	return deleted, err
+}
+
+func (c *CachedRegistryAPI) DeleteSubjectVersion(ctx context.Context, subjectName string, version int, permanent bool) error {
+	err := c.RegistryAPI.DeleteSubjectVersion(ctx, subjectName, version, permanent)
+	if err == nil {
+		c.cache.flush()
+	}
+	return err
+}
+
+func (c *CachedRegistryAPI) RestoreSubjectVersion(ctx context.Context, subjectName string, version int) (types.Response, error) {
+	resp, err := c.RegistryAPI.RestoreSubjectVersion(ctx, subjectName, version)
+	if resp.Registered != nil {
+		c.cache.flush()
+	}
+	return resp, err
+}
+
+// ReturnSubjectConfigs caches the config of each subject on its own, so that only
+// the subjects missing from the cache are requested from the registry
+func (c *CachedRegistryAPI) ReturnSubjectConfigs(ctx context.Context, subjectNames []string) ([]types.SubjectConfigInterface, error) {
+	return cachedEach(ctx, c.cache, "config", c.config.Configs, subjectNames, c.RegistryAPI.ReturnSubjectConfigs,
+		func(config types.SubjectConfigInterface) (string, bool) {
+			_, failed := config.(types.SubjectConfigError)
+			return config.GetName(), failed
+		})
+}
+
+// ReturnSubjectModes caches the mode of each subject on its own, sharing the
+// entries of GetMode
+func (c *CachedRegistryAPI) ReturnSubjectModes(ctx context.Context, subjectNames []string) ([]types.SubjectMode, error) {
+	return cachedEach(ctx, c.cache, "mode", c.config.Configs, subjectNames, c.RegistryAPI.ReturnSubjectModes,
+		func(mode types.SubjectMode) (string, bool) {
+			return mode.Name, mode.Error != ""
+		})
+}
+
+// cachedEach caches a value per subject under prefix:subject, so that only the
+// subjects missing from the cache are loaded. describe gives the subject of a
+// loaded value and whether it reports a failed lookup, which is not cached.
+func cachedEach[T any](ctx context.Context, cache *registryCache, prefix string, ttl time.Duration, subjectNames []string, load func(context.Context, []string) ([]T, error), describe func(T) (string, bool)) ([]T, error) {
+	values := make([]T, len(subjectNames))
+	found := make([]bool, len(subjectNames))
+
+	var missing []string
+	for i, subjectName := range subjectNames {
+		if value, ok := cache.get(prefix + ":" + subjectName); ok {
+			values[i], found[i] = value.(T), true
+			continue
+		}
+		missing = append(missing, subjectName)
+	}
+
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	missingKey, err := json.Marshal(missing)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling subject names: %v", err)
+	}
+
+	fetched, err := cache.do(ctx, prefix+"s:"+string(missingKey), func(ctx context.Context, generation uint64) (any, error) {
+		fetched, err := load(ctx, missing)
+		for _, value := range fetched {
+			// Failed lookups are retried on the next page load
+			subjectName, failed := describe(value)
+			if failed {
+				continue
+			}
+			cache.set(prefix+":"+subjectName, value, ttl, generation)
+		}
+		return fetched, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fetchedByName := make(map[string]T, len(missing))
+	for _, value := range fetched.([]T) {
+		subjectName, _ := describe(value)
+		fetchedByName[subjectName] = value
+	}
+
+	for i, subjectName := range subjectNames {
+		if !found[i] {
+			values[i] = fetchedByName[subjectName]
+		}
+	}
+
+	return values, nil
+}
+
+// cached returns the value stored under key, loading it when missing or expired.
+// Concurrent loads of the same key wait for the first one instead of calling
+// the registry again.
+func cached[T any](ctx context.Context, cache *registryCache, key string, ttl time.Duration, load func(context.Context) (T, error)) (T, error) {
+	if value, ok := cache.get(key); ok {
+		return value.(T), nil
+	}
+
+	value, err := cache.do(ctx, key, func(ctx context.Context, generation uint64) (any, error) {
+		value, err := load(ctx)
+		if err == nil {
+			cache.set(key, value, ttl, generation)
+		}
+		return value, err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return value.(T), nil
+}
+
+type cacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+// registryCache is a TTL map with a singleflight group merging concurrent loads
+type registryCache struct {
+	logger *slog.Logger
+	group  singleflight.Group
+
+	mu         sync.Mutex
+	entries    map[string]cacheEntry
+	generation uint64
+	now        func() time.Time
+}
+
+func newRegistryCache(logger *slog.Logger) *registryCache {
+	return &registryCache{
+		logger:  logger,
+		entries: make(map[string]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+func (c *registryCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		c.logger.Debug("registryCache - Cache miss",
+			"key", key)
+
+		return nil, false
+	}
+
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		c.logger.Debug("registryCache - Cache entry expired",
+			"key", key)
+
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+// set stores a value loaded during the given generation. Values loaded before a
+// flush are dropped so a flush is never undone by a slow registry call.
+func (c *registryCache) set(key string, value any, ttl time.Duration, generation uint64) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	c.entries[key] = cacheEntry{value: value, expires: c.now().Add(ttl)}
+}
+
+// do runs load once for all concurrent callers of the same key. Callers arriving
+// after a flush do not join a load started before it.
+//
+// The load runs with the context of the caller that started it. If that caller
+// goes away, the load is canceled and the callers still waiting start a new one.
+func (c *registryCache) do(ctx context.Context, key string, load func(ctx context.Context, generation uint64) (any, error)) (any, error) {
+	for {
+		c.mu.Lock()
+		generation := c.generation
+		c.mu.Unlock()
+
+		results := c.group.DoChan(fmt.Sprintf("%d/%s", generation, key), func() (any, error) {
+			return load(ctx, generation)
+		})
+
+		select {
+		case result := <-results:
+			if errors.Is(result.Err, helpers.ErrRequestCanceled) && ctx.Err() == nil {
+				continue
+			}
+			return result.Val, result.Err
+		case <-ctx.Done():
+			return nil, helpers.RequestContextError(ctx, ctx.Err())
+		}
+	}
+}
+
+// invalidate drops the entries whose key matches. Like a flush, it also drops the
+// values of loads still running, which may predate the change.
+func (c *registryCache) invalidate(match func(key string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dropped := 0
+	for key := range c.entries {
+		if match(key) {
+			delete(c.entries, key)
+			dropped++
+		}
+	}
+	c.generation++
+
+	c.logger.Debug("registryCache - Cache entries invalidated",
+		"entries", dropped)
+}
+
+func (c *registryCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.logger.Debug("registryCache - Cache flushed",
+		"entries", len(c.entries))
+
+	c.entries = make(map[string]cacheEntry)
+	c.generation++
+}
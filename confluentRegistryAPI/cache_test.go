@@ -0,0 +1,178 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kafka-board/types"
+)
+
+func TestCachedRegistryAPI(t *testing.T) {
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	release := make(chan struct{})
+	close(release)
+	gate := release
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		wait := gate
+		mu.Unlock()
+		<-wait
+
+		switch r.URL.Path {
+		case "/subjects":
+			json.NewEncoder(w).Encode([]string{"orders", "customers"})
+		case "/subjects/orders/versions", "/subjects/customers/versions":
+			if r.Method == http.MethodPost {
+				json.NewEncoder(w).Encode(map[string]int{"id": 0})
+				return
+			}
+			json.NewEncoder(w).Encode([]int{1})
+		default:
+			json.NewEncoder(w).Encode(map[string]string{"compatibilityLevel": "BACKWARD"})
+		}
+	}))
+	defer server.Close()
+
+	hitsFor := func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits[path]
+	}
+
+	newCachedAPI := func() *CachedRegistryAPI {
+		mu.Lock()
+		hits = make(map[string]int)
+		mu.Unlock()
+
+		registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+		return ReturnCachedRegistryAPI(registryAPI, CacheConfig{Subjects: time.Minute, Configs: time.Minute, Schemas: time.Minute})
+	}
+
+	t.Run("reads are served from the cache until they expire", func(t *testing.T) {
+		cachedAPI := newCachedAPI()
+		now := time.Now()
+		cachedAPI.cache.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			if _, err := cachedAPI.ReturnSubjects(context.Background()); err != nil {
+				t.Fatalf("ReturnSubjects() unexpected error: %v", err)
+			}
+		}
+		if got := hitsFor("/subjects"); got != 1 {
+			t.Errorf("registry called %d times, want 1", got)
+		}
+
+		now = now.Add(2 * time.Minute)
+		cachedAPI.ReturnSubjects(context.Background())
+		if got := hitsFor("/subjects"); got != 2 {
+			t.Errorf("registry called %d times after expiry, want 2", got)
+		}
+	})
+
+	t.Run("concurrent identical reads are merged", func(t *testing.T) {
+		cachedAPI := newCachedAPI()
+
+		mu.Lock()
+		blocked := make(chan struct{})
+		gate = blocked
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			gate = release
+			mu.Unlock()
+		}()
+
+		var wg sync.WaitGroup
+		var failures atomic.Int32
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := cachedAPI.ReturnSubjects(context.Background()); err != nil {
+					failures.Add(1)
+				}
+			}()
+		}
+
+		// Let every caller reach the cache before the registry answers
+		time.Sleep(50 * time.Millisecond)
+		close(blocked)
+		wg.Wait()
+
+		if failures.Load() != 0 {
+			t.Errorf("%d calls failed", failures.Load())
+		}
+		if got := hitsFor("/subjects"); got != 1 {
+			t.Errorf("registry called %d times, want 1", got)
+		}
+	})
+
+	t.Run("only subjects missing from the cache are requested", func(t *testing.T) {
+		cachedAPI := newCachedAPI()
+
+		if _, err := cachedAPI.ReturnSubjectConfigs(context.Background(), []string{"orders"}); err != nil {
+			t.Fatalf("ReturnSubjectConfigs() unexpected error: %v", err)
+		}
+		configs, err := cachedAPI.ReturnSubjectConfigs(context.Background(), []string{"customers", "orders"})
+		if err != nil {
+			t.Fatalf("ReturnSubjectConfigs() unexpected error: %v", err)
+		}
+
+		if configs[0].GetName() != "customers" || configs[1].GetName() != "orders" {
+			t.Errorf("ReturnSubjectConfigs() order = %s, %s, want customers, orders", configs[0].GetName(), configs[1].GetName())
+		}
+		if hitsFor("/config/orders") != 1 || hitsFor("/config/customers") != 1 {
+			t.Errorf("each subject config should be requested once, got %d and %d", hitsFor("/config/orders"), hitsFor("/config/customers"))
+		}
+	})
+
+	t.Run("flush drops cached reads", func(t *testing.T) {
+		cachedAPI := newCachedAPI()
+
+		cachedAPI.ReturnSubjects(context.Background())
+		cachedAPI.FlushCache()
+		cachedAPI.ReturnSubjects(context.Background())
+
+		if got := hitsFor("/subjects"); got != 2 {
+			t.Errorf("registry called %d times, want 2", got)
+		}
+	})
+	t.Run("imports only drop the entries of the subject", func(t *testing.T) {
+		cachedAPI := newCachedAPI()
+		ctx := context.Background()
+
+		read := func() {
+			cachedAPI.ReturnSubjects(ctx)
+			cachedAPI.ReturnSubjectConfigs(ctx, []string{"orders"})
+			for _, subjectName := range []string{"orders", "customers"} {
+				if _, err := cachedAPI.GetSchemas(ctx, subjectName, false); err != nil {
+					t.Fatalf("GetSchemas() unexpected error: %v", err)
+				}
+			}
+		}
+
+		read()
+		if err := cachedAPI.ImportSchema(ctx, "orders", types.Schema{Version: 2, Schema: `{"type": "string"}`}); err != nil {
+			t.Fatalf("ImportSchema() unexpected error: %v", err)
+		}
+		read()
+
+		// The orders versions are listed twice around the import request
+		expected := map[string]int{"/subjects": 2, "/subjects/orders/versions": 3, "/subjects/customers/versions": 1, "/config/orders": 1}
+		for path, want := range expected {
+			if got := hitsFor(path); got != want {
+				t.Errorf("%s requested %d times, want %d", path, got, want)
+			}
+		}
+	})
+}
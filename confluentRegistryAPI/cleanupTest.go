@@ -3,7 +3,6 @@ package confluentRegistryAPI
 import (
 	"fmt"
 	"net/http"
-	"os"
 )
 
 func (r *RegistryAPI) deleteDefaultConfig() error {
@@ -13,24 +12,16 @@ func (r *RegistryAPI) deleteDefaultConfig() error {
 }
 
 func (r *RegistryAPI) deleteAllSubjects(subjectNames []string) (string, error) {
-	client := &http.Client{}
-
-	// Use the environment variable for Schema Registry URL
-	registryURL := os.Getenv("SCHEMA_REGISTRY_URL")
-	if registryURL == "" {
-		registryURL = "http://schema-registry:8081"
-	}
-
-	baseURL := fmt.Sprintf("%s/subjects", registryURL)
+	baseURL := fmt.Sprintf("%s/subjects", r.baseRegistryURL)
 	r.logger.Debug("DeleteAllSubjects - Using Schema Registry URL", "url", baseURL)
 
 	for _, subjectName := range subjectNames {
-		req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/%s", baseURL, subjectName), nil)
+		req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/%s", baseURL, escapeSubject(subjectName)), nil)
 
 		if err != nil {
 			return "", err
 		}
-		resp, err := client.Do(req)
+		resp, err := r.client.Do(req)
 
 		if err != nil {
 			return "", err
@@ -41,8 +32,8 @@ func (r *RegistryAPI) deleteAllSubjects(subjectNames []string) (string, error) {
 			r.logger.Error("DeleteAllSubjects - Unexpected status code",
 				"status", resp.StatusCode,
 				"subject", subjectName,
-				"url", fmt.Sprintf("%s/%s", baseURL, subjectName))
-			return fmt.Sprintf("%s/%s", baseURL, subjectName), fmt.Errorf("DeleteAllSubjects -unexpected status code: %d for subject: %s", resp.StatusCode, subjectName)
+				"url", fmt.Sprintf("%s/%s", baseURL, escapeSubject(subjectName)))
+			return fmt.Sprintf("%s/%s", baseURL, escapeSubject(subjectName)), fmt.Errorf("DeleteAllSubjects -unexpected status code: %d for subject: %s", resp.StatusCode, subjectName)
 		}
 		r.logger.Debug("DeleteAllSubjects - Subject deleted",
 			"subject", subjectName)
@@ -0,0 +1,115 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"kafka-board/helpers"
+	"kafka-board/registryErrors"
+	"kafka-board/types"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// TestSchemaAgainstVersions checks a proposed schema against every live version of the
+// subject, as a *_TRANSITIVE compatibility level does, or against the latest version
+// only when latestOnly is set. The result lists the outcome for each version, oldest
+// first, and is compatible when the schema is compatible with all of them.
+func (r *RegistryAPI) TestSchemaAgainstVersions(ctx context.Context, subjectName string, latestOnly bool, proposed types.Schema) (types.Response, error) {
+	versions, err := r.GetSubjectVersions(ctx, subjectName, false)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("TestSchemaAgainstVersions - Error listing versions",
+			"error", err)
+
+		return helpers.CreateResponseObject(nil, fmt.Sprintf("Error listing versions: %v", err), http.StatusInternalServerError, 0), err
+	}
+
+	if latestOnly && len(versions) > 0 {
+		versions = versions[len(versions)-1:]
+	}
+
+	payload, resp, err := r.compatibilityPayload(ctx, proposed)
+	if helpers.CheckErr(err) {
+		return resp, err
+	}
+
+	// Versions are checked concurrently, a failed check is reported on its own row
+	results := make([]types.VersionCompatibility, len(versions))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(r.concurrency())
+
+	for i, version := range versions {
+		group.Go(func() error {
+			result, err := r.checkCompatibility(groupCtx, subjectName, version, payload)
+
+			var registryErr *registryErrors.Error
+			if helpers.CheckErr(err) && !errors.As(err, &registryErr) {
+				return fmt.Errorf("version %d: %w", version, err)
+			}
+
+			results[i] = types.VersionCompatibility{Version: version, Response: result}
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		r.logger.Debug("TestSchemaAgainstVersions - Error checking versions",
+			"error", err)
+
+		return helpers.CreateResponseObject(nil, fmt.Sprintf("Error checking versions: %v", err), http.StatusInternalServerError, 0), helpers.RequestContextError(ctx, err)
+	}
+
+	summary := summarizeVersions(results)
+
+	r.logger.Debug("TestSchemaAgainstVersions - Versions checked",
+		"subject", subjectName,
+		"versions", versions,
+		"message", summary.Message)
+
+	return summary, nil
+}
+
+// summarizeVersions combines the results of the versions into one response. The
+// schema is compatible when every check passed, and undetermined when a check failed
+// without a verdict.
+func summarizeVersions(results []types.VersionCompatibility) types.Response {
+	isCompatible := true
+	undetermined := false
+
+	var broken []string
+	for _, result := range results {
+		switch {
+		case result.IsCompatible == nil:
+			undetermined = true
+		case !*result.IsCompatible:
+			isCompatible = false
+			broken = append(broken, fmt.Sprintf("%d", result.Version))
+		}
+	}
+
+	response := helpers.CreateResponseObject(&isCompatible, "", http.StatusOK, 0)
+	response.Versions = results
+
+	switch {
+	case len(results) == 0:
+		response.Message = "The subject has no versions to check against"
+	case !isCompatible:
+		label := "version"
+		if len(broken) > 1 {
+			label = "versions"
+		}
+		response.Message = fmt.Sprintf("Incompatible with %s %s", label, strings.Join(broken, ", "))
+	case undetermined:
+		response.IsCompatible = nil
+		response.Message = "Some versions could not be checked"
+	case len(results) == 1:
+		response.Message = fmt.Sprintf("Compatible with version %d", results[0].Version)
+	default:
+		response.Message = fmt.Sprintf("Compatible with all %d versions", len(results))
+	}
+
+	return response
+}
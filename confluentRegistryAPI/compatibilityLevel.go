@@ -0,0 +1,356 @@
+package confluentRegistryAPI
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"kafka-board/helpers"
+	"kafka-board/registryErrors"
+	"kafka-board/schemaCompatibility"
+	"kafka-board/types"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// maxPreviewSubjects caps the subjects of a preview of the global level, each one
+// costs a registry call per version
+const maxPreviewSubjects = 200
+
+// UpdateCompatibilityLevel sets the compatibility level of a subject, or the global
+// level when subjectName is empty
+func (r *RegistryAPI) UpdateCompatibilityLevel(ctx context.Context, subjectName string, level string) error {
+	path := "/config"
+	if subjectName != "" {
+		path += "/" + escapeSubject(subjectName)
+	}
+
+	payload, err := json.Marshal(types.ConfigPayload{Compatibility: level})
+	if helpers.CheckErr(err) {
+		r.logger.Debug("UpdateCompatibilityLevel - Error marshalling payload",
+			"error", err)
+
+		return fmt.Errorf("error marshalling payload: %v", err)
+	}
+
+	if err := r.sendWrite(ctx, "PUT", path, payload, nil); helpers.CheckErr(err) {
+		r.logger.Debug("UpdateCompatibilityLevel - Error updating config",
+			"error", err)
+
+		return err
+	}
+
+	r.logger.Info("UpdateCompatibilityLevel - Compatibility level updated",
+		"subject", subjectName,
+		"level", level)
+
+	return nil
+}
+
+// DeleteCompatibilityLevel removes the compatibility level of a subject, so that
+// it takes the global default again
+func (r *RegistryAPI) DeleteCompatibilityLevel(ctx context.Context, subjectName string) error {
+	if err := r.sendWrite(ctx, "DELETE", "/config/"+escapeSubject(subjectName), nil, nil); helpers.CheckErr(err) {
+		r.logger.Debug("DeleteCompatibilityLevel - Error deleting config",
+			"error", err)
+
+		return err
+	}
+
+	r.logger.Info("DeleteCompatibilityLevel - Subject reset to the global default",
+		"subject", subjectName)
+
+	return nil
+}
+
+// UpdateConfig sets every setting of the config of a subject, or of the global config
+// when subjectName is empty, e.g. when restoring a backup
+func (r *RegistryAPI) UpdateConfig(ctx context.Context, subjectName string, config types.ConfigPayload) error {
+	path := "/config"
+	if subjectName != "" {
+		path += "/" + escapeSubject(subjectName)
+	}
+
+	payload, err := json.Marshal(config)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("UpdateConfig - Error marshalling payload",
+			"error", err)
+
+		return fmt.Errorf("error marshalling payload: %v", err)
+	}
+
+	if err := r.sendWrite(ctx, "PUT", path, payload, nil); helpers.CheckErr(err) {
+		r.logger.Debug("UpdateConfig - Error updating config",
+			"error", err)
+
+		return err
+	}
+
+	r.logger.Info("UpdateConfig - Config updated",
+		"subject", subjectName,
+		"level", config.Compatibility)
+
+	return nil
+}
+
+// sendWrite sends a write request to the registry and decodes the answer into
+// result, unless result is nil
+func (r *RegistryAPI) sendWrite(ctx context.Context, method string, path string, payload []byte, result any) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, r.baseRegistryURL+path, bytes.NewReader(payload))
+	if helpers.CheckErr(err) {
+		r.logger.Debug("sendWrite - Error creating request",
+			"error", err)
+
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("sendWrite - Error making request",
+			"error", err)
+
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("sendWrite - Error reading response",
+			"error", err)
+
+		return fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := registryErrors.FromResponse(resp.StatusCode, body)
+
+		r.logger.Debug("sendWrite - Unexpected status code",
+			"status", resp.StatusCode,
+			"error", err)
+
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		r.logger.Debug("sendWrite - Error parsing JSON",
+			"error", err)
+
+		return fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	return nil
+}
+
+// PreviewCompatibilityLevel lists the existing versions that would violate a
+// compatibility level: each version is checked against the ones before it, as the
+// registry would have done when it was registered. With an empty subjectName, the
+// first maxPreviewSubjects subjects taking the global default are previewed, and
+// those that cannot be read are reported as skipped.
+//
+// Versions are checked locally by the schemaCompatibility package, so subjects with
+// Protobuf schemas or Avro schemas with references are skipped.
+func (r *RegistryAPI) PreviewCompatibilityLevel(ctx context.Context, subjectName string, level string) (types.LevelPreview, error) {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	if !slices.Contains(schemaCompatibility.Levels, level) {
+		return types.LevelPreview{}, &registryErrors.Error{
+			Code:       registryErrors.ErrInvalidCompatibilityLevel.Code,
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("Invalid compatibility level %s, expected one of %s", level, strings.Join(schemaCompatibility.Levels, ", ")),
+		}
+	}
+
+	preview := types.LevelPreview{Level: level}
+
+	if subjectName != "" {
+		subjectPreview, err := r.previewSubject(ctx, subjectName, level, r.concurrency())
+		if helpers.CheckErr(err) {
+			r.logger.Debug("PreviewCompatibilityLevel - Error previewing subject",
+				"error", err)
+
+			return types.LevelPreview{}, helpers.RequestContextError(ctx, err)
+		}
+		preview.Subjects = []types.SubjectPreview{subjectPreview}
+	} else {
+		subjectNames, err := r.subjectsTakingGlobalDefault(ctx)
+		if helpers.CheckErr(err) {
+			r.logger.Debug("PreviewCompatibilityLevel - Error listing subjects taking the global default",
+				"error", err)
+
+			return types.LevelPreview{}, err
+		}
+
+		if len(subjectNames) > maxPreviewSubjects {
+			preview.Remaining = len(subjectNames) - maxPreviewSubjects
+			subjectNames = subjectNames[:maxPreviewSubjects]
+		}
+
+		// Subjects are previewed side by side, their versions one at a time, and a
+		// subject that cannot be previewed is reported as skipped
+		preview.Subjects, err = fetchEach(ctx, r, subjectNames, func(ctx context.Context, name string) (types.SubjectPreview, error) {
+			return r.previewSubject(ctx, name, level, 1)
+		}, func(name string, err error) types.SubjectPreview {
+			return types.SubjectPreview{
+				Subject:    name,
+				Violations: []types.LevelViolation{},
+				Skipped:    fmt.Sprintf("Could not be previewed: %v", err),
+			}
+		})
+		if helpers.CheckErr(err) {
+			r.logger.Debug("PreviewCompatibilityLevel - Stopped before every subject was previewed",
+				"error", err)
+
+			return types.LevelPreview{}, err
+		}
+	}
+
+	preview.Message = summarizePreview(preview)
+
+	r.logger.Debug("PreviewCompatibilityLevel - Level previewed",
+		"subject", subjectName,
+		"level", level,
+		"message", preview.Message)
+
+	return preview, nil
+}
+
+// subjectsTakingGlobalDefault lists the subjects without a config of their own
+func (r *RegistryAPI) subjectsTakingGlobalDefault(ctx context.Context) ([]string, error) {
+	subjectNames, err := r.ReturnSubjects(ctx)
+	if helpers.CheckErr(err) {
+		return nil, err
+	}
+
+	configs, err := r.ReturnSubjectConfigs(ctx, subjectNames)
+	if helpers.CheckErr(err) {
+		return nil, err
+	}
+
+	var defaults []string
+	for _, config := range configs {
+		if _, ok := config.(types.SubjectGlobalConfig); ok {
+			defaults = append(defaults, config.GetName())
+		}
+	}
+
+	return defaults, nil
+}
+
+// previewSubject checks each live version of a subject against the earlier ones,
+// fetching at most workers versions at a time
+func (r *RegistryAPI) previewSubject(ctx context.Context, subjectName string, level string, workers int) (types.SubjectPreview, error) {
+	preview := types.SubjectPreview{Subject: subjectName, Violations: []types.LevelViolation{}}
+
+	versions, err := r.GetSubjectVersions(ctx, subjectName, false)
+	if helpers.CheckErr(err) {
+		return preview, err
+	}
+
+	schemas := make([]types.Schema, len(versions))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(workers)
+
+	for i, version := range versions {
+		group.Go(func() error {
+			schema, err := r.GetSubjectVersion(groupCtx, subjectName, version, false)
+			if helpers.CheckErr(err) {
+				return fmt.Errorf("version %d: %w", version, err)
+			}
+
+			schemas[i] = schema
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return preview, err
+	}
+
+	bodies := make([]string, len(schemas))
+	for i, schema := range schemas {
+		switch {
+		case schema.GetSchemaType() == types.SchemaTypeProtobuf:
+			preview.Skipped = "Protobuf schemas cannot be checked locally"
+			return preview, nil
+		case schema.GetSchemaType() == types.SchemaTypeAvro && len(schema.References) > 0:
+			preview.Skipped = fmt.Sprintf("Version %d references other subjects, Avro references cannot be checked locally", schema.Version)
+			return preview, nil
+		}
+		bodies[i] = schema.Schema
+	}
+
+	for i := 1; i < len(schemas); i++ {
+		result, err := schemaCompatibility.CheckSchema(schemas[i].GetSchemaType(), level, bodies[i], bodies[:i])
+		if err != nil {
+			preview.Skipped = fmt.Sprintf("Version %d could not be checked: %v", versions[i], err)
+			return preview, nil
+		}
+		if result.Compatible {
+			continue
+		}
+
+		// Checks give the position of the earlier version, reported as its number
+		violation := types.LevelViolation{Version: versions[i], IncompatibleWith: []int{}}
+		for j, check := range result.Checks {
+			result.Checks[j].Version = versions[check.Version-1]
+			if !check.Compatible && !slices.Contains(violation.IncompatibleWith, result.Checks[j].Version) {
+				violation.IncompatibleWith = append(violation.IncompatibleWith, result.Checks[j].Version)
+			}
+		}
+		violation.Incompatibilities = result.Incompatibilities()
+
+		preview.Violations = append(preview.Violations, violation)
+	}
+
+	return preview, nil
+}
+
+// summarizePreview describes the outcome of a preview in one sentence
+func summarizePreview(preview types.LevelPreview) string {
+	violations, skipped := 0, 0
+	for _, subject := range preview.Subjects {
+		violations += len(subject.Violations)
+		if subject.Skipped != "" {
+			skipped++
+		}
+	}
+
+	var message string
+	switch {
+	case len(preview.Subjects) == 0:
+		return "No subject takes the global default"
+	case preview.Level == schemaCompatibility.LevelNone:
+		return "Nothing is checked at NONE, every version complies"
+	case violations == 0:
+		message = fmt.Sprintf("Every version complies with %s", preview.Level)
+	case violations == 1:
+		message = fmt.Sprintf("1 version violates %s", preview.Level)
+	default:
+		message = fmt.Sprintf("%d versions violate %s", violations, preview.Level)
+	}
+
+	if skipped > 0 {
+		message += fmt.Sprintf(", %d of %d subjects could not be checked", skipped, len(preview.Subjects))
+	}
+	if preview.Remaining > 0 {
+		message += fmt.Sprintf(", %d more subjects taking the global default were not previewed", preview.Remaining)
+	}
+
+	return message
+}
@@ -0,0 +1,211 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kafka-board/registryErrors"
+)
+
+func TestUpdateCompatibilityLevel(t *testing.T) {
+	tests := []struct {
+		name         string
+		subject      string
+		level        string
+		reset        bool
+		expectedCall string
+		expectedBody string
+	}{
+		{
+			name:         "subject level is set",
+			subject:      "orders",
+			level:        "FULL",
+			expectedCall: "PUT /config/orders",
+			expectedBody: `{"compatibility":"FULL"}`,
+		},
+		{
+			name:         "global level is set",
+			level:        "BACKWARD",
+			expectedCall: "PUT /config",
+			expectedBody: `{"compatibility":"BACKWARD"}`,
+		},
+		{
+			name:         "subject is reset to the global default",
+			subject:      "orders",
+			reset:        true,
+			expectedCall: "DELETE /config/orders",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var call, body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				content, _ := io.ReadAll(r.Body)
+				call, body = r.Method+" "+r.URL.Path, string(content)
+				fmt.Fprint(w, `{"compatibility": "FULL"}`)
+			}))
+			defer server.Close()
+
+			registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+
+			var err error
+			if tt.reset {
+				err = registryAPI.DeleteCompatibilityLevel(context.Background(), tt.subject)
+			} else {
+				err = registryAPI.UpdateCompatibilityLevel(context.Background(), tt.subject, tt.level)
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if call != tt.expectedCall || body != tt.expectedBody {
+				t.Errorf("request = %s %s, want %s %s", call, body, tt.expectedCall, tt.expectedBody)
+			}
+		})
+	}
+
+	t.Run("invalid level is reported", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"error_code": 42203, "message": "Invalid compatibility level"}`)
+		}))
+		defer server.Close()
+
+		registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+		if err := registryAPI.UpdateCompatibilityLevel(context.Background(), "orders", "SIDEWAYS"); !errors.Is(err, registryErrors.ErrInvalidCompatibilityLevel) {
+			t.Errorf("UpdateCompatibilityLevel() error = %v, want ErrInvalidCompatibilityLevel", err)
+		}
+	})
+}
+
+func TestPreviewCompatibilityLevel(t *testing.T) {
+	// Version 2 adds a property to a closed content model, version 3 removes it again
+	schemas := map[string]string{
+		"1": `{"type":"object","properties":{"id":{"type":"integer"}},"additionalProperties":false}`,
+		"2": `{"type":"object","properties":{"id":{"type":"integer"},"note":{"type":"string"}},"additionalProperties":false}`,
+		"3": `{"type":"object","properties":{"id":{"type":"integer"}},"additionalProperties":false}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/subjects/orders/versions" {
+			fmt.Fprint(w, `[1, 2, 3]`)
+			return
+		}
+
+		version := r.URL.Path[len("/subjects/orders/versions/"):]
+		json.NewEncoder(w).Encode(map[string]any{"subject": "orders", "version": json.Number(version), "schemaType": "JSON", "schema": schemas[version]})
+	}))
+	defer server.Close()
+
+	registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+
+	tests := []struct {
+		name               string
+		level              string
+		expectedViolations map[int][]int
+	}{
+		{
+			name:               "backward flags the removed property",
+			level:              "BACKWARD",
+			expectedViolations: map[int][]int{3: {2}},
+		},
+		{
+			name:               "forward flags the added property",
+			level:              "FORWARD",
+			expectedViolations: map[int][]int{2: {1}},
+		},
+		{
+			name:               "full transitive flags every earlier version",
+			level:              "full_transitive",
+			expectedViolations: map[int][]int{2: {1}, 3: {2}},
+		},
+		{
+			name:               "none flags nothing",
+			level:              "NONE",
+			expectedViolations: map[int][]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview, err := registryAPI.PreviewCompatibilityLevel(context.Background(), "orders", tt.level)
+			if err != nil {
+				t.Fatalf("PreviewCompatibilityLevel() unexpected error: %v", err)
+			}
+
+			violations := map[int][]int{}
+			for _, violation := range preview.Subjects[0].Violations {
+				violations[violation.Version] = violation.IncompatibleWith
+				if len(violation.Incompatibilities) == 0 {
+					t.Errorf("version %d has no incompatibilities", violation.Version)
+				}
+			}
+			if !reflect.DeepEqual(violations, tt.expectedViolations) {
+				t.Errorf("violations = %v, want %v (%s)", violations, tt.expectedViolations, preview.Message)
+			}
+		})
+	}
+
+	t.Run("unknown level is refused", func(t *testing.T) {
+		if _, err := registryAPI.PreviewCompatibilityLevel(context.Background(), "orders", "SIDEWAYS"); !errors.Is(err, registryErrors.ErrInvalidCompatibilityLevel) {
+			t.Errorf("PreviewCompatibilityLevel() error = %v, want ErrInvalidCompatibilityLevel", err)
+		}
+	})
+}
+
+func TestPreviewGlobalCompatibilityLevel(t *testing.T) {
+	subjectNames := []string{"broken", "orders"}
+	for i := len(subjectNames); i <= maxPreviewSubjects; i++ {
+		subjectNames = append(subjectNames, fmt.Sprintf("empty-%03d", i))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/subjects":
+			json.NewEncoder(w).Encode(subjectNames)
+		case strings.HasPrefix(r.URL.Path, "/config/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code": 40408, "message": "Subject does not have subject-level compatibility configured"}`)
+		case r.URL.Path == "/subjects/broken/versions":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error_code": 50001, "message": "Error in the backend"}`)
+		case r.URL.Path == "/subjects/orders/versions":
+			fmt.Fprint(w, `[1]`)
+		case r.URL.Path == "/subjects/orders/versions/1":
+			fmt.Fprint(w, `{"subject": "orders", "version": 1, "schemaType": "JSON", "schema": "{\"type\": \"object\"}"}`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer server.Close()
+
+	registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+
+	preview, err := registryAPI.PreviewCompatibilityLevel(context.Background(), "", "BACKWARD")
+	if err != nil {
+		t.Fatalf("PreviewCompatibilityLevel() unexpected error: %v", err)
+	}
+
+	if len(preview.Subjects) != maxPreviewSubjects || preview.Remaining != 1 {
+		t.Fatalf("previewed %d subjects with %d remaining, want %d and 1", len(preview.Subjects), preview.Remaining, maxPreviewSubjects)
+	}
+	if broken := preview.Subjects[0]; broken.Subject != "broken" || !strings.Contains(broken.Skipped, "50001") {
+		t.Errorf("broken = %+v, want it skipped with the registry error", broken)
+	}
+	if orders := preview.Subjects[1]; orders.Subject != "orders" || orders.Skipped != "" || len(orders.Violations) != 0 {
+		t.Errorf("orders = %+v, want it previewed without violations", orders)
+	}
+	if !strings.Contains(preview.Message, "1 of 200 subjects could not be checked") || !strings.Contains(preview.Message, "1 more subjects") {
+		t.Errorf("Message = %q, want the skipped and remaining subjects", preview.Message)
+	}
+}
@@ -0,0 +1,128 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"kafka-board/types"
+)
+
+func TestTestSchemaAgainstVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/subjects/orders/versions":
+			fmt.Fprint(w, `[1, 2, 3]`)
+		case "/compatibility/subjects/orders/versions/1":
+			fmt.Fprint(w, `{"is_compatible": false, "messages": ["{errorType:'TYPE_NARROWED', description:'The type at path '#/properties/id' was narrowed'}"]}`)
+		case "/compatibility/subjects/orders/versions/2", "/compatibility/subjects/orders/versions/3":
+			if r.URL.Query().Get("verbose") != "true" {
+				t.Errorf("compatibility check without verbose=true")
+			}
+			fmt.Fprint(w, `{"is_compatible": true}`)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client(), configConcurrency: 2}
+	proposed := types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type":"object","properties":{"id":{"type":"integer"}}}`}
+
+	tests := []struct {
+		name               string
+		latestOnly         bool
+		expectedVersions   string
+		expectedCompatible bool
+		expectedMessage    string
+	}{
+		{
+			name:               "every version is checked",
+			expectedVersions:   "1:false 2:true 3:true",
+			expectedCompatible: false,
+			expectedMessage:    "Incompatible with version 1",
+		},
+		{
+			name:               "only the latest version is checked",
+			latestOnly:         true,
+			expectedVersions:   "3:true",
+			expectedCompatible: true,
+			expectedMessage:    "Compatible with version 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := registryAPI.TestSchemaAgainstVersions(context.Background(), "orders", tt.latestOnly, proposed)
+			if err != nil {
+				t.Fatalf("TestSchemaAgainstVersions() unexpected error: %v", err)
+			}
+
+			var versions []string
+			for _, result := range resp.Versions {
+				versions = append(versions, fmt.Sprintf("%d:%t", result.Version, *result.IsCompatible))
+			}
+			if got := strings.Join(versions, " "); got != tt.expectedVersions {
+				t.Errorf("versions = %s, want %s", got, tt.expectedVersions)
+			}
+			if resp.IsCompatible == nil || *resp.IsCompatible != tt.expectedCompatible {
+				t.Errorf("IsCompatible = %v, want %t", resp.IsCompatible, tt.expectedCompatible)
+			}
+			if resp.Message != tt.expectedMessage {
+				t.Errorf("Message = %q, want %q", resp.Message, tt.expectedMessage)
+			}
+		})
+	}
+
+	t.Run("incompatibilities are kept per version", func(t *testing.T) {
+		resp, _ := registryAPI.TestSchemaAgainstVersions(context.Background(), "orders", false, proposed)
+		if len(resp.Versions) == 0 || len(resp.Versions[0].Incompatibilities) != 1 || resp.Versions[0].Incompatibilities[0].Path != "#/properties/id" {
+			t.Errorf("version 1 incompatibilities = %+v, want the narrowed type", resp.Versions)
+		}
+	})
+}
+
+func TestTestSchemaAgainstVersionsConcurrency(t *testing.T) {
+	// Each check waits for the other one, so both only pass when checked concurrently
+	var inFlight sync.WaitGroup
+	inFlight.Add(2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/subjects/orders/versions" {
+			fmt.Fprint(w, `[1, 2]`)
+			return
+		}
+
+		inFlight.Done()
+		waited := make(chan struct{})
+		go func() {
+			inFlight.Wait()
+			close(waited)
+		}()
+		select {
+		case <-waited:
+			fmt.Fprint(w, `{"is_compatible": true}`)
+		case <-time.After(time.Second):
+			w.WriteHeader(http.StatusGatewayTimeout)
+		}
+	}))
+	defer server.Close()
+
+	// No concurrency configured, the default applies
+	registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+	proposed := types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type":"object"}`}
+
+	resp, err := registryAPI.TestSchemaAgainstVersions(context.Background(), "orders", false, proposed)
+	if err != nil {
+		t.Fatalf("TestSchemaAgainstVersions() unexpected error: %v", err)
+	}
+	if resp.IsCompatible == nil || !*resp.IsCompatible {
+		t.Errorf("TestSchemaAgainstVersions() = %+v, want both versions checked at the same time", resp)
+	}
+}
@@ -1,6 +1,8 @@
 package confluentRegistryAPI
 
 import (
+	"context"
+	"kafka-board/types"
 	"log/slog"
 	"net/http"
 	"testing"
@@ -13,7 +15,10 @@ type ConfigResponse struct {
 
 func TestCompatibility(t *testing.T) {
 	// Create registryAPI instance
-	registryAPI := ReturnRegistryAPI(slog.Default())
+	registryAPI, err := ReturnRegistryAPI(slog.Default())
+	if err != nil {
+		t.Fatalf("Error creating registry API: %v", err)
+	}
 
 	// Original schema being tested against:
 	// {
@@ -90,7 +95,7 @@ func TestCompatibility(t *testing.T) {
 				"compatible", tc.compatible,
 				"newSchemaStr", tc.newSchemaStr)
 
-			resp, err := registryAPI.TestSchema(tc.subjectName, 5, tc.newSchemaStr)
+			resp, err := registryAPI.TestSchema(context.Background(), tc.subjectName, 5, types.Schema{SchemaType: types.SchemaTypeJSON, Schema: tc.newSchemaStr})
 			if err != nil {
 				slog.Error("TestCompatibility - Error testing schema",
 					"error", err)
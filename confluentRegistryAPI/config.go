@@ -0,0 +1,164 @@
+package confluentRegistryAPI
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// defaultConfigConcurrency is the number of subject configs fetched at the same time
+const defaultConfigConcurrency = 8
+
+// defaultRequestTimeout is the deadline of each registry call
+const defaultRequestTimeout = 10 * time.Second
+
+// registryNamePattern keeps registry names safe to carry in URLs as-is
+var registryNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// RegistryConfig holds the connection settings of a schema registry
+type RegistryConfig struct {
+	// Name the registry is listed under, e.g. dev, staging or prod
+	Name    string `json:"name"`
+	BaseURL string `json:"baseUrl"`
+
+	// HTTP basic auth, the API key and secret on Confluent Cloud
+	APIKey    string `json:"apiKey"`
+	APISecret string `json:"apiSecret"`
+
+	// File holding a bearer token, re-read when the token expires
+	BearerTokenFile string `json:"bearerTokenFile"`
+
+	// TLS settings: a CA bundle to trust and a client certificate for mTLS
+	CAFile   string `json:"caFile"`
+	CertFile string `json:"certFile"`
+	KeyFile  string `json:"keyFile"`
+
+	// Number of subject configs fetched at the same time, defaults to 8
+	ConfigConcurrency int `json:"configConcurrency"`
+
+	// Deadline of each registry call as a Go duration, defaults to 10s
+	RequestTimeout string `json:"requestTimeout"`
+
+	// Number of times a failed GET is retried, defaults to 3, 0 disables retries
+	MaxRetries *int `json:"maxRetries"`
+
+	// Consecutive failed calls opening the circuit breaker, defaults to 5
+	BreakerThreshold int `json:"breakerThreshold"`
+
+	// Time the breaker stays open before probing the registry, defaults to 30s
+	BreakerCooldown string `json:"breakerCooldown"`
+}
+
+// getRegistryConfig reads the registry connection settings from the environment
+func getRegistryConfig() RegistryConfig {
+	name := os.Getenv("REGISTRY_NAME")
+	if name == "" {
+		name = "default"
+	}
+
+	concurrency, _ := strconv.Atoi(os.Getenv("REGISTRY_CONFIG_CONCURRENCY"))
+	threshold, _ := strconv.Atoi(os.Getenv("REGISTRY_BREAKER_THRESHOLD"))
+
+	var maxRetries *int
+	if retries, err := strconv.Atoi(os.Getenv("REGISTRY_MAX_RETRIES")); err == nil {
+		maxRetries = &retries
+	}
+
+	return RegistryConfig{
+		Name:              name,
+		ConfigConcurrency: concurrency,
+		RequestTimeout:    os.Getenv("REGISTRY_REQUEST_TIMEOUT"),
+		MaxRetries:        maxRetries,
+		BreakerThreshold:  threshold,
+		BreakerCooldown:   os.Getenv("REGISTRY_BREAKER_COOLDOWN"),
+		BaseURL:           getBaseRegistryURL(),
+		APIKey:            os.Getenv("REGISTRY_API_KEY"),
+		APISecret:         os.Getenv("REGISTRY_API_SECRET"),
+		BearerTokenFile:   os.Getenv("REGISTRY_BEARER_TOKEN_FILE"),
+		CAFile:            os.Getenv("REGISTRY_TLS_CA_FILE"),
+		CertFile:          os.Getenv("REGISTRY_TLS_CERT_FILE"),
+		KeyFile:           os.Getenv("REGISTRY_TLS_KEY_FILE"),
+	}
+}
+
+// registriesFile is the format of the file named by REGISTRY_CONFIG_FILE
+type registriesFile struct {
+	Registries []RegistryConfig `json:"registries"`
+}
+
+// getRegistryConfigs returns the configured registries. They are read from the JSON
+// file named by REGISTRY_CONFIG_FILE if set, otherwise a single registry is
+// configured from the environment.
+func getRegistryConfigs() ([]RegistryConfig, error) {
+	path := os.Getenv("REGISTRY_CONFIG_FILE")
+	if path == "" {
+		config := getRegistryConfig()
+		if !registryNamePattern.MatchString(config.Name) {
+			return nil, fmt.Errorf("invalid registry name %q: only letters, digits, - and _ are allowed", config.Name)
+		}
+		return []RegistryConfig{config}, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading registry config file: %v", err)
+	}
+
+	var file registriesFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("error parsing registry config file: %v", err)
+	}
+
+	if len(file.Registries) == 0 {
+		return nil, fmt.Errorf("no registries defined in %s", path)
+	}
+
+	seen := make(map[string]bool, len(file.Registries))
+	for _, config := range file.Registries {
+		if !registryNamePattern.MatchString(config.Name) {
+			return nil, fmt.Errorf("invalid registry name %q: only letters, digits, - and _ are allowed", config.Name)
+		}
+		if seen[config.Name] {
+			return nil, fmt.Errorf("registry %s is defined more than once", config.Name)
+		}
+		if config.BaseURL == "" {
+			return nil, fmt.Errorf("registry %s has no baseUrl", config.Name)
+		}
+		if config.ConfigConcurrency < 0 {
+			return nil, fmt.Errorf("registry %s has a negative configConcurrency", config.Name)
+		}
+		if config.MaxRetries != nil && *config.MaxRetries < 0 {
+			return nil, fmt.Errorf("registry %s has a negative maxRetries", config.Name)
+		}
+		if config.BreakerThreshold < 0 {
+			return nil, fmt.Errorf("registry %s has a negative breakerThreshold", config.Name)
+		}
+		seen[config.Name] = true
+	}
+
+	return file.Registries, nil
+}
+
+// ReturnRegistryAPIs creates one RegistryAPI per configured registry, in the order
+// they are configured
+func ReturnRegistryAPIs(logger *slog.Logger) ([]*RegistryAPI, error) {
+	configs, err := getRegistryConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	registryAPIs := make([]*RegistryAPI, 0, len(configs))
+	for _, config := range configs {
+		registryAPI, err := ReturnRegistryAPIForConfig(logger, config)
+		if err != nil {
+			return nil, fmt.Errorf("registry %s: %w", config.Name, err)
+		}
+		registryAPIs = append(registryAPIs, registryAPI)
+	}
+
+	return registryAPIs, nil
+}
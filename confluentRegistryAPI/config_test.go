@@ -0,0 +1,85 @@
+package confluentRegistryAPI
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetRegistryConfigs(t *testing.T) {
+	tests := []struct {
+		name          string
+		file          string
+		expectedNames []string
+		expectError   bool
+	}{
+		{
+			name:          "registries in file order",
+			file:          `{"registries": [{"name": "dev", "baseUrl": "http://dev:8081"}, {"name": "prod", "baseUrl": "https://prod:8081"}]}`,
+			expectedNames: []string{"dev", "prod"},
+		},
+		{
+			name:        "duplicate name",
+			file:        `{"registries": [{"name": "dev", "baseUrl": "http://a"}, {"name": "dev", "baseUrl": "http://b"}]}`,
+			expectError: true,
+		},
+		{
+			name:        "name not safe in URLs",
+			file:        `{"registries": [{"name": "dev env", "baseUrl": "http://a"}]}`,
+			expectError: true,
+		},
+		{
+			name:        "missing base URL",
+			file:        `{"registries": [{"name": "dev"}]}`,
+			expectError: true,
+		},
+		{
+			name:        "no registries",
+			file:        `{"registries": []}`,
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "registries.json")
+			if err := os.WriteFile(path, []byte(test.file), 0o600); err != nil {
+				t.Fatalf("error writing config file: %v", err)
+			}
+			t.Setenv("REGISTRY_CONFIG_FILE", path)
+
+			configs, err := getRegistryConfigs()
+			if test.expectError {
+				if err == nil {
+					t.Errorf("getRegistryConfigs() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getRegistryConfigs() unexpected error: %v", err)
+			}
+
+			if len(configs) != len(test.expectedNames) {
+				t.Fatalf("getRegistryConfigs() returned %d registries, want %d", len(configs), len(test.expectedNames))
+			}
+			for i, config := range configs {
+				if config.Name != test.expectedNames[i] {
+					t.Errorf("registry %d = %s, want %s", i, config.Name, test.expectedNames[i])
+				}
+			}
+		})
+	}
+
+	t.Run("single registry from the environment", func(t *testing.T) {
+		t.Setenv("REGISTRY_CONFIG_FILE", "")
+		t.Setenv("REGISTRY_NAME", "")
+
+		configs, err := getRegistryConfigs()
+		if err != nil {
+			t.Fatalf("getRegistryConfigs() unexpected error: %v", err)
+		}
+		if len(configs) != 1 || configs[0].Name != "default" {
+			t.Errorf("getRegistryConfigs() = %+v, want a single registry named default", configs)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"kafka-board/helpers"
+)
+
+func TestRegistryCallContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			fmt.Fprint(w, `["orders"]`)
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	newRegistryAPI := func(timeout time.Duration) *RegistryAPI {
+		return &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client(), requestTimeout: timeout}
+	}
+
+	t.Run("calls past their deadline time out", func(t *testing.T) {
+		_, err := newRegistryAPI(10 * time.Millisecond).ReturnSubjects(context.Background())
+		if !errors.Is(err, helpers.ErrRequestTimedOut) {
+			t.Errorf("ReturnSubjects() error = %v, want ErrRequestTimedOut", err)
+		}
+	})
+
+	t.Run("calls of a canceled request are canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := newRegistryAPI(time.Second).ReturnSubjects(ctx)
+		if !errors.Is(err, helpers.ErrRequestCanceled) {
+			t.Errorf("ReturnSubjects() error = %v, want ErrRequestCanceled", err)
+		}
+	})
+
+	t.Run("merged reads survive the caller that started them going away", func(t *testing.T) {
+		cachedAPI := ReturnCachedRegistryAPI(newRegistryAPI(time.Second), CacheConfig{Subjects: time.Minute})
+
+		starter, cancel := context.WithCancel(context.Background())
+		starterErr := make(chan error, 1)
+		go func() {
+			_, err := cachedAPI.ReturnSubjects(starter)
+			starterErr <- err
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		waiterResult := make(chan error, 1)
+		go func() {
+			_, err := cachedAPI.ReturnSubjects(context.Background())
+			waiterResult <- err
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+
+		if err := <-starterErr; !errors.Is(err, helpers.ErrRequestCanceled) {
+			t.Errorf("starter error = %v, want ErrRequestCanceled", err)
+		}
+		if err := <-waiterResult; err != nil {
+			t.Errorf("waiter error = %v, want the subjects", err)
+		}
+	})
+}
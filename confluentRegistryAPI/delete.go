@@ -0,0 +1,223 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"kafka-board/helpers"
+	"kafka-board/registryErrors"
+	"kafka-board/types"
+)
+
+// DeleteSubject deletes every version of a subject and returns the deleted version
+// numbers. A soft delete keeps the versions readable with deleted=true, a permanent
+// delete removes them for good and needs a soft delete first. Subjects with a
+// version referenced by other schemas are refused with ErrReferenceExists.
+func (r *RegistryAPI) DeleteSubject(ctx context.Context, subjectName string, permanent bool) ([]int, error) {
+	// A permanent delete covers the soft-deleted versions
+	versions, err := r.GetSubjectVersions(ctx, subjectName, permanent)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("DeleteSubject - Error listing versions",
+			"error", err)
+
+		return nil, err
+	}
+
+	if err := r.checkNotReferenced(ctx, subjectName, versions); helpers.CheckErr(err) {
+		r.logger.Debug("DeleteSubject - Subject is referenced",
+			"error", err)
+
+		return nil, err
+	}
+
+	path := "/subjects/" + escapeSubject(subjectName)
+	if permanent {
+		path += "?permanent=true"
+	}
+
+	var deleted []int
+	if err := r.sendWrite(ctx, "DELETE", path, nil, &deleted); helpers.CheckErr(err) {
+		r.logger.Debug("DeleteSubject - Error deleting subject",
+			"error", err)
+
+		return nil, err
+	}
+
+	r.logger.Info("DeleteSubject - Subject deleted",
+		"subject", subjectName,
+		"permanent", permanent,
+		"versions", deleted)
+
+	return deleted, nil
+}
+
+// DeleteSubjectVersion deletes one version of a subject, softly or, once soft-deleted,
+// permanently. Versions referenced by other schemas are refused with ErrReferenceExists.
+func (r *RegistryAPI) DeleteSubjectVersion(ctx context.Context, subjectName string, version int, permanent bool) error {
+	if err := r.checkNotReferenced(ctx, subjectName, []int{version}); helpers.CheckErr(err) {
+		r.logger.Debug("DeleteSubjectVersion - Version is referenced",
+			"error", err)
+
+		return err
+	}
+
+	path := fmt.Sprintf("/subjects/%s/versions/%d", escapeSubject(subjectName), version)
+	if permanent {
+		path += "?permanent=true"
+	}
+
+	if err := r.sendWrite(ctx, "DELETE", path, nil, nil); helpers.CheckErr(err) {
+		r.logger.Debug("DeleteSubjectVersion - Error deleting version",
+			"error", err)
+
+		return err
+	}
+
+	r.logger.Info("DeleteSubjectVersion - Version deleted",
+		"subject", subjectName,
+		"version", version,
+		"permanent", permanent)
+
+	return nil
+}
+
+// RestoreSubjectVersion brings a soft-deleted version back. The registry has no
+// undelete, so its schema is registered again: it keeps its ID and gets a new
+// version number, and is checked under the compatibility level of the subject
+// like any new schema.
+func (r *RegistryAPI) RestoreSubjectVersion(ctx context.Context, subjectName string, version int) (types.Response, error) {
+	live, err := r.GetSubjectVersions(ctx, subjectName, false)
+	if helpers.CheckErr(err) && !errors.Is(err, registryErrors.ErrSubjectNotFound) {
+		r.logger.Debug("RestoreSubjectVersion - Error listing versions",
+			"error", err)
+
+		return registrationErrorResponse("Error listing versions", err), err
+	}
+
+	if slices.Contains(live, version) {
+		err := &registryErrors.Error{
+			Code:       registryErrors.ErrInvalidVersion.Code,
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    fmt.Sprintf("Version %d of %s is not soft-deleted", version, subjectName),
+		}
+
+		return registrationErrorResponse("Not restored", err), err
+	}
+
+	deleted, err := r.GetSubjectVersion(ctx, subjectName, version, true)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("RestoreSubjectVersion - Error fetching soft-deleted version",
+			"error", err)
+
+		return registrationErrorResponse("Error fetching the soft-deleted version", err), err
+	}
+
+	resp, err := r.RegisterSchema(ctx, subjectName, types.Schema{
+		SchemaType: deleted.SchemaType,
+		Schema:     deleted.Schema,
+		References: deleted.References,
+	}, false)
+	if helpers.CheckErr(err) {
+		return resp, err
+	}
+
+	resp.Message = fmt.Sprintf("Restored version %d as version %d with ID %d", version, resp.Registered.Version, resp.Registered.Id)
+
+	return resp, nil
+}
+
+// checkNotReferenced refuses versions referenced by other schemas, listing the IDs
+// of the referencing schemas
+func (r *RegistryAPI) checkNotReferenced(ctx context.Context, subjectName string, versions []int) error {
+	var referenced []string
+	for _, version := range versions {
+		ids, err := r.getReferencedBy(ctx, subjectName, version)
+		if helpers.CheckErr(err) {
+			return err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		idStrings := make([]string, len(ids))
+		for i, id := range ids {
+			idStrings[i] = fmt.Sprintf("%d", id)
+		}
+		referenced = append(referenced, fmt.Sprintf("version %d by schema IDs %s", version, strings.Join(idStrings, ", ")))
+	}
+
+	if len(referenced) == 0 {
+		return nil
+	}
+
+	return &registryErrors.Error{
+		Code:       registryErrors.ErrReferenceExists.Code,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    fmt.Sprintf("%s is referenced: %s", subjectName, strings.Join(referenced, "; ")),
+	}
+}
+
+// getReferencedBy returns the IDs of the schemas referencing a version of a subject
+func (r *RegistryAPI) getReferencedBy(ctx context.Context, subjectName string, version int) ([]int, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/subjects/%s/versions/%d/referencedby", r.baseRegistryURL, escapeSubject(subjectName), version)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("getReferencedBy - Error creating request",
+			"error", err)
+
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("getReferencedBy - Error making request",
+			"error", err)
+
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("getReferencedBy - Error reading response",
+			"error", err)
+
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := registryErrors.FromResponse(resp.StatusCode, body)
+
+		// Soft-deleted versions are not found here, the registry checks them on delete
+		if errors.Is(err, registryErrors.ErrVersionNotFound) {
+			return nil, nil
+		}
+
+		r.logger.Debug("getReferencedBy - Unexpected status code",
+			"status", resp.StatusCode,
+			"error", err)
+
+		return nil, err
+	}
+
+	var ids []int
+	if err := json.Unmarshal(body, &ids); err != nil {
+		r.logger.Debug("getReferencedBy - Error parsing JSON",
+			"error", err)
+
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,153 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"kafka-board/registryErrors"
+)
+
+func TestDeleteSubject(t *testing.T) {
+	tests := []struct {
+		name             string
+		permanent        bool
+		referencedBy     string
+		expectedDelete   string
+		expectedVersions []int
+		expectedErr      error
+	}{
+		{
+			name:             "soft delete",
+			referencedBy:     `[]`,
+			expectedDelete:   "/subjects/orders?",
+			expectedVersions: []int{1, 2},
+		},
+		{
+			name:             "permanent delete covers soft-deleted versions",
+			permanent:        true,
+			referencedBy:     `[]`,
+			expectedDelete:   "/subjects/orders?permanent=true",
+			expectedVersions: []int{1, 2, 3},
+		},
+		{
+			name:         "referenced subject is refused",
+			referencedBy: `[14, 15]`,
+			expectedErr:  registryErrors.ErrReferenceExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deleteCall string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodDelete:
+					deleteCall = r.URL.Path + "?" + r.URL.RawQuery
+					if tt.permanent {
+						fmt.Fprint(w, `[1, 2, 3]`)
+					} else {
+						fmt.Fprint(w, `[1, 2]`)
+					}
+				case r.URL.Path == "/subjects/orders/versions" && r.URL.Query().Get("deleted") == "true":
+					fmt.Fprint(w, `[1, 2, 3]`)
+				case r.URL.Path == "/subjects/orders/versions":
+					fmt.Fprint(w, `[1, 2]`)
+				default:
+					fmt.Fprint(w, tt.referencedBy)
+				}
+			}))
+			defer server.Close()
+
+			registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+			deleted, err := registryAPI.DeleteSubject(context.Background(), "orders", tt.permanent)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("DeleteSubject() error = %v, want %v", err, tt.expectedErr)
+				}
+				if deleteCall != "" {
+					t.Errorf("DeleteSubject() sent %s for a refused delete", deleteCall)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("DeleteSubject() unexpected error: %v", err)
+			}
+			if deleteCall != tt.expectedDelete {
+				t.Errorf("delete request = %s, want %s", deleteCall, tt.expectedDelete)
+			}
+			if !reflect.DeepEqual(deleted, tt.expectedVersions) {
+				t.Errorf("deleted = %v, want %v", deleted, tt.expectedVersions)
+			}
+		})
+	}
+}
+
+func TestRestoreSubjectVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		version         int
+		expectedMessage string
+		expectedErr     error
+	}{
+		{
+			name:            "soft-deleted version is registered again",
+			version:         2,
+			expectedMessage: "Restored version 2 as version 4 with ID 12",
+		},
+		{
+			name:        "live version is refused",
+			version:     1,
+			expectedErr: registryErrors.ErrInvalidVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/subjects/orders/versions":
+					if r.Method == http.MethodPost {
+						fmt.Fprint(w, `{"id": 12}`)
+						return
+					}
+					fmt.Fprint(w, `[1, 3]`)
+				case "/subjects/orders/versions/2":
+					fmt.Fprint(w, `{"subject": "orders", "version": 2, "id": 12, "schemaType": "JSON", "schema": "{\"type\":\"object\"}"}`)
+				case "/compatibility/subjects/orders/versions":
+					fmt.Fprint(w, `{"is_compatible": true}`)
+				case "/subjects/orders":
+					fmt.Fprint(w, `{"subject": "orders", "version": 4, "id": 12, "schema": "{}"}`)
+				default:
+					t.Errorf("unexpected %s request to %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+			resp, err := registryAPI.RestoreSubjectVersion(context.Background(), "orders", tt.version)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("RestoreSubjectVersion() error = %v, want %v", err, tt.expectedErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("RestoreSubjectVersion() unexpected error: %v", err)
+			}
+			if resp.Message != tt.expectedMessage {
+				t.Errorf("Message = %q, want %q", resp.Message, tt.expectedMessage)
+			}
+		})
+	}
+}
@@ -2,15 +2,42 @@ package confluentRegistryAPI
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 )
 
-func createTestSchemaRequest(subjectName string, version int, testJSON string, baseRegistryURL string) (*http.Request, error) {
-	requestURL := fmt.Sprintf("%s/compatibility/subjects/%s/versions/%d",
-		baseRegistryURL, subjectName, version)
+// createTestSchemaRequest builds a compatibility check against a version of the
+// subject. A version of 0 or less checks against the latest version.
+func createTestSchemaRequest(ctx context.Context, subjectName string, version int, testJSON string, baseRegistryURL string) (*http.Request, error) {
+	versionStr := "latest"
+	if version > 0 {
+		versionStr = fmt.Sprintf("%d", version)
+	}
+
+	// Verbose results list every incompatibility instead of just a verdict
+	requestURL := fmt.Sprintf("%s/compatibility/subjects/%s/versions/%s?verbose=true",
+		baseRegistryURL, escapeSubject(subjectName), versionStr)
+
+	return createCompatibilityRequest(ctx, requestURL, testJSON)
+}
+
+// createDryRunRequest builds a compatibility check against the versions the
+// compatibility level of the subject compares a new version with, every version
+// for a transitive level, the way registering the schema would be checked
+func createDryRunRequest(ctx context.Context, subjectName string, testJSON string, normalize bool, baseRegistryURL string) (*http.Request, error) {
+	requestURL := fmt.Sprintf("%s/compatibility/subjects/%s/versions?verbose=true",
+		baseRegistryURL, escapeSubject(subjectName))
+	if normalize {
+		requestURL += "&normalize=true"
+	}
+
+	return createCompatibilityRequest(ctx, requestURL, testJSON)
+}
 
-	req, err := http.NewRequest(
+func createCompatibilityRequest(ctx context.Context, requestURL string, testJSON string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
 		"POST",
 		requestURL,
 		bytes.NewBuffer([]byte(testJSON)),
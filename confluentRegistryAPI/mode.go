@@ -0,0 +1,146 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"kafka-board/helpers"
+	"kafka-board/registryErrors"
+	"kafka-board/types"
+)
+
+// GetMode fetches the mode of a subject, or the global mode when subjectName is
+// empty. Subjects without a mode of their own take the global one.
+func (r *RegistryAPI) GetMode(ctx context.Context, subjectName string) (types.SubjectMode, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+
+	url := r.baseRegistryURL + "/mode"
+	if subjectName != "" {
+		url += "/" + escapeSubject(subjectName)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("GetMode - Error creating request",
+			"error", err)
+
+		return types.SubjectMode{}, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("GetMode - Error making request",
+			"error", err)
+
+		return types.SubjectMode{}, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("GetMode - Error reading response",
+			"error", err)
+
+		return types.SubjectMode{}, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := registryErrors.FromResponse(resp.StatusCode, body)
+
+		// Older registries answer subject not found for subjects without a mode
+		if subjectName != "" && (errors.Is(err, registryErrors.ErrSubjectModeNotSet) || errors.Is(err, registryErrors.ErrSubjectNotFound)) {
+			r.logger.Debug("GetMode - Subject mode not set",
+				"subject", subjectName)
+
+			return types.SubjectMode{Name: subjectName, TakesGlobalDefault: true}, nil
+		}
+
+		r.logger.Debug("GetMode - Unexpected status code",
+			"status", resp.StatusCode,
+			"error", err)
+
+		return types.SubjectMode{}, err
+	}
+
+	mode := types.SubjectMode{Name: subjectName}
+	if err := json.Unmarshal(body, &mode); err != nil {
+		r.logger.Debug("GetMode - Error parsing JSON",
+			"error", err)
+
+		return types.SubjectMode{}, fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	return mode, nil
+}
+
+// ReturnSubjectModes fetches the mode of every subject concurrently, in the order of
+// subjectNames. A subject whose mode cannot be fetched is returned with its error.
+func (r *RegistryAPI) ReturnSubjectModes(ctx context.Context, subjectNames []string) ([]types.SubjectMode, error) {
+	modes, err := fetchEach(ctx, r, subjectNames, r.GetMode, func(subjectName string, err error) types.SubjectMode {
+		return types.SubjectMode{Name: subjectName, Error: err.Error()}
+	})
+	if err != nil {
+		r.logger.Debug("ReturnSubjectModes - Stopped before every mode was fetched",
+			"error", err)
+
+		return nil, err
+	}
+
+	return modes, nil
+}
+
+// UpdateMode sets the mode of a subject, or the global mode when subjectName is
+// empty. Switching a subject or registry holding schemas to IMPORT needs force.
+func (r *RegistryAPI) UpdateMode(ctx context.Context, subjectName string, mode string, force bool) error {
+	path := "/mode"
+	if subjectName != "" {
+		path += "/" + escapeSubject(subjectName)
+	}
+	if force {
+		path += "?force=true"
+	}
+
+	payload, err := json.Marshal(map[string]string{"mode": mode})
+	if helpers.CheckErr(err) {
+		r.logger.Debug("UpdateMode - Error marshalling payload",
+			"error", err)
+
+		return fmt.Errorf("error marshalling payload: %v", err)
+	}
+
+	if err := r.sendWrite(ctx, "PUT", path, payload, nil); helpers.CheckErr(err) {
+		r.logger.Debug("UpdateMode - Error updating mode",
+			"error", err)
+
+		return err
+	}
+
+	r.logger.Info("UpdateMode - Mode updated",
+		"subject", subjectName,
+		"mode", mode,
+		"force", force)
+
+	return nil
+}
+
+// DeleteMode removes the mode of a subject, so that it takes the global mode again
+func (r *RegistryAPI) DeleteMode(ctx context.Context, subjectName string) error {
+	if err := r.sendWrite(ctx, "DELETE", "/mode/"+escapeSubject(subjectName), nil, nil); helpers.CheckErr(err) {
+		r.logger.Debug("DeleteMode - Error deleting mode",
+			"error", err)
+
+		return err
+	}
+
+	r.logger.Info("DeleteMode - Subject reset to the global mode",
+		"subject", subjectName)
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"kafka-board/types"
+)
+
+func TestGetMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mode":
+			fmt.Fprint(w, `{"mode": "READWRITE"}`)
+		case "/mode/orders":
+			fmt.Fprint(w, `{"mode": "READONLY"}`)
+		case "/mode/customers":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code": 40409, "message": "Subject 'customers' does not have subject-level mode configured"}`)
+		case "/mode/legacy":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code": 40401, "message": "Subject 'legacy' not found."}`)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error_code": 50001, "message": "Error in the backend"}`)
+		}
+	}))
+	defer server.Close()
+
+	registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+
+	tests := []struct {
+		name         string
+		subject      string
+		expectedMode types.SubjectMode
+		expectedErr  bool
+	}{
+		{
+			name:         "global mode",
+			expectedMode: types.SubjectMode{Mode: types.ModeReadWrite},
+		},
+		{
+			name:         "subject with its own mode",
+			subject:      "orders",
+			expectedMode: types.SubjectMode{Name: "orders", Mode: types.ModeReadOnly},
+		},
+		{
+			name:         "subject taking the global mode",
+			subject:      "customers",
+			expectedMode: types.SubjectMode{Name: "customers", TakesGlobalDefault: true},
+		},
+		{
+			name:         "subject taking the global mode on an older registry",
+			subject:      "legacy",
+			expectedMode: types.SubjectMode{Name: "legacy", TakesGlobalDefault: true},
+		},
+		{
+			name:        "registry failure",
+			subject:     "broken",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, err := registryAPI.GetMode(context.Background(), tt.subject)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("GetMode() error = %v, want error %t", err, tt.expectedErr)
+			}
+			if mode != tt.expectedMode {
+				t.Errorf("GetMode() = %+v, want %+v", mode, tt.expectedMode)
+			}
+		})
+	}
+
+	t.Run("modes of several subjects", func(t *testing.T) {
+		modes, err := registryAPI.ReturnSubjectModes(context.Background(), []string{"orders", "customers", "broken"})
+		if err != nil {
+			t.Fatalf("ReturnSubjectModes() unexpected error: %v", err)
+		}
+		if modes[0].Mode != types.ModeReadOnly || !modes[1].TakesGlobalDefault || modes[2].Error == "" {
+			t.Errorf("ReturnSubjectModes() = %+v", modes)
+		}
+	})
+}
+
+func TestUpdateMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		subject      string
+		mode         string
+		force        bool
+		reset        bool
+		expectedCall string
+	}{
+		{
+			name:         "subject mode is set",
+			subject:      "orders",
+			mode:         types.ModeReadOnly,
+			expectedCall: `PUT /mode/orders? {"mode":"READONLY"}`,
+		},
+		{
+			name:         "global mode is forced to import",
+			mode:         types.ModeImport,
+			force:        true,
+			expectedCall: `PUT /mode?force=true {"mode":"IMPORT"}`,
+		},
+		{
+			name:         "subject is reset to the global mode",
+			subject:      "orders",
+			reset:        true,
+			expectedCall: `DELETE /mode/orders? `,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var call string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				call = fmt.Sprintf("%s %s?%s %s", r.Method, r.URL.Path, r.URL.RawQuery, body)
+				fmt.Fprint(w, `{"mode": "READONLY"}`)
+			}))
+			defer server.Close()
+
+			registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+
+			var err error
+			if tt.reset {
+				err = registryAPI.DeleteMode(context.Background(), tt.subject)
+			} else {
+				err = registryAPI.UpdateMode(context.Background(), tt.subject, tt.mode, tt.force)
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if call != tt.expectedCall {
+				t.Errorf("request = %q, want %q", call, tt.expectedCall)
+			}
+		})
+	}
+}
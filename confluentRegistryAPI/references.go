@@ -0,0 +1,83 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"fmt"
+	"kafka-board/types"
+	"strings"
+)
+
+// ResolveReferences fetches every schema reachable through the references of schema.
+// The result is ordered so that a schema always comes after the schemas it references,
+// which is the order Avro needs to parse named types. Cycles are reported as errors.
+func (r *RegistryAPI) ResolveReferences(ctx context.Context, schema types.Schema) ([]types.ResolvedReference, error) {
+	resolver := referenceResolver{
+		registryAPI: r,
+		fetched:     make(map[string]types.Schema),
+		inProgress:  make(map[string]bool),
+		named:       make(map[string]bool),
+	}
+
+	if err := resolver.resolve(ctx, schema.References, nil); err != nil {
+		r.logger.Debug("ResolveReferences - Error resolving references",
+			"error", err)
+
+		return nil, err
+	}
+
+	r.logger.Debug("ResolveReferences - References resolved",
+		"count", len(resolver.ordered))
+
+	return resolver.ordered, nil
+}
+
+// referenceResolver walks the reference graph depth first. Schemas are fetched once
+// per subject and version, and listed once per reference name: the same version can
+// be imported under several names, and parsers look references up by name.
+type referenceResolver struct {
+	registryAPI *RegistryAPI
+	fetched     map[string]types.Schema
+	inProgress  map[string]bool
+	named       map[string]bool
+	ordered     []types.ResolvedReference
+}
+
+func (rr *referenceResolver) resolve(ctx context.Context, references []types.SchemaReference, path []string) error {
+	for _, reference := range references {
+		key := fmt.Sprintf("%s:%d", reference.Subject, reference.Version)
+
+		if rr.inProgress[key] {
+			return fmt.Errorf("schema reference cycle detected: %s", strings.Join(append(path, key), " -> "))
+		}
+
+		schema, ok := rr.fetched[key]
+		if !ok {
+			rr.inProgress[key] = true
+
+			var err error
+			schema, err = rr.registryAPI.GetSubjectVersion(ctx, reference.Subject, reference.Version, false)
+			if err != nil {
+				return fmt.Errorf("error fetching reference %s (subject %s, version %d): %w",
+					reference.Name, reference.Subject, reference.Version, err)
+			}
+
+			if err := rr.resolve(ctx, schema.References, append(path, key)); err != nil {
+				return err
+			}
+
+			delete(rr.inProgress, key)
+			rr.fetched[key] = schema
+		}
+
+		if rr.named[reference.Name] {
+			continue
+		}
+		rr.named[reference.Name] = true
+		rr.ordered = append(rr.ordered, types.ResolvedReference{
+			Name:   reference.Name,
+			Schema: schema,
+		})
+	}
+
+	return nil
+}
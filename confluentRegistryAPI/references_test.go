@@ -0,0 +1,97 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"encoding/json"
+	"kafka-board/types"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveReferences(t *testing.T) {
+	// Subject versions served by the fake registry
+	versions := map[string]types.Schema{
+		"/subjects/address/versions/1":  {Subject: "address", Version: 1, Schema: `{"type":"object"}`},
+		"/subjects/customer/versions/2": {Subject: "customer", Version: 2, Schema: `{"type":"object"}`, References: []types.SchemaReference{{Name: "address.json", Subject: "address", Version: 1}}},
+		"/subjects/loop-a/versions/1":   {Subject: "loop-a", Version: 1, References: []types.SchemaReference{{Name: "b.json", Subject: "loop-b", Version: 1}}},
+		"/subjects/loop-b/versions/1":   {Subject: "loop-b", Version: 1, References: []types.SchemaReference{{Name: "a.json", Subject: "loop-a", Version: 1}}},
+	}
+
+	fetches := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches[r.URL.Path]++
+		schema, ok := versions[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(schema)
+	}))
+	defer server.Close()
+
+	registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+
+	t.Run("references are ordered after their dependencies", func(t *testing.T) {
+		schema := types.Schema{References: []types.SchemaReference{
+			{Name: "customer.json", Subject: "customer", Version: 2},
+			{Name: "address.json", Subject: "address", Version: 1},
+		}}
+
+		resolved, err := registryAPI.ResolveReferences(context.Background(), schema)
+		if err != nil {
+			t.Fatalf("ResolveReferences() unexpected error: %v", err)
+		}
+
+		var names []string
+		for _, reference := range resolved {
+			names = append(names, reference.Name)
+		}
+		if strings.Join(names, ",") != "address.json,customer.json" {
+			t.Errorf("ResolveReferences() order = %v, want [address.json customer.json]", names)
+		}
+	})
+
+	t.Run("a version referenced under several names is listed under each", func(t *testing.T) {
+		clear(fetches)
+		schema := types.Schema{References: []types.SchemaReference{
+			{Name: "customer.json", Subject: "customer", Version: 2},
+			{Name: "common/address.json", Subject: "address", Version: 1},
+		}}
+
+		resolved, err := registryAPI.ResolveReferences(context.Background(), schema)
+		if err != nil {
+			t.Fatalf("ResolveReferences() unexpected error: %v", err)
+		}
+
+		var names []string
+		for _, reference := range resolved {
+			names = append(names, reference.Name)
+		}
+		if strings.Join(names, ",") != "address.json,customer.json,common/address.json" {
+			t.Errorf("ResolveReferences() names = %v, want [address.json customer.json common/address.json]", names)
+		}
+		if fetches["/subjects/address/versions/1"] != 1 {
+			t.Errorf("address version fetched %d times, want once", fetches["/subjects/address/versions/1"])
+		}
+	})
+
+	t.Run("cycles are reported", func(t *testing.T) {
+		schema := types.Schema{References: []types.SchemaReference{{Name: "a.json", Subject: "loop-a", Version: 1}}}
+
+		_, err := registryAPI.ResolveReferences(context.Background(), schema)
+		if err == nil || !strings.Contains(err.Error(), "cycle detected: loop-a:1 -> loop-b:1 -> loop-a:1") {
+			t.Errorf("ResolveReferences() error = %v, want a cycle error", err)
+		}
+	})
+
+	t.Run("missing references are reported", func(t *testing.T) {
+		schema := types.Schema{References: []types.SchemaReference{{Name: "gone.json", Subject: "gone", Version: 1}}}
+
+		if _, err := registryAPI.ResolveReferences(context.Background(), schema); err == nil {
+			t.Errorf("ResolveReferences() expected an error for a missing reference")
+		}
+	})
+}
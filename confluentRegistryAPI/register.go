@@ -0,0 +1,211 @@
+package confluentRegistryAPI
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"kafka-board/helpers"
+	"kafka-board/registryErrors"
+	"kafka-board/types"
+)
+
+var trueVal = true
+
+// RegisterSchema registers a proposed schema as a new version of the subject. The
+// schema is first checked, as a dry run, against the versions the compatibility
+// level of the subject compares it with, every version for a transitive level, and
+// is only registered when compatible. A subject without versions has nothing to
+// check against. With normalize set, the registry normalizes the schema before
+// comparing it to the versions already registered, in the dry run too.
+//
+// A schema failing the dry run is returned with its incompatibilities and an error
+// matching registryErrors.ErrIncompatibleSchema.
+func (r *RegistryAPI) RegisterSchema(ctx context.Context, subjectName string, proposed types.Schema, normalize bool) (types.Response, error) {
+	payload, resp, err := r.compatibilityPayload(ctx, proposed)
+	if helpers.CheckErr(err) {
+		return resp, err
+	}
+
+	dryRun, err := r.dryRunCompatibility(ctx, subjectName, payload, normalize)
+	switch {
+	case errors.Is(err, registryErrors.ErrSubjectNotFound), errors.Is(err, registryErrors.ErrVersionNotFound):
+		r.logger.Debug("RegisterSchema - No version to check against",
+			"subject", subjectName)
+
+	case helpers.CheckErr(err):
+		r.logger.Debug("RegisterSchema - Error in compatibility dry run",
+			"error", err)
+
+		return dryRun, err
+
+	case dryRun.IsCompatible == nil || !*dryRun.IsCompatible:
+		r.logger.Debug("RegisterSchema - Schema failed the compatibility dry run",
+			"subject", subjectName,
+			"message", dryRun.Message)
+
+		dryRun.Message = fmt.Sprintf("Not registered, the compatibility dry run failed: %s", dryRun.Message)
+		dryRun.StatusCode = http.StatusConflict
+		dryRun.ErrorCode = registryErrors.ErrIncompatibleSchema.Code
+
+		return dryRun, &registryErrors.Error{
+			Code:       registryErrors.ErrIncompatibleSchema.Code,
+			StatusCode: http.StatusConflict,
+			Message:    dryRun.Message,
+		}
+	}
+
+	var registered struct {
+		Id int `json:"id"`
+	}
+	if err := r.postSchema(ctx, "/subjects/"+escapeSubject(subjectName)+"/versions", payload, normalize, &registered); helpers.CheckErr(err) {
+		r.logger.Debug("RegisterSchema - Error registering schema",
+			"error", err)
+
+		return registrationErrorResponse("Error registering schema", err), err
+	}
+
+	// The registry only answers with the ID, the version is looked up
+	var schema types.Schema
+	if err := r.postSchema(ctx, "/subjects/"+escapeSubject(subjectName), payload, normalize, &schema); helpers.CheckErr(err) {
+		r.logger.Debug("RegisterSchema - Error looking up registered version",
+			"error", err)
+
+		return registrationErrorResponse("Schema registered, error looking up its version", err), err
+	}
+
+	r.logger.Info("RegisterSchema - Schema registered",
+		"subject", subjectName,
+		"id", registered.Id,
+		"version", schema.Version)
+
+	resp = helpers.CreateResponseObject(&trueVal, fmt.Sprintf("Registered as version %d with ID %d", schema.Version, registered.Id), http.StatusOK, 0)
+	resp.Registered = &types.RegisteredSchema{
+		Subject: subjectName,
+		Id:      registered.Id,
+		Version: schema.Version,
+	}
+
+	return resp, nil
+}
+
+// ImportSchema registers a schema under the ID and version it carries, as when
+// restoring a backup. The registry or subject must be in IMPORT mode. Unlike
+// RegisterSchema there is no compatibility dry run, the schema was already accepted
+// by the registry it comes from.
+func (r *RegistryAPI) ImportSchema(ctx context.Context, subjectName string, schema types.Schema) error {
+	payload, err := json.Marshal(struct {
+		Schema     string                  `json:"schema"`
+		SchemaType string                  `json:"schemaType,omitempty"`
+		References []types.SchemaReference `json:"references,omitempty"`
+		Id         int                     `json:"id"`
+		Version    int                     `json:"version"`
+	}{
+		Schema:     schema.Schema,
+		SchemaType: schema.SchemaType,
+		References: schema.References,
+		Id:         schema.Id,
+		Version:    schema.Version,
+	})
+	if helpers.CheckErr(err) {
+		r.logger.Debug("ImportSchema - Error marshalling payload",
+			"error", err)
+
+		return fmt.Errorf("error marshalling payload: %v", err)
+	}
+
+	var registered struct {
+		Id int `json:"id"`
+	}
+	if err := r.sendWrite(ctx, "POST", "/subjects/"+escapeSubject(subjectName)+"/versions", payload, &registered); helpers.CheckErr(err) {
+		r.logger.Debug("ImportSchema - Error importing schema",
+			"error", err)
+
+		return err
+	}
+
+	if registered.Id != schema.Id {
+		return fmt.Errorf("version %d of %s was imported with ID %d instead of %d", schema.Version, subjectName, registered.Id, schema.Id)
+	}
+
+	r.logger.Debug("ImportSchema - Schema imported",
+		"subject", subjectName,
+		"id", schema.Id,
+		"version", schema.Version)
+
+	return nil
+}
+
+// postSchema posts a payload built by TransformToSchemaFormat to a subject
+// endpoint of the registry and decodes the answer into result
+func (r *RegistryAPI) postSchema(ctx context.Context, path string, payload string, normalize bool, result any) error {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+
+	url := r.baseRegistryURL + path
+	if normalize {
+		url += "?normalize=true"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBufferString(payload))
+	if helpers.CheckErr(err) {
+		r.logger.Debug("postSchema - Error creating request",
+			"error", err)
+
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("postSchema - Error making request",
+			"error", err)
+
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("postSchema - Error reading response",
+			"error", err)
+
+		return fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := registryErrors.FromResponse(resp.StatusCode, body)
+
+		r.logger.Debug("postSchema - Unexpected status code",
+			"status", resp.StatusCode,
+			"error", err)
+
+		return err
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		r.logger.Debug("postSchema - Error parsing JSON",
+			"error", err)
+
+		return fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	return nil
+}
+
+// registrationErrorResponse reports a failed registration call, with the code of
+// the registry when it returned one
+func registrationErrorResponse(message string, err error) types.Response {
+	var registryErr *registryErrors.Error
+	if errors.As(err, &registryErr) {
+		return helpers.CreateResponseObject(nil, fmt.Sprintf("%s: %s", message, registryErr.FriendlyMessage()), registryErr.HTTPStatus(), registryErr.Code)
+	}
+
+	return helpers.CreateResponseObject(nil, fmt.Sprintf("%s: %v", message, err), http.StatusInternalServerError, 0)
+}
@@ -0,0 +1,164 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"kafka-board/registryErrors"
+	"kafka-board/types"
+)
+
+func TestRegisterSchema(t *testing.T) {
+	proposed := types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type":"object","properties":{"id":{"type":"integer"}}}`}
+
+	tests := []struct {
+		name               string
+		compatibility      string
+		compatibilityCode  int
+		normalize          bool
+		expectedRegistered bool
+		expectedErr        error
+		expectedVersion    int
+	}{
+		{
+			name:               "compatible schema is registered",
+			compatibility:      `{"is_compatible": true}`,
+			compatibilityCode:  http.StatusOK,
+			normalize:          true,
+			expectedRegistered: true,
+			expectedVersion:    4,
+		},
+		{
+			name:               "first version of a new subject is registered",
+			compatibility:      `{"error_code": 40401, "message": "Subject 'orders' not found."}`,
+			compatibilityCode:  http.StatusNotFound,
+			expectedRegistered: true,
+			expectedVersion:    4,
+		},
+		{
+			name:              "incompatible schema is not registered",
+			compatibility:     `{"is_compatible": false, "messages": ["{errorType:'TYPE_NARROWED', description:'The type at path '#/properties/id' was narrowed'}"]}`,
+			compatibilityCode: http.StatusOK,
+			expectedErr:       registryErrors.ErrIncompatibleSchema,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registered := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("unexpected %s request to %s", r.Method, r.URL.Path)
+				}
+				if (r.URL.Query().Get("normalize") == "true") != tt.normalize {
+					t.Errorf("request to %s with normalize=%q, want %t", r.URL.Path, r.URL.Query().Get("normalize"), tt.normalize)
+				}
+
+				switch r.URL.Path {
+				case "/compatibility/subjects/orders/versions":
+					if r.URL.Query().Get("verbose") != "true" {
+						t.Errorf("dry run without verbose=true: %s", r.URL.RawQuery)
+					}
+					w.WriteHeader(tt.compatibilityCode)
+					fmt.Fprint(w, tt.compatibility)
+				case "/subjects/orders/versions":
+					registered = true
+					fmt.Fprint(w, `{"id": 12}`)
+				case "/subjects/orders":
+					fmt.Fprint(w, `{"subject": "orders", "id": 12, "version": 4, "schema": "{}"}`)
+				default:
+					t.Errorf("unexpected request to %s", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+			resp, err := registryAPI.RegisterSchema(context.Background(), "orders", proposed, tt.normalize)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("RegisterSchema() error = %v, want %v", err, tt.expectedErr)
+				}
+				if len(resp.Incompatibilities) == 0 {
+					t.Errorf("RegisterSchema() returned no incompatibilities for a failed dry run")
+				}
+			} else if err != nil {
+				t.Fatalf("RegisterSchema() unexpected error: %v", err)
+			}
+
+			if registered != tt.expectedRegistered {
+				t.Errorf("schema registered = %t, want %t", registered, tt.expectedRegistered)
+			}
+			if tt.expectedRegistered && (resp.Registered == nil || resp.Registered.Version != tt.expectedVersion || resp.Registered.Id != 12) {
+				t.Errorf("Registered = %+v, want version %d with ID 12", resp.Registered, tt.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestImportSchema(t *testing.T) {
+	schema := types.Schema{Version: 3, Id: 42, Schema: `{"type": "string"}`, References: []types.SchemaReference{{Name: "com.shop.Id", Subject: "ids", Version: 1}}}
+
+	tests := []struct {
+		name        string
+		response    string
+		status      int
+		expectedErr error
+	}{
+		{
+			name:     "schema keeps its ID and version",
+			response: `{"id": 42}`,
+			status:   http.StatusOK,
+		},
+		{
+			name:        "registry not in IMPORT mode",
+			response:    `{"error_code": 42205, "message": "Subject orders is not in import mode"}`,
+			status:      http.StatusUnprocessableEntity,
+			expectedErr: registryErrors.ErrOperationNotPermitted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/subjects/orders/versions" {
+					t.Errorf("unexpected %s request to %s", r.Method, r.URL.Path)
+				}
+
+				var payload struct {
+					SchemaType string                  `json:"schemaType"`
+					References []types.SchemaReference `json:"references"`
+					Id         int                     `json:"id"`
+					Version    int                     `json:"version"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Fatalf("invalid payload: %v", err)
+				}
+				if payload.Id != 42 || payload.Version != 3 || payload.SchemaType != "" || len(payload.References) != 1 {
+					t.Errorf("payload = %+v, want ID 42, version 3, no schema type and one reference", payload)
+				}
+
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.response)
+			}))
+			defer server.Close()
+
+			registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+			err := registryAPI.ImportSchema(context.Background(), "orders", schema)
+
+			if tt.expectedErr == nil && err != nil {
+				t.Fatalf("ImportSchema() unexpected error: %v", err)
+			}
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Errorf("ImportSchema() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"kafka-board/helpers"
+	"kafka-board/types"
+)
+
+// Defaults of the retry and circuit breaker settings
+const (
+	defaultMaxRetries       = 3
+	defaultRetryBaseDelay   = 100 * time.Millisecond
+	defaultRetryMaxDelay    = 2 * time.Second
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// Circuit breaker states, as reported on the health endpoint
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+)
+
+// circuitBreaker stops calling a registry after consecutive failed calls. Once the
+// cooldown has passed, a single probe call is let through: its success closes the
+// breaker again, its failure keeps it open for another cooldown.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	state     string
+	failures  int
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     breakerClosed,
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a call may go to the registry, and whether that call is
+// the probe deciding if a half-open breaker closes
+func (b *circuitBreaker) allow() (allowed bool, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Before(b.openUntil) {
+			return false, false
+		}
+		b.state = breakerHalfOpen
+		fallthrough
+	case breakerHalfOpen:
+		if b.probing {
+			return false, false
+		}
+		b.probing = true
+		return true, true
+	}
+
+	return true, false
+}
+
+// record counts the outcome of a call let through by allow
+func (b *circuitBreaker) record(failed bool, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	if !failed {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if probe || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// release gives up the probe of a call whose outcome says nothing about the
+// registry, e.g. because the caller went away
+func (b *circuitBreaker) release(probe bool) {
+	if !probe {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) status() types.BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := types.BreakerStatus{State: b.state, ConsecutiveFailures: b.failures}
+	if b.state == breakerOpen {
+		openUntil := b.openUntil
+		status.OpenUntil = &openUntil
+	}
+	return status
+}
+
+// resilientTransport retries idempotent registry calls with exponential backoff and
+// full jitter, and fails fast with helpers.ErrRegistryUnavailable while the
+// circuit breaker is open
+type resilientTransport struct {
+	base       http.RoundTripper
+	breaker    *circuitBreaker
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	logger     *slog.Logger
+}
+
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	allowed, probe := t.breaker.allow()
+	if !allowed {
+		t.logger.Debug("resilientTransport - Circuit breaker open, failing fast",
+			"url", req.URL.String())
+
+		return nil, helpers.ErrRegistryUnavailable
+	}
+
+	// Only GETs are retried, and a half-open breaker is probed with a single attempt
+	attempts := 1
+	if req.Method == http.MethodGet && !probe {
+		attempts += t.maxRetries
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		failed := isRetryable(resp, err)
+
+		// A caller going away says nothing about the registry, a deadline running out does
+		if ctxErr := req.Context().Err(); failed && ctxErr != nil {
+			if errors.Is(ctxErr, context.DeadlineExceeded) {
+				t.breaker.record(true, probe)
+			} else {
+				t.breaker.release(probe)
+			}
+			return resp, err
+		}
+
+		if !failed || attempt >= attempts {
+			t.breaker.record(failed, probe)
+			return resp, err
+		}
+
+		t.logger.Debug("resilientTransport - Registry call failed, retrying",
+			"url", req.URL.String(),
+			"attempt", attempt,
+			"error", retryReason(resp, err))
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(t.backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			if errors.Is(req.Context().Err(), context.DeadlineExceeded) {
+				t.breaker.record(true, probe)
+			} else {
+				t.breaker.release(probe)
+			}
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// backoff returns a random delay up to baseDelay doubled for every attempt made,
+// capped at maxDelay
+func (t *resilientTransport) backoff(attempt int) time.Duration {
+	delay := t.maxDelay
+	if shift := attempt - 1; shift < 32 && t.baseDelay<<shift < t.maxDelay {
+		delay = t.baseDelay << shift
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return rand.N(delay + 1)
+}
+
+// isRetryable reports whether a call failed because the registry is unreachable
+// or overloaded, rather than because of the request itself
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("status code %d", resp.StatusCode)
+}
@@ -0,0 +1,106 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kafka-board/helpers"
+)
+
+func TestResilientTransport(t *testing.T) {
+	var hits, failuresLeft atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if failuresLeft.Add(-1) >= 0 {
+			http.Error(w, "restarting", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `["orders"]`)
+	}))
+	defer server.Close()
+
+	newRegistryAPI := func(t *testing.T, failures int32) *RegistryAPI {
+		hits.Store(0)
+		failuresLeft.Store(failures)
+
+		retries := 2
+		registryAPI, err := ReturnRegistryAPIForConfig(slog.Default(), RegistryConfig{
+			Name:             "test",
+			BaseURL:          server.URL,
+			MaxRetries:       &retries,
+			BreakerThreshold: 2,
+		})
+		if err != nil {
+			t.Fatalf("ReturnRegistryAPIForConfig() unexpected error: %v", err)
+		}
+		registryAPI.client.Transport.(*resilientTransport).baseDelay = time.Millisecond
+		return registryAPI
+	}
+
+	t.Run("reads are retried until the registry answers", func(t *testing.T) {
+		registryAPI := newRegistryAPI(t, 2)
+
+		if _, err := registryAPI.ReturnSubjects(context.Background()); err != nil {
+			t.Fatalf("ReturnSubjects() unexpected error: %v", err)
+		}
+		if got := hits.Load(); got != 3 {
+			t.Errorf("registry called %d times, want 3", got)
+		}
+		if state := registryAPI.BreakerStatus().State; state != breakerClosed {
+			t.Errorf("breaker %s, want %s", state, breakerClosed)
+		}
+	})
+
+	t.Run("writes are not retried", func(t *testing.T) {
+		registryAPI := newRegistryAPI(t, 1)
+
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/subjects", nil)
+		resp, err := registryAPI.client.Do(req)
+		if err != nil {
+			t.Fatalf("Do() unexpected error: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusServiceUnavailable || hits.Load() != 1 {
+			t.Errorf("got status %d after %d calls, want 503 after 1", resp.StatusCode, hits.Load())
+		}
+	})
+
+	t.Run("the breaker opens after consecutive failures and closes once the registry is back", func(t *testing.T) {
+		registryAPI := newRegistryAPI(t, 6)
+		now := time.Now()
+		registryAPI.breaker.now = func() time.Time { return now }
+
+		for i := 0; i < 2; i++ {
+			if _, err := registryAPI.ReturnSubjects(context.Background()); err == nil {
+				t.Fatalf("ReturnSubjects() call %d succeeded, want an error", i)
+			}
+		}
+
+		_, err := registryAPI.ReturnSubjects(context.Background())
+		if !errors.Is(err, helpers.ErrRegistryUnavailable) {
+			t.Errorf("ReturnSubjects() error = %v, want ErrRegistryUnavailable", err)
+		}
+		if got := hits.Load(); got != 6 {
+			t.Errorf("registry called %d times, want 6", got)
+		}
+		if status := registryAPI.BreakerStatus(); status.State != breakerOpen || status.OpenUntil == nil {
+			t.Errorf("breaker = %+v, want open with a reopening time", status)
+		}
+
+		now = now.Add(defaultBreakerCooldown)
+		if _, err := registryAPI.ReturnSubjects(context.Background()); err != nil {
+			t.Fatalf("ReturnSubjects() after cooldown unexpected error: %v", err)
+		}
+		if state := registryAPI.BreakerStatus().State; state != breakerClosed {
+			t.Errorf("breaker %s, want %s", state, breakerClosed)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"kafka-board/helpers"
+	"kafka-board/registryErrors"
+	"kafka-board/types"
+)
+
+// ReturnContexts lists the schema contexts of the registry, the default context
+// included. Registries without context support only have the default context.
+func (r *RegistryAPI) ReturnContexts(ctx context.Context) ([]string, error) {
+	ctx, cancel := r.withDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", r.baseRegistryURL+"/contexts", nil)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("ReturnContexts - Error creating request",
+			"error", err)
+
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+
+	resp, err := helpers.MakeHTTPRequestWithContext(ctx, r.client, req)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("ReturnContexts - Error making request",
+			"error", err)
+
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if helpers.CheckErr(err) {
+		r.logger.Debug("ReturnContexts - Error reading response",
+			"error", err)
+
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		r.logger.Debug("ReturnContexts - Registry without context support")
+
+		return []string{types.DefaultContext}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err := registryErrors.FromResponse(resp.StatusCode, body)
+
+		r.logger.Debug("ReturnContexts - Unexpected status code",
+			"status", resp.StatusCode,
+			"error", err)
+
+		return nil, err
+	}
+
+	var contexts []string
+	if err := json.Unmarshal(body, &contexts); err != nil {
+		r.logger.Debug("ReturnContexts - Error parsing JSON",
+			"error", err)
+
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+
+	return contexts, nil
+}
+
+// escapeSubject escapes a subject name for a registry URL path. Context-qualified
+// names like ":.team-a:orders" keep their colons, while slashes, spaces and other
+// reserved characters in names are escaped so they stay in one path segment.
+func escapeSubject(subjectName string) string {
+	return url.PathEscape(subjectName)
+}
@@ -0,0 +1,115 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"kafka-board/types"
+)
+
+func TestReturnContexts(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		body             string
+		expectedContexts []string
+		expectedErr      bool
+	}{
+		{
+			name:             "registry with contexts",
+			status:           http.StatusOK,
+			body:             `[".", ".team-a", ".team-b"]`,
+			expectedContexts: []string{".", ".team-a", ".team-b"},
+		},
+		{
+			name:             "registry without context support",
+			status:           http.StatusNotFound,
+			body:             `{"error_code": 404, "message": "HTTP 404 Not Found"}`,
+			expectedContexts: []string{types.DefaultContext},
+		},
+		{
+			name:        "registry failure",
+			status:      http.StatusInternalServerError,
+			body:        `{"error_code": 50001, "message": "Error in the backend"}`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+			contexts, err := registryAPI.ReturnContexts(context.Background())
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("ReturnContexts() error = %v, want error %t", err, tt.expectedErr)
+			}
+			if !reflect.DeepEqual(contexts, tt.expectedContexts) {
+				t.Errorf("ReturnContexts() = %v, want %v", contexts, tt.expectedContexts)
+			}
+		})
+	}
+}
+
+func TestContextQualifiedSubjectURLs(t *testing.T) {
+	tests := []struct {
+		name         string
+		subject      string
+		call         func(*RegistryAPI, string) error
+		expectedPath string
+	}{
+		{
+			name:    "subject config in a context",
+			subject: ":.team-a:orders",
+			call: func(r *RegistryAPI, subject string) error {
+				_, err := r.getSubjectConfig(context.Background(), subject)
+				return err
+			},
+			expectedPath: "/config/:.team-a:orders",
+		},
+		{
+			name:    "subject versions in a context",
+			subject: ":.team-a:orders",
+			call: func(r *RegistryAPI, subject string) error {
+				_, err := r.GetSubjectVersions(context.Background(), subject, false)
+				return err
+			},
+			expectedPath: "/subjects/:.team-a:orders/versions",
+		},
+		{
+			name:    "reserved characters stay in one path segment",
+			subject: "team/orders?v=1",
+			call: func(r *RegistryAPI, subject string) error {
+				return r.UpdateMode(context.Background(), subject, types.ModeReadOnly, false)
+			},
+			expectedPath: "/mode/team%2Forders%3Fv=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.EscapedPath()
+				fmt.Fprint(w, `{}`)
+			}))
+			defer server.Close()
+
+			registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+			tt.call(registryAPI, tt.subject)
+
+			if path != tt.expectedPath {
+				t.Errorf("request path = %s, want %s", path, tt.expectedPath)
+			}
+		})
+	}
+}
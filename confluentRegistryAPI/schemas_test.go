@@ -0,0 +1,101 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetSchemas(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deleted := r.URL.Query().Get("deleted") == "true"
+
+		switch r.URL.Path {
+		case "/subjects/orders/versions":
+			if deleted {
+				fmt.Fprint(w, `[3, 1, 2]`)
+				return
+			}
+			fmt.Fprint(w, `[1, 3]`)
+		case "/subjects/retired/versions":
+			// Subjects whose versions are all soft-deleted are not found without deleted=true
+			if deleted {
+				fmt.Fprint(w, `[1]`)
+				return
+			}
+			http.Error(w, `{"error_code":40401,"message":"Subject 'retired' not found."}`, http.StatusNotFound)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client()}
+
+	tests := []struct {
+		name            string
+		subject         string
+		includeDeleted  bool
+		expected        string
+		expectedFailure bool
+	}{
+		{
+			name:     "live versions only",
+			subject:  "orders",
+			expected: "1 3",
+		},
+		{
+			name:           "soft-deleted versions are flagged",
+			subject:        "orders",
+			includeDeleted: true,
+			expected:       "1 2(deleted) 3",
+		},
+		{
+			name:            "unknown subject",
+			subject:         "retired",
+			expectedFailure: true,
+		},
+		{
+			name:           "subject with only soft-deleted versions",
+			subject:        "retired",
+			includeDeleted: true,
+			expected:       "1(deleted)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schemas, err := registryAPI.GetSchemas(context.Background(), test.subject, test.includeDeleted)
+			if test.expectedFailure {
+				if err == nil {
+					t.Errorf("GetSchemas() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetSchemas() unexpected error: %v", err)
+			}
+
+			got := ""
+			for i, schema := range schemas {
+				if i > 0 {
+					got += " "
+				}
+				got += fmt.Sprintf("%d", schema.Version)
+				if schema.Deleted {
+					got += "(deleted)"
+				}
+				if schema.Schema != "" {
+					t.Errorf("GetSchemas() should not load schema bodies, got %q", schema.Schema)
+				}
+			}
+			if got != test.expected {
+				t.Errorf("GetSchemas() = %s, want %s", got, test.expected)
+			}
+		})
+	}
+}
@@ -99,7 +99,7 @@ func (r *RegistryAPI) createTestSubject(testSubject testSubject) error {
 
 	// Create helper instance to transform the input string into the schema registry format
 	helper := helpers.ReturnHelpers(r.logger)
-	transformedSchema, err := helper.TransformJSONToSchemaFormat(testSubject.schemaStr)
+	transformedSchema, err := helper.TransformJSONToSchemaFormat(testSubject.schemaStr, types.SchemaTypeJSON, nil, nil)
 
 	if err != nil {
 		r.logger.Debug("CreateTestSubject - Error transforming schema",
@@ -108,8 +108,7 @@ func (r *RegistryAPI) createTestSubject(testSubject testSubject) error {
 		return err
 	}
 
-	client := &http.Client{}
-	requestURL := fmt.Sprintf("%s/subjects/%s/versions", r.baseRegistryURL, testSubject.subjectName)
+	requestURL := fmt.Sprintf("%s/subjects/%s/versions", r.baseRegistryURL, escapeSubject(testSubject.subjectName))
 
 	r.logger.Debug("CreateTestSubject - Using Schema Registry URL",
 		"url", requestURL)
@@ -126,7 +125,7 @@ func (r *RegistryAPI) createTestSubject(testSubject testSubject) error {
 	req.Header.Set("Content-Type", "application/json")
 
 	// Make the request
-	resp, err := client.Do(req)
+	resp, err := r.client.Do(req)
 
 	if err != nil {
 		r.logger.Debug("CreateTestSubject - Error making request",
@@ -159,8 +158,7 @@ func (r *RegistryAPI) createConfig(testSubject testSubject) error {
 		return nil
 	}
 
-	client := &http.Client{}
-	requestURL := fmt.Sprintf("%s/config/%s", r.baseRegistryURL, testSubject.subjectName)
+	requestURL := fmt.Sprintf("%s/config/%s", r.baseRegistryURL, escapeSubject(testSubject.subjectName))
 
 	r.logger.Debug("CreateConfig - Using Schema Registry URL", "url", requestURL)
 
@@ -182,7 +180,7 @@ func (r *RegistryAPI) createConfig(testSubject testSubject) error {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := client.Do(req)
+	resp, err := r.client.Do(req)
 
 	if err != nil {
 
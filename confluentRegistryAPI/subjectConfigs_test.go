@@ -0,0 +1,78 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"kafka-board/types"
+)
+
+func TestReturnSubjectConfigs(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		time.Sleep(5 * time.Millisecond)
+
+		subject := strings.TrimPrefix(r.URL.Path, "/config/")
+		switch {
+		case subject == "broken":
+			http.Error(w, `{"error_code":50001,"message":"Error in the backend"}`, http.StatusInternalServerError)
+		case strings.HasPrefix(subject, "inherits"):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			fmt.Fprint(w, `{"compatibilityLevel":"FULL"}`)
+		}
+	}))
+	defer server.Close()
+
+	registryAPI := &RegistryAPI{logger: slog.Default(), baseRegistryURL: server.URL, client: server.Client(), configConcurrency: 3}
+
+	var subjectNames []string
+	for i := 0; i < 20; i++ {
+		subjectNames = append(subjectNames, fmt.Sprintf("subject-%d", i))
+	}
+	subjectNames = append(subjectNames, "broken", "inherits-global")
+
+	configs, err := registryAPI.ReturnSubjectConfigs(context.Background(), subjectNames)
+	if err != nil {
+		t.Fatalf("ReturnSubjectConfigs() unexpected error: %v", err)
+	}
+
+	for i, config := range configs {
+		if config.GetName() != subjectNames[i] {
+			t.Errorf("config %d = %s, want %s", i, config.GetName(), subjectNames[i])
+		}
+	}
+
+	if config, ok := configs[0].(types.SubjectConfig); !ok || config.CompatibilityLevel != "FULL" {
+		t.Errorf("config of subject-0 = %+v, want a FULL SubjectConfig", configs[0])
+	}
+	if config, ok := configs[20].(types.SubjectConfigError); !ok || !strings.Contains(config.Error, "500") {
+		t.Errorf("config of broken = %+v, want a SubjectConfigError", configs[20])
+	}
+	if _, ok := configs[21].(types.SubjectGlobalConfig); !ok {
+		t.Errorf("config of inherits-global = %+v, want a SubjectGlobalConfig", configs[21])
+	}
+
+	if maxInFlight > 3 {
+		t.Errorf("%d requests in flight, want at most 3", maxInFlight)
+	}
+}
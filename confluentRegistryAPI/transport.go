@@ -0,0 +1,208 @@
+package confluentRegistryAPI
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// newRegistryClient builds the HTTP client shared by every RegistryAPI method.
+// TLS is set up on the transport once, authentication is added to each request.
+func newRegistryClient(config RegistryConfig, logger *slog.Logger) (*http.Client, error) {
+	if config.APIKey != "" && config.BearerTokenFile != "" {
+		return nil, fmt.Errorf("basic auth and bearer token cannot both be configured")
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	auth := &authTransport{
+		base:      transport,
+		apiKey:    config.APIKey,
+		apiSecret: config.APISecret,
+		logger:    logger,
+	}
+	if config.BearerTokenFile != "" {
+		auth.tokens = &fileTokenSource{path: config.BearerTokenFile}
+	}
+
+	logger.Debug("newRegistryClient - Registry client configured",
+		"basicAuth", config.APIKey != "",
+		"bearerToken", config.BearerTokenFile != "",
+		"customCA", config.CAFile != "",
+		"clientCertificate", config.CertFile != "")
+
+	return &http.Client{Transport: auth}, nil
+}
+
+// newTLSConfig loads the CA bundle and client certificate, if any
+func newTLSConfig(config RegistryConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if config.CAFile != "" {
+		caBundle, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA bundle: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		if config.CertFile == "" || config.KeyFile == "" {
+			return nil, fmt.Errorf("both a client certificate and key are required for mTLS")
+		}
+
+		certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// authTransport adds basic auth or a bearer token to every registry request
+type authTransport struct {
+	base      http.RoundTripper
+	apiKey    string
+	apiSecret string
+	tokens    *fileTokenSource
+	logger    *slog.Logger
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	authorized, err := t.authorize(req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(authorized)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || t.tokens == nil {
+		return resp, err
+	}
+
+	// The token may have been rotated before its expiry, re-read it and retry once
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	t.logger.Debug("authTransport - Unauthorized, refreshing bearer token",
+		"url", req.URL.String())
+
+	retry, err := t.authorize(req, true)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return t.base.RoundTrip(retry)
+}
+
+// authorize returns a copy of req with the Authorization header set
+func (t *authTransport) authorize(req *http.Request, refresh bool) (*http.Request, error) {
+	authorized := req.Clone(req.Context())
+
+	switch {
+	case t.tokens != nil:
+		token, err := t.tokens.Token(refresh)
+		if err != nil {
+			return nil, err
+		}
+		authorized.Header.Set("Authorization", "Bearer "+token)
+	case t.apiKey != "":
+		authorized.SetBasicAuth(t.apiKey, t.apiSecret)
+	}
+
+	return authorized, nil
+}
+
+// tokenExpirySkew renews tokens slightly before they expire
+const tokenExpirySkew = 30 * time.Second
+
+// fileTokenSource reads a bearer token from a file. The file is re-read when the
+// token expires, when the file changes, or when a refresh is forced.
+type fileTokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	expiry  time.Time
+	modTime time.Time
+}
+
+func (s *fileTokenSource) Token(refresh bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", fmt.Errorf("error reading bearer token file: %v", err)
+	}
+
+	expired := !s.expiry.IsZero() && time.Now().Add(tokenExpirySkew).After(s.expiry)
+	if s.token != "" && !refresh && !expired && info.ModTime().Equal(s.modTime) {
+		return s.token, nil
+	}
+
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("error reading bearer token file: %v", err)
+	}
+
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("bearer token file %s is empty", s.path)
+	}
+
+	s.token = token
+	s.expiry = jwtExpiry(token)
+	s.modTime = info.ModTime()
+
+	return s.token, nil
+}
+
+// jwtExpiry returns the exp claim of a JWT, or the zero time for opaque tokens
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(claims.Exp, 0)
+}
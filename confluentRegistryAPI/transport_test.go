@@ -0,0 +1,89 @@
+package confluentRegistryAPI
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRegistryClientAuthentication(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") == "Bearer stale" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	t.Run("basic auth", func(t *testing.T) {
+		authorizations = nil
+		registryAPI := newTestRegistryAPI(t, RegistryConfig{BaseURL: server.URL, APIKey: "key", APISecret: "secret"})
+
+		if _, err := registryAPI.ReturnSubjects(context.Background()); err != nil {
+			t.Fatalf("ReturnSubjects() unexpected error: %v", err)
+		}
+
+		expected := "Basic " + base64.StdEncoding.EncodeToString([]byte("key:secret"))
+		if len(authorizations) != 1 || authorizations[0] != expected {
+			t.Errorf("Authorization headers = %v, want [%s]", authorizations, expected)
+		}
+	})
+
+	t.Run("bearer token is re-read after a 401", func(t *testing.T) {
+		authorizations = nil
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		os.WriteFile(tokenFile, []byte("stale\n"), 0o600)
+
+		registryAPI := newTestRegistryAPI(t, RegistryConfig{BaseURL: server.URL, BearerTokenFile: tokenFile})
+
+		// Rotate the token without changing the modification time
+		info, _ := os.Stat(tokenFile)
+		registryAPI.client.Transport.(*authTransport).tokens.Token(false)
+		os.WriteFile(tokenFile, []byte("fresh\n"), 0o600)
+		os.Chtimes(tokenFile, info.ModTime(), info.ModTime())
+
+		if _, err := registryAPI.ReturnSubjects(context.Background()); err != nil {
+			t.Fatalf("ReturnSubjects() unexpected error: %v", err)
+		}
+
+		if fmt.Sprint(authorizations) != "[Bearer stale Bearer fresh]" {
+			t.Errorf("Authorization headers = %v, want [Bearer stale Bearer fresh]", authorizations)
+		}
+	})
+
+	t.Run("basic auth and bearer token are exclusive", func(t *testing.T) {
+		if _, err := newRegistryClient(RegistryConfig{APIKey: "key", BearerTokenFile: "token"}, slog.Default()); err == nil {
+			t.Errorf("newRegistryClient() expected an error")
+		}
+	})
+}
+
+func TestJWTExpiry(t *testing.T) {
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"exp": 1700000000}`))
+
+	if expiry := jwtExpiry("header." + claims + ".signature"); !expiry.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("jwtExpiry() = %v, want %v", expiry, time.Unix(1700000000, 0))
+	}
+
+	if expiry := jwtExpiry("opaque-token"); !expiry.IsZero() {
+		t.Errorf("jwtExpiry() = %v, want zero time for opaque tokens", expiry)
+	}
+}
+
+func newTestRegistryAPI(t *testing.T, config RegistryConfig) *RegistryAPI {
+	client, err := newRegistryClient(config, slog.Default())
+	if err != nil {
+		t.Fatalf("newRegistryClient() unexpected error: %v", err)
+	}
+	return &RegistryAPI{logger: slog.Default(), baseRegistryURL: config.BaseURL, client: client}
+}
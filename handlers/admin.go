@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"kafka-board/helpers"
+)
+
+// cacheFlusher is implemented by registry APIs that cache their reads
+type cacheFlusher interface {
+	FlushCache()
+}
+
+// AdminOnly guards the admin endpoints with the ADMIN_TOKEN bearer token. The
+// endpoints are disabled when no token is set.
+func (h *handler) AdminOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := helpers.GetAdminToken()
+		if token == "" {
+			h.logger.Debug("AdminOnly - Admin request without ADMIN_TOKEN set",
+				"path", r.URL.Path)
+
+			http.Error(w, "Admin endpoints are disabled, set ADMIN_TOKEN to enable them", http.StatusForbidden)
+
+			return
+		}
+
+		given := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(given, []byte("Bearer "+token)) != 1 {
+			h.logger.Debug("AdminOnly - Unauthorized admin request",
+				"path", r.URL.Path)
+
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// Handler flushing the registry read cache, of one registry or of all of them
+func (h *handler) HandleCacheFlush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	registries := h.registries
+	if r.URL.Query().Get(registryQueryParam) != "" {
+		_, registryName, err := h.registryForRequest(r)
+		if helpers.CheckErr(err) {
+			h.logger.Debug("HandleCacheFlush - Error resolving registry",
+				"error", err)
+
+			helpers.SendJSONResponse(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+
+			return
+		}
+
+		for _, registry := range h.registries {
+			if registry.Name == registryName {
+				registries = []Registry{registry}
+			}
+		}
+	}
+
+	flushed := []string{}
+	for _, registry := range registries {
+		if flusher, ok := registry.RegistryAPI.(cacheFlusher); ok {
+			flusher.FlushCache()
+			flushed = append(flushed, registry.Name)
+		}
+	}
+
+	h.logger.Info("HandleCacheFlush - Registry cache flushed",
+		"registries", flushed)
+
+	helpers.SendJSONResponse(w, http.StatusOK, map[string][]string{"flushed": flushed})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"kafka-board/schemaLint"
+)
+
+func TestAdminOnly(t *testing.T) {
+	tests := []struct {
+		name           string
+		adminToken     string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			name:           "No admin token set - Admin endpoints are disabled",
+			authorization:  "Bearer ",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Missing bearer token - Unauthorized",
+			adminToken:     "secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Wrong bearer token - Unauthorized",
+			adminToken:     "secret",
+			authorization:  "Bearer other",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Matching bearer token - Passed through",
+			adminToken:     "secret",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	h := ReturnHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, schemaLint.RuleSet{})
+	next := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ADMIN_TOKEN", tt.adminToken)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/restore", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			h.AdminOnly(next)(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.expectedStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"kafka-board/helpers"
+	"kafka-board/registryBackup"
+)
+
+// maxBackupSize caps the size of an uploaded backup tarball
+const maxBackupSize = 512 << 20
+
+// Handler exporting a registry as a gzipped tarball, e.g. /admin/backup?registry=prod.
+// The cache is flushed first so the backup reads the registry itself.
+func (h *handler) HandleBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			err.Error(),
+			http.StatusNotFound,
+			0,
+		)
+
+		h.logger.Debug("HandleBackup - Error resolving registry",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusNotFound, response)
+
+		return
+	}
+
+	if flusher, ok := registryAPI.(cacheFlusher); ok {
+		flusher.FlushCache()
+	}
+
+	backup, err := registryBackup.Export(r.Context(), registryAPI, registryName)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error exporting the registry: %v", err),
+			registryErrorStatus(err),
+			0,
+		)
+
+		h.logger.Debug("HandleBackup - Error exporting registry",
+			"registry", registryName,
+			"error", err)
+
+		helpers.SendJSONResponse(w, registryErrorStatus(err), response)
+
+		return
+	}
+
+	// Large registries take longer to stream than the server write timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Debug("HandleBackup - Could not lift the write deadline",
+			"error", err)
+	}
+
+	fileName := fmt.Sprintf("%s-%s.tar.gz", registryName, backup.Manifest.ExportedAt.Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+	if err := registryBackup.WriteTarball(backup, w); err != nil {
+		h.logger.Error("HandleBackup - Error writing backup",
+			"registry", registryName,
+			"error", err)
+
+		return
+	}
+
+	versions, deleted := backup.VersionCount()
+	h.logger.Info("HandleBackup - Registry exported",
+		"registry", registryName,
+		"subjects", len(backup.Subjects),
+		"versions", versions,
+		"deleted", deleted)
+}
+
+// Handler restoring a backup tarball, sent as the request body, into an empty registry,
+// e.g. POST /admin/restore?registry=staging
+func (h *handler) HandleRestore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if h.refuseInReadOnlyMode(w, "HandleRestore", "Restoring backups") {
+		return
+	}
+
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			err.Error(),
+			http.StatusNotFound,
+			0,
+		)
+
+		h.logger.Debug("HandleRestore - Error resolving registry",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusNotFound, response)
+
+		return
+	}
+
+	// Uploads and restores of large registries outlast the server timeouts
+	controller := http.NewResponseController(w)
+	if err := controller.SetReadDeadline(time.Time{}); err != nil {
+		h.logger.Debug("HandleRestore - Could not lift the read deadline",
+			"error", err)
+	}
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Debug("HandleRestore - Could not lift the write deadline",
+			"error", err)
+	}
+
+	backup, err := registryBackup.ReadTarball(http.MaxBytesReader(w, r.Body, maxBackupSize))
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error reading backup: %v", err),
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleRestore - Error reading backup",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	// The registry is read subject by subject to check it is empty
+	if flusher, ok := registryAPI.(cacheFlusher); ok {
+		flusher.FlushCache()
+	}
+
+	report, err := registryBackup.Restore(r.Context(), registryAPI, backup)
+	if errors.Is(err, registryBackup.ErrRegistryNotEmpty) {
+		response := helpers.CreateResponseObject(
+			nil,
+			err.Error(),
+			http.StatusConflict,
+			0,
+		)
+
+		h.logger.Debug("HandleRestore - Registry not empty",
+			"registry", registryName)
+
+		helpers.SendJSONResponse(w, http.StatusConflict, response)
+
+		return
+	}
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error restoring the backup after importing %d versions: %v", report.Versions, err),
+			registryErrorStatus(err),
+			0,
+		)
+
+		h.logger.Error("HandleRestore - Error restoring backup",
+			"registry", registryName,
+			"error", err)
+
+		helpers.SendJSONResponse(w, registryErrorStatus(err), response)
+
+		return
+	}
+
+	h.logger.Info("HandleRestore - Backup restored",
+		"registry", registryName,
+		"from", backup.Manifest.Registry,
+		"subjects", report.Subjects,
+		"versions", report.Versions)
+
+	helpers.SendJSONResponse(w, http.StatusOK, report)
+}
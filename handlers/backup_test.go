@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kafka-board/internal/registryFake"
+	"kafka-board/registryBackup"
+	"kafka-board/schemaLint"
+	"kafka-board/types"
+)
+
+func TestHandleRestore(t *testing.T) {
+	source := &registryFake.Registry{
+		Versions: map[string][]types.Schema{
+			"orders": {{Subject: "orders", Version: 1, Id: 10, Schema: `{"type": "string"}`}},
+		},
+		GlobalMode: types.ModeReadWrite,
+	}
+	backup, err := registryBackup.Export(context.Background(), source, "dev")
+	if err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	var tarball bytes.Buffer
+	if err := registryBackup.WriteTarball(backup, &tarball); err != nil {
+		t.Fatalf("WriteTarball() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           []byte
+		readOnly       bool
+		expectedStatus int
+		expectWrite    bool
+	}{
+		{
+			name:           "Backup tarball - Restored",
+			method:         http.MethodPost,
+			target:         "/admin/restore",
+			body:           tarball.Bytes(),
+			expectedStatus: http.StatusOK,
+			expectWrite:    true,
+		},
+		{
+			name:           "Wrong method - Method not allowed",
+			method:         http.MethodGet,
+			target:         "/admin/restore",
+			body:           tarball.Bytes(),
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Read-only board - Forbidden",
+			method:         http.MethodPost,
+			target:         "/admin/restore",
+			body:           tarball.Bytes(),
+			readOnly:       true,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Unknown registry - Not found",
+			method:         http.MethodPost,
+			target:         "/admin/restore?registry=prod",
+			body:           tarball.Bytes(),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Not a tarball - Bad request",
+			method:         http.MethodPost,
+			target:         "/admin/restore",
+			body:           []byte(`{"subject": "orders"}`),
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.readOnly {
+				t.Setenv("READ_ONLY", "true")
+			}
+
+			registryAPI := &mockRegistryAPI{}
+			h := ReturnHandler(logger, []Registry{{Name: "dev", RegistryAPI: registryAPI}}, schemaLint.RuleSet{})
+
+			req := httptest.NewRequest(tt.method, tt.target, bytes.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			h.HandleRestore(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if wrote := len(registryAPI.writes) > 0; wrote != tt.expectWrite {
+				t.Errorf("writes = %q, want a write: %t", registryAPI.writes, tt.expectWrite)
+			}
+			if tt.expectWrite && !strings.Contains(strings.Join(registryAPI.writes, "\n"), "import orders") {
+				t.Errorf("writes = %q, want the orders version imported", registryAPI.writes)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"kafka-board/helpers"
+)
+
+// Handler changing compatibility levels from the home page cards. PUT sets the level
+// of a subject, or the global level when no subject is given, DELETE resets a subject
+// to the global default. Disabled in read-only mode.
+func (h *handler) HandleCompatibilityLevel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if h.refuseCrossSiteWrite(w, r, "HandleCompatibilityLevel") {
+		return
+	}
+
+	if h.refuseInReadOnlyMode(w, "HandleCompatibilityLevel", "Changing compatibility levels") {
+		return
+	}
+
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			err.Error(),
+			http.StatusNotFound,
+			0,
+		)
+
+		h.logger.Debug("HandleCompatibilityLevel - Error resolving registry",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusNotFound, response)
+
+		return
+	}
+
+	var requestData struct {
+		// Empty for the global level
+		Subject string `json:"subject"`
+		Level   string `json:"level"`
+	}
+
+	if r.Method == http.MethodDelete {
+		requestData.Subject = r.URL.Query().Get("subject")
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err == nil {
+			err = json.Unmarshal(body, &requestData)
+		}
+		if helpers.CheckErr(err) {
+			response := helpers.CreateResponseObject(
+				nil,
+				fmt.Sprintf("Error parsing JSON request: %v", err),
+				http.StatusBadRequest,
+				0,
+			)
+
+			h.logger.Debug("HandleCompatibilityLevel - Error parsing JSON request",
+				"error", err)
+
+			helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+			return
+		}
+	}
+
+	if (r.Method == http.MethodDelete && requestData.Subject == "") || (r.Method == http.MethodPut && requestData.Level == "") {
+		response := helpers.CreateResponseObject(
+			nil,
+			"Missing required fields",
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleCompatibilityLevel - Missing required fields",
+			"error", "subject is empty on DELETE or level is empty on PUT")
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	target := "the global level"
+	if requestData.Subject != "" {
+		target = requestData.Subject
+	}
+
+	var message string
+	if r.Method == http.MethodDelete {
+		err = registryAPI.DeleteCompatibilityLevel(r.Context(), requestData.Subject)
+		message = fmt.Sprintf("%s now takes the global default", target)
+	} else {
+		err = registryAPI.UpdateCompatibilityLevel(r.Context(), requestData.Subject, requestData.Level)
+		message = fmt.Sprintf("Compatibility level of %s set to %s", target, requestData.Level)
+	}
+
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error changing the compatibility level of %s: %s", target, registryErrorMessage(err)),
+			registryErrorStatus(err),
+			registryErrorCode(err),
+		)
+
+		h.logger.Debug("HandleCompatibilityLevel - Error changing compatibility level",
+			"error", err)
+
+		helpers.SendJSONResponse(w, response.StatusCode, response)
+
+		return
+	}
+
+	h.logger.Info("HandleCompatibilityLevel - Compatibility level changed",
+		"registry", registryName,
+		"method", r.Method,
+		"subject", requestData.Subject,
+		"level", requestData.Level)
+
+	helpers.SendJSONResponse(w, http.StatusOK, helpers.CreateResponseObject(nil, message, http.StatusOK, 0))
+}
+
+// Handler previewing a compatibility level: it lists the existing versions of the
+// subject that would violate it, or of every subject taking the global default when
+// no subject is given
+func (h *handler) HandleCompatibilityLevelPreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	registryAPI, _, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			err.Error(),
+			http.StatusNotFound,
+			0,
+		)
+
+		h.logger.Debug("HandleCompatibilityLevelPreview - Error resolving registry",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusNotFound, response)
+
+		return
+	}
+
+	subjectName := r.URL.Query().Get("subject")
+	level := r.URL.Query().Get("level")
+
+	preview, err := registryAPI.PreviewCompatibilityLevel(r.Context(), subjectName, level)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error previewing %s: %s", level, registryErrorMessage(err)),
+			registryErrorStatus(err),
+			registryErrorCode(err),
+		)
+
+		h.logger.Debug("HandleCompatibilityLevelPreview - Error previewing level",
+			"error", err)
+
+		helpers.SendJSONResponse(w, response.StatusCode, response)
+
+		return
+	}
+
+	helpers.SendJSONResponse(w, http.StatusOK, preview)
+}
+
+// refuseInReadOnlyMode answers 403 to a write while the board is read-only, and
+// reports whether it did
+func (h *handler) refuseInReadOnlyMode(w http.ResponseWriter, caller string, action string) bool {
+	if !helpers.IsReadOnly() {
+		return false
+	}
+
+	response := helpers.CreateResponseObject(
+		nil,
+		fmt.Sprintf("%s is disabled in read-only mode", action),
+		http.StatusForbidden,
+		0,
+	)
+
+	h.logger.Debug(caller + " - Refused in read-only mode")
+
+	helpers.SendJSONResponse(w, http.StatusForbidden, response)
+
+	return true
+}
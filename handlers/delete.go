@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"kafka-board/helpers"
+	"kafka-board/registryErrors"
+	"kafka-board/types"
+)
+
+// Handler deleting a subject, or one of its versions, from the schema page. Versions
+// are soft-deleted first and permanently deleted after. The subject name must be
+// typed again to confirm. Disabled in read-only mode and in the READONLY modes.
+func (h *handler) HandleDeleteSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if h.refuseCrossSiteWrite(w, r, "HandleDeleteSchema") {
+		return
+	}
+
+	if h.refuseInReadOnlyMode(w, "HandleDeleteSchema", "Deleting schemas") {
+		return
+	}
+
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			err.Error(),
+			http.StatusNotFound,
+			0,
+		)
+
+		h.logger.Debug("HandleDeleteSchema - Error resolving registry",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusNotFound, response)
+
+		return
+	}
+
+	var requestData struct {
+		Subject string `json:"subject"`
+		// Version to delete, the whole subject when 0
+		Version   int  `json:"version"`
+		Permanent bool `json:"permanent"`
+		// Subject name typed by the user to confirm
+		Confirm string `json:"confirm"`
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &requestData)
+	}
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error parsing JSON request: %v", err),
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleDeleteSchema - Error parsing JSON request",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	if requestData.Subject == "" || requestData.Version < 0 {
+		response := helpers.CreateResponseObject(
+			nil,
+			"Missing required fields",
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleDeleteSchema - Missing required fields",
+			"error", "requestData.Subject is empty or requestData.Version is negative")
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	if requestData.Confirm != requestData.Subject {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Not deleted, type %s to confirm", requestData.Subject),
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleDeleteSchema - Deletion not confirmed",
+			"subject", requestData.Subject)
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	if mode := h.effectiveMode(r.Context(), registryAPI, requestData.Subject); blocksDeletion(mode) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Not deleted, %s is in %s mode", requestData.Subject, mode),
+			http.StatusUnprocessableEntity,
+			registryErrors.ErrOperationNotPermitted.Code,
+		)
+
+		h.logger.Debug("HandleDeleteSchema - Deletion refused by the subject mode",
+			"subject", requestData.Subject,
+			"mode", mode)
+
+		helpers.SendJSONResponse(w, http.StatusUnprocessableEntity, response)
+
+		return
+	}
+
+	kind := "Soft-deleted"
+	if requestData.Permanent {
+		kind = "Permanently deleted"
+	}
+
+	var message string
+	deleted := []int{requestData.Version}
+	if requestData.Version == 0 {
+		deleted, err = registryAPI.DeleteSubject(r.Context(), requestData.Subject, requestData.Permanent)
+		message = fmt.Sprintf("%s %s, versions %s", kind, requestData.Subject, joinVersions(deleted))
+	} else {
+		err = registryAPI.DeleteSubjectVersion(r.Context(), requestData.Subject, requestData.Version, requestData.Permanent)
+		message = fmt.Sprintf("%s version %d of %s", kind, requestData.Version, requestData.Subject)
+	}
+
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error deleting %s: %s", requestData.Subject, registryErrorMessage(err)),
+			registryErrorStatus(err),
+			registryErrorCode(err),
+		)
+
+		h.logger.Debug("HandleDeleteSchema - Error deleting",
+			"error", err)
+
+		helpers.SendJSONResponse(w, response.StatusCode, response)
+
+		return
+	}
+
+	h.logger.Info("HandleDeleteSchema - Deleted",
+		"registry", registryName,
+		"subject", requestData.Subject,
+		"versions", deleted,
+		"permanent", requestData.Permanent)
+
+	response := helpers.CreateResponseObject(nil, message, http.StatusOK, 0)
+	response.Deleted = deleted
+
+	helpers.SendJSONResponse(w, http.StatusOK, response)
+}
+
+// Handler restoring a soft-deleted version from the schema page, by registering its
+// schema again. Disabled in read-only mode and when the subject mode refuses new schemas.
+func (h *handler) HandleRestoreVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if h.refuseCrossSiteWrite(w, r, "HandleRestoreVersion") {
+		return
+	}
+
+	if h.refuseInReadOnlyMode(w, "HandleRestoreVersion", "Restoring versions") {
+		return
+	}
+
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			err.Error(),
+			http.StatusNotFound,
+			0,
+		)
+
+		h.logger.Debug("HandleRestoreVersion - Error resolving registry",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusNotFound, response)
+
+		return
+	}
+
+	var requestData struct {
+		Subject string `json:"subject"`
+		Version int    `json:"version"`
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &requestData)
+	}
+	if helpers.CheckErr(err) || requestData.Subject == "" || requestData.Version <= 0 {
+		response := helpers.CreateResponseObject(
+			nil,
+			"Missing required fields",
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleRestoreVersion - Invalid request",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	if mode := h.effectiveMode(r.Context(), registryAPI, requestData.Subject); blocksRegistration(mode) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Not restored, %s is in %s mode and does not take new schemas", requestData.Subject, mode),
+			http.StatusUnprocessableEntity,
+			registryErrors.ErrOperationNotPermitted.Code,
+		)
+
+		h.logger.Debug("HandleRestoreVersion - Restore refused by the subject mode",
+			"subject", requestData.Subject,
+			"mode", mode)
+
+		helpers.SendJSONResponse(w, http.StatusUnprocessableEntity, response)
+
+		return
+	}
+
+	resp, err := registryAPI.RestoreSubjectVersion(r.Context(), requestData.Subject, requestData.Version)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleRestoreVersion - Error restoring version",
+			"error", err)
+
+		// Errors reported by the registry, including a failed dry run, already carry
+		// their code and message
+		var registryErr *registryErrors.Error
+		if status := registryErrorStatus(err); status != http.StatusInternalServerError && !errors.As(err, &registryErr) {
+			resp = helpers.CreateResponseObject(nil, err.Error(), status, 0)
+		}
+
+		helpers.SendJSONResponse(w, registryErrorStatus(err), resp)
+
+		return
+	}
+
+	h.logger.Info("HandleRestoreVersion - Version restored",
+		"registry", registryName,
+		"subject", requestData.Subject,
+		"version", requestData.Version,
+		"newVersion", resp.Registered.Version)
+
+	helpers.SendJSONResponse(w, http.StatusOK, resp)
+}
+
+// blocksDeletion reports whether a mode refuses deletes
+func blocksDeletion(mode string) bool {
+	return mode == types.ModeReadOnly || mode == types.ModeReadOnlyOverride
+}
+
+// joinVersions lists version numbers for messages, e.g. "1, 2, 3"
+func joinVersions(versions []int) string {
+	versionStrings := make([]string, len(versions))
+	for i, version := range versions {
+		versionStrings[i] = fmt.Sprintf("%d", version)
+	}
+	return strings.Join(versionStrings, ", ")
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"text/template"
+
+	"kafka-board/helpers"
+	"kafka-board/schemaDiff"
+	"kafka-board/types"
+)
+
+// Page handler comparing two versions of a subject, e.g.
+// /schema-diff/?topic=orders&from=3&to=7. Soft-deleted versions can be compared too.
+// With format=json the diff is returned as JSON instead of a page.
+func (h *handler) HandleSchemaDiff(w http.ResponseWriter, r *http.Request) {
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleSchemaDiff - Error resolving registry",
+			"error", err)
+
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	subjectName := r.URL.Query().Get("topic")
+	from, fromErr := strconv.Atoi(r.URL.Query().Get("from"))
+	to, toErr := strconv.Atoi(r.URL.Query().Get("to"))
+	if subjectName == "" || helpers.CheckErr(fromErr) || helpers.CheckErr(toErr) || from <= 0 || to <= 0 {
+		h.logger.Debug("HandleSchemaDiff - Missing required parameters",
+			"error", "subjectName is an empty string or a version is not a positive number")
+
+		http.Error(w, "Missing required parameters: topic, from and to versions", http.StatusBadRequest)
+
+		return
+	}
+
+	asJSON := r.URL.Query().Get("format") == "json"
+	sendError := func(err error) {
+		if asJSON {
+			http.Error(w, registryErrorMessage(err), registryErrorStatus(err))
+			return
+		}
+		h.sendPageError(w, registryAPI, registryName, err)
+	}
+
+	original, err := registryAPI.GetSubjectVersion(r.Context(), subjectName, from, true)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleSchemaDiff - Error fetching the from version",
+			"error", err)
+
+		sendError(err)
+
+		return
+	}
+
+	update, err := registryAPI.GetSubjectVersion(r.Context(), subjectName, to, true)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleSchemaDiff - Error fetching the to version",
+			"error", err)
+
+		sendError(err)
+
+		return
+	}
+
+	diff := schemaDiff.Compare(original, update)
+
+	if asJSON {
+		helpers.SendJSONResponse(w, http.StatusOK, diff)
+
+		return
+	}
+
+	// The version pickers list every version, soft-deleted ones included
+	schemas, err := registryAPI.GetSchemas(r.Context(), subjectName, true)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleSchemaDiff - Error listing versions",
+			"error", err)
+
+		sendError(err)
+
+		return
+	}
+
+	t := template.Must(template.New("diff").Parse(diffTemplate))
+	data := struct {
+		Registry    string
+		Registries  []string
+		SubjectName string
+		Versions    []types.Schema
+		From        types.Schema
+		To          types.Schema
+		Diff        schemaDiff.Diff
+	}{
+		Registry:    registryName,
+		Registries:  h.registryNames(),
+		SubjectName: subjectName,
+		Versions:    schemas,
+		From:        original,
+		To:          update,
+		Diff:        diff,
+	}
+
+	h.logger.Debug("HandleSchemaDiff - Diff data",
+		"subject", subjectName,
+		"from", from,
+		"to", to,
+		"changes", len(diff.Changes))
+
+	t.Execute(w, data)
+}
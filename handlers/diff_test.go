@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"kafka-board/schemaLint"
+	"kafka-board/types"
+)
+
+func TestHandleSchemaDiffEscaping(t *testing.T) {
+	hostile := `orders"</script><script>alert(1)</script>`
+	registryAPI := &mockRegistryAPI{mockSchema: types.Schema{Subject: hostile, Version: 1, Id: 1, SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object"}`}}
+	h := ReturnHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), []Registry{{Name: "dev", RegistryAPI: registryAPI}}, schemaLint.RuleSet{})
+
+	req := httptest.NewRequest(http.MethodGet, "/schema-diff/?from=1&to=1&topic="+url.QueryEscape(hostile), nil)
+	w := httptest.NewRecorder()
+
+	h.HandleSchemaDiff(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	for _, expected := range []string{
+		`<title>Schema Diff - orders&#34;&lt;/script&gt;&lt;script&gt;alert(1)&lt;/script&gt; v1`,
+		`<h1>🔍 orders&#34;&lt;/script&gt;&lt;script&gt;alert(1)&lt;/script&gt;</h1>`,
+		`const subjectName = "orders\"\u003C/script\u003E\u003Cscript\u003Ealert(1)\u003C/script\u003E";`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("body does not hold %q", expected)
+		}
+	}
+	if strings.Contains(body, "<script>alert(1)") {
+		t.Errorf("body holds the unescaped subject name")
+	}
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"kafka-board/helpers"
+	"kafka-board/schemaCanonical"
+	"kafka-board/types"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// fingerprintSearchWorkers caps the subjects searched at once
+const fingerprintSearchWorkers = 8
+
+// fingerprintSearchTimeout stops a search in time to answer with what it found
+// before the server write timeout
+var fingerprintSearchTimeout = 8 * time.Second
+
+// fingerprintMatch is a version whose canonical form has the searched fingerprint
+type fingerprintMatch struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+	Id      int    `json:"id"`
+	schemaCanonical.Canonical
+}
+
+// maxCanonicalForms caps the canonical forms kept in memory
+const maxCanonicalForms = 5000
+
+// canonicalKey identifies a schema, IDs are unique within a context of a registry
+type canonicalKey struct {
+	registry string
+	context  string
+	id       int
+}
+
+// canonicalCache keeps the most recently used canonical forms, dropping the least
+// recently used one once full
+type canonicalCache struct {
+	mu       sync.Mutex
+	capacity int
+	// Front is the most recently used, elements hold a canonicalEntry
+	order   *list.List
+	entries map[canonicalKey]*list.Element
+}
+
+type canonicalEntry struct {
+	key       canonicalKey
+	canonical *schemaCanonical.Canonical
+}
+
+func newCanonicalCache(capacity int) *canonicalCache {
+	return &canonicalCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[canonicalKey]*list.Element),
+	}
+}
+
+func (c *canonicalCache) get(key canonicalKey) (*schemaCanonical.Canonical, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(canonicalEntry).canonical, true
+}
+
+func (c *canonicalCache) set(key canonicalKey, canonical *schemaCanonical.Canonical) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = canonicalEntry{key: key, canonical: canonical}
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(canonicalEntry{key: key, canonical: canonical})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(canonicalEntry).key)
+	}
+}
+
+// canonicalForm returns the canonical form and fingerprints of a schema, or nil when
+// the schema or its references cannot be parsed. A schema never changes once it has
+// an ID, so the most recently used canonical forms are cached by ID.
+func (h *handler) canonicalForm(ctx context.Context, registryAPI registryAPICalls, registryName string, schema types.Schema) *schemaCanonical.Canonical {
+	schemaContext, _ := types.SplitContext(schema.Subject)
+	key := canonicalKey{registry: registryName, context: schemaContext, id: schema.Id}
+	if schema.Id > 0 {
+		if cached, ok := h.canonicalForms.get(key); ok {
+			return cached
+		}
+	}
+
+	references, err := registryAPI.ResolveReferences(ctx, schema)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("canonicalForm - Error resolving schema references",
+			"error", err)
+
+		return nil
+	}
+
+	canonical, err := schemaCanonical.Canonicalize(schema, references)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("canonicalForm - Error computing canonical form",
+			"error", err)
+
+		return nil
+	}
+
+	if schema.Id > 0 {
+		h.canonicalForms.set(key, &canonical)
+	}
+
+	return &canonical
+}
+
+// Handler finding the versions whose SHA-256 or Rabin fingerprint starts with the
+// given one, e.g. /fingerprint-search/?fingerprint=3f1c9a6b. Searches every subject
+// of the registry, or of one schema context, several at a time. Subjects that cannot
+// be read are listed under skipped rather than failing the search, and a search
+// running out of time answers with the matches found so far, flagged as partial.
+func (h *handler) HandleFingerprintSearch(w http.ResponseWriter, r *http.Request) {
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			err.Error(),
+			http.StatusNotFound,
+			0,
+		)
+
+		h.logger.Debug("HandleFingerprintSearch - Error resolving registry",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusNotFound, response)
+
+		return
+	}
+
+	fingerprint := r.URL.Query().Get("fingerprint")
+	if len(fingerprint) < schemaCanonical.MinSearchLength {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("A fingerprint of at least %d characters is required", schemaCanonical.MinSearchLength),
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleFingerprintSearch - Fingerprint too short",
+			"fingerprint", fingerprint)
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	subjects, err := registryAPI.ReturnSubjects(r.Context())
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			registryErrorMessage(err),
+			registryErrorStatus(err),
+			0,
+		)
+
+		h.logger.Debug("HandleFingerprintSearch - Error fetching subjects",
+			"error", err)
+
+		helpers.SendJSONResponse(w, registryErrorStatus(err), response)
+
+		return
+	}
+
+	// Subjects are searched in parallel until the search times out, the subjects
+	// left are counted under remaining
+	searchCtx, cancel := context.WithTimeout(r.Context(), fingerprintSearchTimeout)
+	defer cancel()
+
+	subjects = subjectsInContext(subjects, r.URL.Query().Get("context"))
+	results := make([]subjectSearch, len(subjects))
+
+	group := new(errgroup.Group)
+	group.SetLimit(fingerprintSearchWorkers)
+	for i, subject := range subjects {
+		if searchCtx.Err() != nil {
+			break
+		}
+
+		group.Go(func() error {
+			matches, err := h.matchFingerprint(searchCtx, registryAPI, registryName, subject, fingerprint)
+			// Subjects cut short by the timeout were not searched rather than unreadable
+			if helpers.CheckErr(err) && searchCtx.Err() != nil {
+				return nil
+			}
+			results[i] = subjectSearch{searched: true, matches: matches, err: err}
+
+			return nil
+		})
+	}
+	group.Wait()
+
+	if r.Context().Err() != nil {
+		h.logger.Debug("HandleFingerprintSearch - Request canceled",
+			"error", r.Context().Err())
+
+		return
+	}
+
+	matches := []fingerprintMatch{}
+	skipped := []string{}
+	remaining := 0
+	for i, result := range results {
+		switch {
+		case !result.searched:
+			remaining++
+
+		case helpers.CheckErr(result.err):
+			h.logger.Debug("HandleFingerprintSearch - Error reading subject",
+				"subject", subjects[i],
+				"error", result.err)
+
+			skipped = append(skipped, subjects[i])
+
+		default:
+			matches = append(matches, result.matches...)
+		}
+	}
+
+	h.logger.Debug("HandleFingerprintSearch - Search results",
+		"fingerprint", fingerprint,
+		"matches", len(matches),
+		"skipped", len(skipped),
+		"remaining", remaining)
+
+	helpers.SendJSONResponse(w, http.StatusOK, struct {
+		Fingerprint string             `json:"fingerprint"`
+		Matches     []fingerprintMatch `json:"matches"`
+		Skipped     []string           `json:"skipped"`
+		// Subjects not searched before the search timed out
+		Partial   bool `json:"partial,omitempty"`
+		Remaining int  `json:"remaining,omitempty"`
+	}{
+		Fingerprint: fingerprint,
+		Matches:     matches,
+		Skipped:     skipped,
+		Partial:     remaining > 0,
+		Remaining:   remaining,
+	})
+}
+
+// subjectSearch is the outcome of searching one subject for a fingerprint
+type subjectSearch struct {
+	searched bool
+	matches  []fingerprintMatch
+	err      error
+}
+
+// matchFingerprint returns the versions of a subject with the given fingerprint.
+// Versions that do not parse have no canonical form and never match.
+func (h *handler) matchFingerprint(ctx context.Context, registryAPI registryAPICalls, registryName string, subject string, fingerprint string) ([]fingerprintMatch, error) {
+	versions, err := registryAPI.GetSchemas(ctx, subject, false)
+	if helpers.CheckErr(err) {
+		return nil, err
+	}
+
+	var matches []fingerprintMatch
+	for _, version := range versions {
+		schema, err := registryAPI.GetSubjectVersion(ctx, subject, version.Version, false)
+		if helpers.CheckErr(err) {
+			return nil, err
+		}
+
+		canonical := h.canonicalForm(ctx, registryAPI, registryName, schema)
+		if canonical == nil || !canonical.Matches(fingerprint) {
+			continue
+		}
+		matches = append(matches, fingerprintMatch{
+			Subject:   subject,
+			Version:   schema.Version,
+			Id:        schema.Id,
+			Canonical: *canonical,
+		})
+	}
+
+	// A canceled search leaves versions without a canonical form, not unmatched
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"kafka-board/schemaCanonical"
+	"kafka-board/schemaLint"
+	"kafka-board/types"
+)
+
+func TestHandleFingerprintSearch(t *testing.T) {
+	schema := types.Schema{Subject: "orders", Version: 1, Id: 7, SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object"}`}
+	canonical, err := schemaCanonical.Canonicalize(schema, nil)
+	if err != nil {
+		t.Fatalf("Canonicalize() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name              string
+		fingerprint       string
+		timeout           time.Duration
+		expectedStatus    int
+		expectedMatches   int
+		expectedRemaining int
+	}{
+		{
+			name:            "Matching fingerprint - Every subject listed",
+			fingerprint:     canonical.SHA256[:12],
+			timeout:         time.Minute,
+			expectedStatus:  http.StatusOK,
+			expectedMatches: 3,
+		},
+		{
+			name:           "Other fingerprint - No match",
+			fingerprint:    "0000000000000000",
+			timeout:        time.Minute,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:              "Search timed out - Partial results",
+			fingerprint:       canonical.SHA256[:12],
+			expectedStatus:    http.StatusOK,
+			expectedRemaining: 3,
+		},
+		{
+			name:           "Fingerprint too short - Bad request",
+			fingerprint:    "3f1c",
+			timeout:        time.Minute,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(timeout time.Duration) { fingerprintSearchTimeout = timeout }(fingerprintSearchTimeout)
+			fingerprintSearchTimeout = tt.timeout
+
+			registryAPI := &mockRegistryAPI{mockSchema: schema, subjects: []string{"orders", "payments", "refunds"}}
+			h := ReturnHandler(logger, []Registry{{Name: "dev", RegistryAPI: registryAPI}}, schemaLint.RuleSet{})
+
+			req := httptest.NewRequest(http.MethodGet, "/fingerprint-search/?fingerprint="+tt.fingerprint, nil)
+			w := httptest.NewRecorder()
+
+			h.HandleFingerprintSearch(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.expectedStatus, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				Matches   []fingerprintMatch `json:"matches"`
+				Skipped   []string           `json:"skipped"`
+				Partial   bool               `json:"partial"`
+				Remaining int                `json:"remaining"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatalf("Error parsing response: %v", err)
+			}
+
+			if len(result.Matches) != tt.expectedMatches {
+				t.Errorf("matches = %+v, want %d", result.Matches, tt.expectedMatches)
+			}
+			if len(result.Skipped) != 0 {
+				t.Errorf("skipped = %q, want none", result.Skipped)
+			}
+			if result.Remaining != tt.expectedRemaining || result.Partial != (tt.expectedRemaining > 0) {
+				t.Errorf("partial = %t, remaining = %d, want %d remaining", result.Partial, result.Remaining, tt.expectedRemaining)
+			}
+		})
+	}
+}
+
+func TestCanonicalFormCache(t *testing.T) {
+	registryAPI := &mockRegistryAPI{}
+	h := ReturnHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), []Registry{{Name: "dev", RegistryAPI: registryAPI}}, schemaLint.RuleSet{})
+	schema := types.Schema{Subject: "orders", Version: 1, Id: 7, SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object"}`}
+
+	first := h.canonicalForm(t.Context(), registryAPI, "dev", schema)
+	second := h.canonicalForm(t.Context(), registryAPI, "dev", schema)
+	if first == nil || second != first {
+		t.Errorf("canonicalForm() = %v then %v, want the cached form", first, second)
+	}
+	if lookups := registryAPI.referenceLookups.Load(); lookups != 1 {
+		t.Errorf("references resolved %d times, want once", lookups)
+	}
+
+	// The same ID in another registry is another schema
+	h.canonicalForm(t.Context(), registryAPI, "prod", schema)
+	if lookups := registryAPI.referenceLookups.Load(); lookups != 2 {
+		t.Errorf("references resolved %d times, want twice", lookups)
+	}
+}
+
+func TestCanonicalCacheEviction(t *testing.T) {
+	cache := newCanonicalCache(2)
+	first, second, third := canonicalKey{id: 1}, canonicalKey{id: 2}, canonicalKey{id: 3}
+
+	cache.set(first, &schemaCanonical.Canonical{SHA256: "1"})
+	cache.set(second, &schemaCanonical.Canonical{SHA256: "2"})
+	// Reading the first form makes the second the least recently used
+	cache.get(first)
+	cache.set(third, &schemaCanonical.Canonical{SHA256: "3"})
+
+	if _, ok := cache.get(second); ok {
+		t.Errorf("least recently used form still cached")
+	}
+	for _, key := range []canonicalKey{first, third} {
+		if canonical, ok := cache.get(key); !ok || canonical.SHA256 != fmt.Sprint(key.id) {
+			t.Errorf("form %d = %v, %t, want it cached", key.id, canonical, ok)
+		}
+	}
+}
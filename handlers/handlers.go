@@ -1,15 +1,22 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"text/template"
 
 	"kafka-board/helpers"
+	"kafka-board/registryErrors"
+	"kafka-board/schemaCanonical"
+	"kafka-board/schemaCompatibility"
+	"kafka-board/schemaLint"
 	"kafka-board/types"
 )
 
@@ -18,51 +25,127 @@ var trueVal = true
 
 // Page load handler for the home page
 func (h *handler) HandleHomePage(w http.ResponseWriter, r *http.Request) {
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleHomePage - Error resolving registry",
+			"error", err)
+
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
 
 	// First get all subjects
-	subjects, err := h.registryAPI.ReturnSubjects()
+	subjects, err := registryAPI.ReturnSubjects(r.Context())
 
 	if helpers.CheckErr(err) {
 		h.logger.Debug("HandleHomePage - Error fetching subjects",
 			"error", err)
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.sendPageError(w, registryAPI, registryName, err)
 
 		return
 	}
 
+	// Contexts are only used to group subjects, the page works without them
+	contexts, err := registryAPI.ReturnContexts(r.Context())
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleHomePage - Error fetching contexts",
+			"error", err)
+
+		contexts = []string{types.DefaultContext}
+	}
+
+	selectedContext := r.URL.Query().Get("context")
+	if selectedContext != "" && !slices.Contains(contexts, selectedContext) {
+		h.logger.Debug("HandleHomePage - Unknown context",
+			"context", selectedContext)
+
+		http.Error(w, "Unknown context", http.StatusNotFound)
+
+		return
+	}
+	subjects = subjectsInContext(subjects, selectedContext)
+
 	//Fetch Global Config
-	globalConfig, err := h.registryAPI.GetGlobalConfig()
+	globalConfig, err := registryAPI.GetGlobalConfig(r.Context())
 
 	if helpers.CheckErr(err) {
 		h.logger.Debug("HandleHomePage - Error fetching global config",
 			"error", err)
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.sendPageError(w, registryAPI, registryName, err)
 
 		return
 	}
 
 	// Then get configs for all subjects
-	configs, err := h.registryAPI.ReturnSubjectConfigs(subjects)
+	configs, err := registryAPI.ReturnSubjectConfigs(r.Context(), subjects)
 
 	if helpers.CheckErr(err) {
 		h.logger.Debug("HandleHomePage - Error fetching configs",
 			"error", err)
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.sendPageError(w, registryAPI, registryName, err)
 
 		return
 	}
 
+	// Modes are shown when available, registries without mode support still get a page
+	globalMode, err := registryAPI.GetMode(r.Context(), "")
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleHomePage - Error fetching global mode",
+			"error", err)
+
+		globalMode = types.SubjectMode{Error: registryErrorMessage(err)}
+	}
+
+	subjectModes, err := registryAPI.ReturnSubjectModes(r.Context(), subjects)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleHomePage - Error fetching modes",
+			"error", err)
+
+		h.sendPageError(w, registryAPI, registryName, err)
+
+		return
+	}
+
+	modes := make(map[string]types.SubjectMode, len(subjectModes))
+	for _, mode := range subjectModes {
+		modes[mode.Name] = mode
+	}
+
 	t := template.Must(template.New("home").Parse(homeTemplate))
 
 	data := struct {
-		Configs      []types.SubjectConfigInterface
+		Registry   string
+		Registries []string
+		Configs    []types.SubjectConfigInterface
+		// Subject configs grouped by schema context
+		ContextGroups []contextGroup
+		Contexts      []string
+		// Context the subjects are filtered on, empty for every context
+		Context      string
 		GlobalConfig types.GlobalConfig
+		Levels       []string
+		GlobalMode   types.SubjectMode
+		// Mode of each subject by name
+		Modes     map[string]types.SubjectMode
+		ModeNames []string
+		ReadOnly  bool
 	}{
-		Configs:      configs,
-		GlobalConfig: globalConfig,
+		Registry:      registryName,
+		Registries:    h.registryNames(),
+		Configs:       configs,
+		ContextGroups: groupByContext(contexts, configs),
+		Contexts:      contexts,
+		Context:       selectedContext,
+		GlobalConfig:  globalConfig,
+		Levels:        schemaCompatibility.Levels,
+		GlobalMode:    globalMode,
+		Modes:         modes,
+		ModeNames:     types.Modes,
+		ReadOnly:      helpers.IsReadOnly(),
 	}
 
 	h.logger.Debug("HandleHomePage - Home page data",
@@ -73,6 +156,16 @@ func (h *handler) HandleHomePage(w http.ResponseWriter, r *http.Request) {
 
 // Page load handler for the schema page
 func (h *handler) HandleSchemaPage(w http.ResponseWriter, r *http.Request) {
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleSchemaPage - Error resolving registry",
+			"error", err)
+
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
 	subjectName := r.URL.Query().Get("topic")
 
 	if subjectName == "" {
@@ -82,49 +175,151 @@ func (h *handler) HandleSchemaPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	schemas, err := h.registryAPI.GetSchemas(subjectName)
+	includeDeleted := r.URL.Query().Get("deleted") == "true"
+
+	versions, err := registryAPI.GetSchemas(r.Context(), subjectName, includeDeleted)
 	if helpers.CheckErr(err) {
 		h.logger.Debug("HandleSchemaPage - Error fetching schemas",
 			"error", err)
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.sendPageError(w, registryAPI, registryName, err)
 
 		return
 	}
 
+	// Only the latest version is loaded with the page, the others when expanded.
+	// The version list may be shared with other requests, so it is copied first.
+	schemas := append([]types.Schema(nil), versions...)
+	for i := len(schemas) - 1; i >= 0; i-- {
+		if schemas[i].Deleted {
+			continue
+		}
+
+		latest, err := registryAPI.GetSubjectVersion(r.Context(), subjectName, schemas[i].Version, false)
+		if helpers.CheckErr(err) {
+			h.logger.Debug("HandleSchemaPage - Error fetching latest version",
+				"error", err)
+
+			h.sendPageError(w, registryAPI, registryName, err)
+
+			return
+		}
+		schemas[i] = latest
+
+		break
+	}
+
+	t := h.schemaPageTemplate(r.Context(), registryAPI, registryName)
+	data := struct {
+		Registry       string
+		Registries     []string
+		SubjectName    string
+		IncludeDeleted bool
+		Schemas        []types.Schema
+		ReadOnly       bool
+	}{
+		Registry:       registryName,
+		Registries:     h.registryNames(),
+		SubjectName:    subjectName,
+		IncludeDeleted: includeDeleted,
+		Schemas:        schemas,
+		ReadOnly:       helpers.IsReadOnly(),
+	}
+
+	h.logger.Debug("HandleSchemaPage - Schema data",
+		"data", data)
+
+	t.Execute(w, data)
+}
+
+// Handler rendering the details of one version of a subject, loaded when its card is expanded
+func (h *handler) HandleSchemaVersion(w http.ResponseWriter, r *http.Request) {
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleSchemaVersion - Error resolving registry",
+			"error", err)
+
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	subjectName := r.URL.Query().Get("topic")
+	version, err := strconv.Atoi(r.URL.Query().Get("version"))
+	if subjectName == "" || helpers.CheckErr(err) {
+		h.logger.Debug("HandleSchemaVersion - Missing required parameters",
+			"error", "subjectName is an empty string or version is not a number")
+
+		http.Error(w, "Missing required parameters", http.StatusBadRequest)
+
+		return
+	}
+
+	// Soft-deleted versions are listed on the page when asked for, load them too
+	schema, err := registryAPI.GetSubjectVersion(r.Context(), subjectName, version, true)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleSchemaVersion - Error fetching version",
+			"error", err)
+
+		http.Error(w, registryErrorMessage(err), registryErrorStatus(err))
+
+		return
+	}
+
+	t := h.schemaPageTemplate(r.Context(), registryAPI, registryName)
+	if err := t.ExecuteTemplate(w, "schemaDetails", schema); helpers.CheckErr(err) {
+		h.logger.Debug("HandleSchemaVersion - Error rendering version",
+			"error", err)
+	}
+}
+
+// schemaPageTemplate parses the schema page template, which also holds the
+// "schemaDetails" template rendering the body of one version with its fingerprints
+func (h *handler) schemaPageTemplate(ctx context.Context, registryAPI registryAPICalls, registryName string) *template.Template {
 	funcMap := template.FuncMap{
 		"formatJSON": func(s string) string {
 			var result interface{}
-			if helpers.CheckErr(json.Unmarshal([]byte(s), &result)) {
-				h.logger.Debug("HandleSchemaPage - Error formatting JSON",
+			if err := json.Unmarshal([]byte(s), &result); helpers.CheckErr(err) {
+				h.logger.Debug("schemaPageTemplate - Error formatting JSON",
 					"error", err)
 
 				return s // Return original string if not valid JSON
 			}
 			formatted, err := json.MarshalIndent(result, "", "    ")
 			if helpers.CheckErr(err) {
-				h.logger.Error("HandleSchemaPage - Error formatting JSON",
+				h.logger.Error("schemaPageTemplate - Error formatting JSON",
 					"error", err)
 
 				return s // Return original string if formatting fails
 			}
 			return string(formatted)
 		},
-	}
+		"messageTypes": func(schema types.Schema) []string {
+			if schema.GetSchemaType() != types.SchemaTypeProtobuf {
+				return nil
+			}
+			references, err := registryAPI.ResolveReferences(ctx, schema)
+			if helpers.CheckErr(err) {
+				h.logger.Debug("schemaPageTemplate - Error resolving schema references",
+					"error", err)
 
-	t := template.Must(template.New("schema").Funcs(funcMap).Parse(schemaTemplate))
-	data := struct {
-		SubjectName string
-		Schemas     []types.Schema
-	}{
-		SubjectName: subjectName,
-		Schemas:     schemas,
-	}
+				return nil
+			}
+			messageTypes, err := helpers.ProtobufMessageTypes(schema.Schema, references)
+			if helpers.CheckErr(err) {
+				h.logger.Debug("schemaPageTemplate - Error listing message types",
+					"error", err)
 
-	h.logger.Debug("HandleSchemaPage - Schema data",
-		"data", data)
+				return nil
+			}
+			return messageTypes
+		},
+		"canonical": func(schema types.Schema) *schemaCanonical.Canonical {
+			return h.canonicalForm(ctx, registryAPI, registryName, schema)
+		},
+	}
 
-	t.Execute(w, data)
+	return template.Must(template.New("schema").Funcs(funcMap).Parse(schemaTemplate))
 }
 
 // Redirect handler for the test schema page to the appropriate handler based on the HTTP method
@@ -145,6 +340,17 @@ func (h *handler) HandleTestSchemaGet(w http.ResponseWriter, r *http.Request) {
 	subjectName := r.URL.Query().Get("topic")
 	version := r.URL.Query().Get("version")
 	id := r.URL.Query().Get("id")
+	messageType := r.URL.Query().Get("messageType")
+
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleTestSchemaGet - Error resolving registry",
+			"error", err)
+
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
 
 	if subjectName == "" || version == "" || id == "" {
 		h.logger.Debug("HandleTestSchemaGet - Missing required parameters",
@@ -155,35 +361,51 @@ func (h *handler) HandleTestSchemaGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get schemas for the subject
-	schemas, err := h.registryAPI.GetSchemas(subjectName)
+	versionInt, err := strconv.Atoi(version)
 	if helpers.CheckErr(err) {
-		h.logger.Debug("HandleTestSchemaGet - Error fetching schemas",
+		h.logger.Debug("HandleTestSchemaGet - Invalid version",
 			"error", err)
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Invalid version", http.StatusBadRequest)
 
 		return
 	}
 
-	// Find the specific schema version
-	var targetSchema types.Schema
-	for _, schema := range schemas {
-		if fmt.Sprintf("%d", schema.Version) == version && fmt.Sprintf("%d", schema.Id) == id {
-			targetSchema = schema
-			break
-		}
+	// Get the specific schema version
+	targetSchema, err := registryAPI.GetSubjectVersion(r.Context(), subjectName, versionInt, true)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleTestSchemaGet - Error fetching schema version",
+			"error", err)
+
+		h.sendPageError(w, registryAPI, registryName, err)
+
+		return
 	}
 
-	if targetSchema.Version == 0 {
+	if fmt.Sprintf("%d", targetSchema.Id) != id {
 		http.Error(w, "Schema not found", http.StatusNotFound)
 
 		h.logger.Debug("HandleTestSchemaGet - Schema not found",
-			"error", "targetSchema.Version is 0 which is invalid")
+			"error", "the version does not have the requested schema id")
 
 		return
 	}
 
+	// Protobuf payloads are validated against one of the messages of the schema
+	var messageTypes []string
+	if targetSchema.GetSchemaType() == types.SchemaTypeProtobuf {
+		references, err := registryAPI.ResolveReferences(r.Context(), targetSchema)
+		if helpers.CheckErr(err) {
+			h.logger.Debug("HandleTestSchemaGet - Error resolving schema references",
+				"error", err)
+		}
+		messageTypes, err = helpers.ProtobufMessageTypes(targetSchema.Schema, references)
+		if helpers.CheckErr(err) {
+			h.logger.Debug("HandleTestSchemaGet - Error listing message types",
+				"error", err)
+		}
+	}
+
 	// Define the funcMap for the template to pretty-print the JSON schema
 	funcMap := template.FuncMap{
 		"formatJSON": func(s string) string {
@@ -210,15 +432,30 @@ func (h *handler) HandleTestSchemaGet(w http.ResponseWriter, r *http.Request) {
 
 	t := template.Must(template.New("test").Funcs(funcMap).Parse(testSchemaTemplate))
 	data := struct {
-		SubjectName string
-		Version     string
-		SchemaID    string
-		Schema      string
+		Registry     string
+		Registries   []string
+		SubjectName  string
+		Version      string
+		SchemaID     string
+		SchemaType   string
+		Schema       string
+		MessageTypes []string
+		MessageType  string
+		ReadOnly     bool
+		// Mode applying to the subject, registration is disabled unless READWRITE
+		Mode string
 	}{
-		SubjectName: subjectName,
-		Version:     version,
-		SchemaID:    id,
-		Schema:      targetSchema.Schema,
+		Registry:     registryName,
+		Registries:   h.registryNames(),
+		SubjectName:  subjectName,
+		Version:      version,
+		SchemaID:     id,
+		SchemaType:   targetSchema.GetSchemaType(),
+		Schema:       targetSchema.Schema,
+		MessageTypes: messageTypes,
+		MessageType:  messageType,
+		ReadOnly:     helpers.IsReadOnly(),
+		Mode:         h.effectiveMode(r.Context(), registryAPI, subjectName),
 	}
 	h.logger.Debug("HandleTestSchemaGet - Schema data",
 		"data", data)
@@ -228,6 +465,23 @@ func (h *handler) HandleTestSchemaGet(w http.ResponseWriter, r *http.Request) {
 
 // Handler for testing the compatibility of a new schema against existing schema
 func (h *handler) HandleTestSchemaPost(w http.ResponseWriter, r *http.Request) {
+	registryAPI, _, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			&falseVal,
+			err.Error(),
+			http.StatusNotFound,
+			0,
+		)
+
+		h.logger.Debug("HandleTestSchemaPost - Error resolving registry",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusNotFound, response)
+
+		return
+	}
+
 	// Parse the request body
 	body, err := io.ReadAll(r.Body)
 	if helpers.CheckErr(err) {
@@ -250,6 +504,8 @@ func (h *handler) HandleTestSchemaPost(w http.ResponseWriter, r *http.Request) {
 		Version string      `json:"version"`
 		Id      string      `json:"id"`
 		JSON    interface{} `json:"json"`
+		// Versions to test against: the given version when empty, "latest" or "all"
+		Against string `json:"against"`
 	}
 	err = json.Unmarshal(body, &requestData)
 	if helpers.CheckErr(err) {
@@ -308,7 +564,26 @@ func (h *handler) HandleTestSchemaPost(w http.ResponseWriter, r *http.Request) {
 		"version", versionInt,
 		"json", requestData.JSON)
 
-	jsonString, err := json.Marshal(requestData.JSON)
+	// The schema being tested against decides the type of the proposed schema
+	existingSchema, err := registryAPI.GetSchema(r.Context(), requestData.Id)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error retrieving schema: %s", registryErrorMessage(err)),
+			registryErrorStatus(err),
+			registryErrorCode(err),
+		)
+
+		h.logger.Debug("HandleTestSchemaPost - Error retrieving schema",
+			"error", err)
+
+		helpers.SendJSONResponse(w, response.StatusCode, response)
+
+		return
+	}
+
+	// The proposed schema keeps the type and references of the schema it is tested against
+	proposed, err := proposedSchema(existingSchema, requestData.JSON)
 	if helpers.CheckErr(err) {
 		response := helpers.CreateResponseObject(
 			nil,
@@ -324,18 +599,52 @@ func (h *handler) HandleTestSchemaPost(w http.ResponseWriter, r *http.Request) {
 
 		return
 	}
-	// Test the schema
-	resp, err := h.registryAPI.TestSchema(requestData.Subject, versionInt, string(jsonString))
+
+	// Lint findings are reported with the compatibility result, they do not fail the test
+	lintReport := h.lintSchema(proposed)
+
+	// Test the schema, against several versions of the subject when asked to
+	var resp types.Response
+	switch requestData.Against {
+	case "":
+		resp, err = registryAPI.TestSchema(r.Context(), requestData.Subject, versionInt, proposed)
+	case "latest", "all":
+		resp, err = registryAPI.TestSchemaAgainstVersions(r.Context(), requestData.Subject, requestData.Against == "latest", proposed)
+	default:
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Unknown versions to test against: %s", requestData.Against),
+			http.StatusBadRequest,
+			http.StatusBadRequest,
+		)
+
+		h.logger.Debug("HandleTestSchemaPost - Unknown versions to test against",
+			"against", requestData.Against)
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
 	if helpers.CheckErr(err) {
 
 		h.logger.Debug("HandleTestSchemaPost - Error testing schema",
 			"error", err)
 
-		helpers.SendJSONResponse(w, http.StatusInternalServerError, resp)
+		// Timeouts and cancellations are reported as such rather than as a failed test.
+		// Errors reported by the registry already carry their code and message.
+		var registryErr *registryErrors.Error
+		if status := registryErrorStatus(err); status != http.StatusInternalServerError && !errors.As(err, &registryErr) {
+			resp = helpers.CreateResponseObject(nil, err.Error(), status, 0)
+		}
+		resp.Lint = lintReport
+
+		helpers.SendJSONResponse(w, registryErrorStatus(err), resp)
 
 		return
 	}
 
+	resp.Lint = lintReport
+
 	// Ensure message has a value
 	if resp.Message == "" {
 		resp.Message = "None"
@@ -348,10 +657,68 @@ func (h *handler) HandleTestSchemaPost(w http.ResponseWriter, r *http.Request) {
 	helpers.SendJSONResponse(w, resp.StatusCode, resp)
 }
 
+// proposedSchema builds the schema entered on the test schema page from the
+// request JSON. It takes the type and references of the existing schema it is
+// tested against. Protobuf schemas are sent as .proto text, other types as JSON documents.
+func proposedSchema(existing types.Schema, input any) (types.Schema, error) {
+	schemaType := existing.GetSchemaType()
+
+	schema, isText := input.(string)
+	if schemaType != types.SchemaTypeProtobuf || !isText {
+		jsonString, err := json.Marshal(input)
+		if err != nil {
+			return types.Schema{}, err
+		}
+		schema = string(jsonString)
+	}
+
+	return types.Schema{
+		SchemaType: schemaType,
+		Schema:     schema,
+		References: existing.References,
+	}, nil
+}
+
+// lintSchema lints a proposed schema with the configured rules. Schemas that cannot
+// be linted, e.g. Avro and Protobuf schemas, get a report saying why.
+func (h *handler) lintSchema(schema types.Schema) *types.LintReport {
+	report, err := schemaLint.Lint(schema.GetSchemaType(), schema.Schema, h.lintRules)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("lintSchema - Schema not linted",
+			"error", err)
+
+		skipped := err.Error()
+		if errors.Is(err, schemaLint.ErrUnsupportedSchemaType) {
+			skipped = fmt.Sprintf("No lint rules for %s schemas yet", schema.GetSchemaType())
+		}
+
+		return &types.LintReport{Findings: []types.LintFinding{}, Skipped: skipped}
+	}
+
+	return &report
+}
+
 // Handler for validating a payload against a schema
 func (h *handler) HandleValidatePayload(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 
+	registryAPI, _, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			&falseVal,
+			err.Error(),
+			http.StatusNotFound,
+			0,
+		)
+
+		h.logger.Debug("HandleValidatePayload - Error resolving registry",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusNotFound, response)
+
+		return
+	}
+
 	// Read and validate request body
 	body, err := io.ReadAll(r.Body)
 	if helpers.CheckErr(err) {
@@ -438,22 +805,41 @@ func (h *handler) HandleValidatePayload(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Get the schema
-	schema, err := h.registryAPI.GetSchema(id)
+	schema, err := registryAPI.GetSchema(r.Context(), id)
 	if helpers.CheckErr(err) {
 		response := helpers.CreateResponseObject(
 			&falseVal,
-			fmt.Sprintf("Error retrieving schema: %v", err),
-			http.StatusInternalServerError,
-			0,
+			fmt.Sprintf("Error retrieving schema: %s", registryErrorMessage(err)),
+			registryErrorStatus(err),
+			registryErrorCode(err),
 		)
 		h.logger.Debug("HandleValidatePayload - Error retrieving schema",
 			"error", err)
-		helpers.SendJSONResponse(w, http.StatusInternalServerError, response)
+		helpers.SendJSONResponse(w, response.StatusCode, response)
+
+		return
+	}
+
+	// Fetch the whole reference graph so that references to other subjects resolve
+	references, err := registryAPI.ResolveReferences(r.Context(), schema)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			&falseVal,
+			fmt.Sprintf("Error resolving schema references: %s", registryErrorMessage(err)),
+			registryErrorStatus(err),
+			registryErrorCode(err),
+		)
+		h.logger.Debug("HandleValidatePayload - Error resolving schema references",
+			"error", err)
+		helpers.SendJSONResponse(w, response.StatusCode, response)
 
 		return
 	}
 
-	isValid, errors, err := helpers.ValidatePayload(payload, schema)
+	// Protobuf schemas can define several messages, the first one is used by default
+	messageType, _ := unmarshalledBody["messageType"].(string)
+
+	isValid, errors, err := helpers.ValidatePayload(payload, schema, references, messageType)
 
 	if helpers.CheckErr(err) {
 		h.logger.Debug("HandleValidatePayload - Error validating payload",
@@ -500,8 +886,34 @@ func (h *handler) HandleValidatePayload(w http.ResponseWriter, r *http.Request)
 	helpers.SendJSONResponse(w, response.StatusCode, response)
 }
 
-// Handler for the health check endpoint
+// Handler for the health check endpoint. It reports the circuit breaker of each
+// registry, and stays 200 while registries are down as the board itself still works.
 func (h *handler) HandleHealthCheck(w http.ResponseWriter, r *http.Request) {
 	h.logger.Debug("HandleHealthCheck - Health check received")
-	w.WriteHeader(http.StatusOK)
+
+	type registryHealth struct {
+		Name    string              `json:"name"`
+		Breaker types.BreakerStatus `json:"breaker"`
+	}
+
+	status := "ok"
+	registries := make([]registryHealth, 0, len(h.registries))
+	for _, registry := range h.registries {
+		health := registryHealth{Name: registry.Name, Breaker: types.BreakerStatus{State: "closed"}}
+		if reporter, ok := registry.RegistryAPI.(breakerReporter); ok {
+			health.Breaker = reporter.BreakerStatus()
+		}
+		if health.Breaker.State != "closed" {
+			status = "degraded"
+		}
+		registries = append(registries, health)
+	}
+
+	helpers.SendJSONResponse(w, http.StatusOK, struct {
+		Status     string           `json:"status"`
+		Registries []registryHealth `json:"registries"`
+	}{
+		Status:     status,
+		Registries: registries,
+	})
 }
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"kafka-board/types"
+	"sync/atomic"
 )
 
 // For testing, we'll use a mock implementation of the schema validation
@@ -52,28 +54,126 @@ func (m *mockSchemaValidator) ValidatePayload(payload interface{}, schema types.
 // Mock registry API implementation
 type mockRegistryAPI struct {
 	mockSchema types.Schema
+	// Contexts listed by the registry, the default context when empty
+	contexts []string
+	// Every write, e.g. "register orders"
+	writes []string
+	// Subjects listed by the registry, each with mockSchema as its only version
+	subjects []string
+	// Number of ResolveReferences calls
+	referenceLookups atomic.Int32
+	// Error returned by GetMode, if any
+	modeErr error
 }
 
-func (m *mockRegistryAPI) ReturnSubjects() ([]string, error) {
+func (m *mockRegistryAPI) ReturnSubjects(ctx context.Context) ([]string, error) {
+	if len(m.subjects) > 0 {
+		return m.subjects, nil
+	}
 	return []string{}, nil
 }
 
-func (m *mockRegistryAPI) ReturnSubjectConfigs(subjectNames []string) ([]types.SubjectConfigInterface, error) {
+func (m *mockRegistryAPI) ReturnContexts(ctx context.Context) ([]string, error) {
+	if len(m.contexts) > 0 {
+		return m.contexts, nil
+	}
+	return []string{types.DefaultContext}, nil
+}
+
+func (m *mockRegistryAPI) ReturnSubjectConfigs(ctx context.Context, subjectNames []string) ([]types.SubjectConfigInterface, error) {
 	return []types.SubjectConfigInterface{}, nil
 }
 
-func (m *mockRegistryAPI) GetGlobalConfig() (types.GlobalConfig, error) {
+func (m *mockRegistryAPI) GetGlobalConfig(ctx context.Context) (types.GlobalConfig, error) {
 	return types.GlobalConfig{}, nil
 }
 
-func (m *mockRegistryAPI) GetSchemas(subjectName string) ([]types.Schema, error) {
+func (m *mockRegistryAPI) GetSchemas(ctx context.Context, subjectName string, includeDeleted bool) ([]types.Schema, error) {
 	return []types.Schema{m.mockSchema}, nil
 }
 
-func (m *mockRegistryAPI) TestSchema(subjectName string, version int, testJSON string) (types.Response, error) {
+func (m *mockRegistryAPI) GetSubjectVersion(ctx context.Context, subjectName string, version int, includeDeleted bool) (types.Schema, error) {
+	return m.mockSchema, nil
+}
+
+func (m *mockRegistryAPI) TestSchema(ctx context.Context, subjectName string, version int, proposed types.Schema) (types.Response, error) {
+	return types.Response{}, nil
+}
+
+func (m *mockRegistryAPI) TestSchemaAgainstVersions(ctx context.Context, subjectName string, latestOnly bool, proposed types.Schema) (types.Response, error) {
 	return types.Response{}, nil
 }
 
-func (m *mockRegistryAPI) GetSchema(id string) (types.Schema, error) {
+func (m *mockRegistryAPI) RegisterSchema(ctx context.Context, subjectName string, proposed types.Schema, normalize bool) (types.Response, error) {
+	m.writes = append(m.writes, "register "+subjectName)
+	return types.Response{Registered: &types.RegisteredSchema{Subject: subjectName, Id: m.mockSchema.Id + 1, Version: m.mockSchema.Version + 1}}, nil
+}
+
+func (m *mockRegistryAPI) UpdateCompatibilityLevel(ctx context.Context, subjectName string, level string) error {
+	m.writes = append(m.writes, "compatibility "+subjectName+" "+level)
+	return nil
+}
+
+func (m *mockRegistryAPI) DeleteCompatibilityLevel(ctx context.Context, subjectName string) error {
+	m.writes = append(m.writes, "delete compatibility "+subjectName)
+	return nil
+}
+
+func (m *mockRegistryAPI) PreviewCompatibilityLevel(ctx context.Context, subjectName string, level string) (types.LevelPreview, error) {
+	return types.LevelPreview{Level: level}, nil
+}
+
+func (m *mockRegistryAPI) GetMode(ctx context.Context, subjectName string) (types.SubjectMode, error) {
+	if m.modeErr != nil {
+		return types.SubjectMode{}, m.modeErr
+	}
+	return types.SubjectMode{Name: subjectName, Mode: types.ModeReadWrite}, nil
+}
+
+func (m *mockRegistryAPI) ReturnSubjectModes(ctx context.Context, subjectNames []string) ([]types.SubjectMode, error) {
+	return []types.SubjectMode{}, nil
+}
+
+func (m *mockRegistryAPI) ImportSchema(ctx context.Context, subjectName string, schema types.Schema) error {
+	m.writes = append(m.writes, "import "+subjectName)
+	return nil
+}
+
+func (m *mockRegistryAPI) UpdateConfig(ctx context.Context, subjectName string, config types.ConfigPayload) error {
+	m.writes = append(m.writes, "config "+subjectName)
+	return nil
+}
+
+func (m *mockRegistryAPI) UpdateMode(ctx context.Context, subjectName string, mode string, force bool) error {
+	m.writes = append(m.writes, "mode "+subjectName+" "+mode)
+	return nil
+}
+
+func (m *mockRegistryAPI) DeleteMode(ctx context.Context, subjectName string) error {
+	m.writes = append(m.writes, "delete mode "+subjectName)
+	return nil
+}
+
+func (m *mockRegistryAPI) DeleteSubject(ctx context.Context, subjectName string, permanent bool) ([]int, error) {
+	m.writes = append(m.writes, "delete "+subjectName)
+	return []int{m.mockSchema.Version}, nil
+}
+
+func (m *mockRegistryAPI) DeleteSubjectVersion(ctx context.Context, subjectName string, version int, permanent bool) error {
+	m.writes = append(m.writes, fmt.Sprintf("delete %s v%d", subjectName, version))
+	return nil
+}
+
+func (m *mockRegistryAPI) RestoreSubjectVersion(ctx context.Context, subjectName string, version int) (types.Response, error) {
+	m.writes = append(m.writes, fmt.Sprintf("restore %s v%d", subjectName, version))
+	return types.Response{Registered: &types.RegisteredSchema{Subject: subjectName, Id: m.mockSchema.Id, Version: m.mockSchema.Version + 1}}, nil
+}
+
+func (m *mockRegistryAPI) GetSchema(ctx context.Context, id string) (types.Schema, error) {
 	return m.mockSchema, nil
 }
+
+func (m *mockRegistryAPI) ResolveReferences(ctx context.Context, schema types.Schema) ([]types.ResolvedReference, error) {
+	m.referenceLookups.Add(1)
+	return nil, nil
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"kafka-board/helpers"
+	"kafka-board/types"
+)
+
+// Handler changing registry modes from the home page cards. PUT sets the mode of a
+// subject, or the global mode when no subject is given, DELETE resets a subject to
+// the global mode. Disabled in read-only mode.
+func (h *handler) HandleMode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if h.refuseCrossSiteWrite(w, r, "HandleMode") {
+		return
+	}
+
+	if h.refuseInReadOnlyMode(w, "HandleMode", "Changing registry modes") {
+		return
+	}
+
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			err.Error(),
+			http.StatusNotFound,
+			0,
+		)
+
+		h.logger.Debug("HandleMode - Error resolving registry",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusNotFound, response)
+
+		return
+	}
+
+	var requestData struct {
+		// Empty for the global mode
+		Subject string `json:"subject"`
+		Mode    string `json:"mode"`
+		// Needed to switch a subject or registry holding schemas to IMPORT
+		Force bool `json:"force"`
+	}
+
+	if r.Method == http.MethodDelete {
+		requestData.Subject = r.URL.Query().Get("subject")
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err == nil {
+			err = json.Unmarshal(body, &requestData)
+		}
+		if helpers.CheckErr(err) {
+			response := helpers.CreateResponseObject(
+				nil,
+				fmt.Sprintf("Error parsing JSON request: %v", err),
+				http.StatusBadRequest,
+				0,
+			)
+
+			h.logger.Debug("HandleMode - Error parsing JSON request",
+				"error", err)
+
+			helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+			return
+		}
+	}
+
+	if (r.Method == http.MethodDelete && requestData.Subject == "") || (r.Method == http.MethodPut && requestData.Mode == "") {
+		response := helpers.CreateResponseObject(
+			nil,
+			"Missing required fields",
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleMode - Missing required fields",
+			"error", "subject is empty on DELETE or mode is empty on PUT")
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	target := "the registry"
+	if requestData.Subject != "" {
+		target = requestData.Subject
+	}
+
+	var message string
+	if r.Method == http.MethodDelete {
+		err = registryAPI.DeleteMode(r.Context(), requestData.Subject)
+		message = fmt.Sprintf("%s now takes the global mode", target)
+	} else {
+		err = registryAPI.UpdateMode(r.Context(), requestData.Subject, requestData.Mode, requestData.Force)
+		message = fmt.Sprintf("Mode of %s set to %s", target, requestData.Mode)
+	}
+
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error changing the mode of %s: %s", target, registryErrorMessage(err)),
+			registryErrorStatus(err),
+			registryErrorCode(err),
+		)
+
+		h.logger.Debug("HandleMode - Error changing mode",
+			"error", err)
+
+		helpers.SendJSONResponse(w, response.StatusCode, response)
+
+		return
+	}
+
+	h.logger.Info("HandleMode - Mode changed",
+		"registry", registryName,
+		"method", r.Method,
+		"subject", requestData.Subject,
+		"mode", requestData.Mode,
+		"force", requestData.Force)
+
+	helpers.SendJSONResponse(w, http.StatusOK, helpers.CreateResponseObject(nil, message, http.StatusOK, 0))
+}
+
+// effectiveMode returns the mode applying to a subject, its own or the global one.
+// An empty mode is returned when it cannot be fetched, the registry then has the
+// final say on what is allowed.
+func (h *handler) effectiveMode(ctx context.Context, registryAPI registryAPICalls, subjectName string) string {
+	subjectMode, err := registryAPI.GetMode(ctx, subjectName)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("effectiveMode - Error fetching subject mode",
+			"subject", subjectName,
+			"error", err)
+
+		return ""
+	}
+
+	if !subjectMode.TakesGlobalDefault {
+		return subjectMode.Mode
+	}
+
+	globalMode, err := registryAPI.GetMode(ctx, "")
+	if helpers.CheckErr(err) {
+		h.logger.Debug("effectiveMode - Error fetching global mode",
+			"error", err)
+
+		return ""
+	}
+
+	return subjectMode.EffectiveMode(globalMode)
+}
+
+// blocksRegistration reports whether a mode refuses new schemas. Only READWRITE
+// takes them, IMPORT needs the ID of each schema.
+func blocksRegistration(mode string) bool {
+	return mode != "" && mode != types.ModeReadWrite
+}
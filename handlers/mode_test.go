@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kafka-board/schemaLint"
+)
+
+func TestHandleHomePageModeError(t *testing.T) {
+	registryAPI := &mockRegistryAPI{modeErr: errors.New(`mode "unknown"><img src=x onerror=alert(1)>`)}
+	h := ReturnHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), []Registry{{Name: "dev", RegistryAPI: registryAPI}}, schemaLint.RuleSet{})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	h.HandleHomePage(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	if strings.Contains(body, "<img src=x") {
+		t.Errorf("body holds the unescaped mode error")
+	}
+	if !strings.Contains(body, `title="mode &#34;unknown&#34;&gt;&lt;img src=x onerror=alert(1)&gt;"`) {
+		t.Errorf("body does not hold the escaped mode error in the title")
+	}
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"kafka-board/helpers"
+	"kafka-board/registryErrors"
+)
+
+// Handler registering the schema entered on the test schema page as a new version
+// of the subject. The registry API first checks the schema, as a dry run, under the
+// compatibility level configured for the subject and only registers it when
+// compatible. Disabled in read-only mode, and
+// refused while the subject or registry mode is not READWRITE.
+func (h *handler) HandleRegisterSchema(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if h.refuseCrossSiteWrite(w, r, "HandleRegisterSchema") {
+		return
+	}
+
+	if h.refuseInReadOnlyMode(w, "HandleRegisterSchema", "Registering schemas") {
+		return
+	}
+
+	registryAPI, registryName, err := h.registryForRequest(r)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			err.Error(),
+			http.StatusNotFound,
+			0,
+		)
+
+		h.logger.Debug("HandleRegisterSchema - Error resolving registry",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusNotFound, response)
+
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error reading request body: %v", err),
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleRegisterSchema - Error reading request body",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	var requestData struct {
+		Subject string `json:"subject"`
+		// ID of the schema the new one was tested against, giving its type and references
+		Id        string `json:"id"`
+		JSON      any    `json:"json"`
+		Normalize bool   `json:"normalize"`
+	}
+	if err := json.Unmarshal(body, &requestData); helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error parsing JSON request: %v", err),
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleRegisterSchema - Error parsing JSON request",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	if requestData.Subject == "" || requestData.Id == "" || requestData.JSON == nil {
+		response := helpers.CreateResponseObject(
+			nil,
+			"Missing required fields",
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleRegisterSchema - Missing required fields",
+			"error", "requestData.Subject, requestData.Id or requestData.JSON is empty")
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	if mode := h.effectiveMode(r.Context(), registryAPI, requestData.Subject); blocksRegistration(mode) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Not registered, %s is in %s mode and does not take new schemas", requestData.Subject, mode),
+			http.StatusUnprocessableEntity,
+			registryErrors.ErrOperationNotPermitted.Code,
+		)
+
+		h.logger.Debug("HandleRegisterSchema - Registration refused by the subject mode",
+			"subject", requestData.Subject,
+			"mode", mode)
+
+		helpers.SendJSONResponse(w, http.StatusUnprocessableEntity, response)
+
+		return
+	}
+
+	existingSchema, err := registryAPI.GetSchema(r.Context(), requestData.Id)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error retrieving schema: %s", registryErrorMessage(err)),
+			registryErrorStatus(err),
+			registryErrorCode(err),
+		)
+
+		h.logger.Debug("HandleRegisterSchema - Error retrieving schema",
+			"error", err)
+
+		helpers.SendJSONResponse(w, response.StatusCode, response)
+
+		return
+	}
+
+	proposed, err := proposedSchema(existingSchema, requestData.JSON)
+	if helpers.CheckErr(err) {
+		response := helpers.CreateResponseObject(
+			nil,
+			fmt.Sprintf("Error marshalling JSON: %v", err),
+			http.StatusBadRequest,
+			0,
+		)
+
+		h.logger.Debug("HandleRegisterSchema - Error marshalling JSON",
+			"error", err)
+
+		helpers.SendJSONResponse(w, http.StatusBadRequest, response)
+
+		return
+	}
+
+	resp, err := registryAPI.RegisterSchema(r.Context(), requestData.Subject, proposed, requestData.Normalize)
+	if helpers.CheckErr(err) {
+		h.logger.Debug("HandleRegisterSchema - Error registering schema",
+			"error", err)
+
+		// Timeouts and cancellations are reported as such. Errors reported by the
+		// registry, including a failed dry run, already carry their code and message.
+		var registryErr *registryErrors.Error
+		if status := registryErrorStatus(err); status != http.StatusInternalServerError && !errors.As(err, &registryErr) {
+			resp = helpers.CreateResponseObject(nil, err.Error(), status, 0)
+		}
+
+		helpers.SendJSONResponse(w, registryErrorStatus(err), resp)
+
+		return
+	}
+
+	h.logger.Info("HandleRegisterSchema - Schema registered",
+		"registry", registryName,
+		"subject", requestData.Subject,
+		"id", resp.Registered.Id,
+		"version", resp.Registered.Version)
+
+	helpers.SendJSONResponse(w, http.StatusOK, resp)
+}
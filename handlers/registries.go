@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"text/template"
+	"time"
+
+	"kafka-board/helpers"
+	"kafka-board/registryErrors"
+	"kafka-board/types"
+)
+
+// registryQueryParam is the query parameter carrying the current registry in every URL
+const registryQueryParam = "registry"
+
+// registryForRequest returns the registry named in the request URL, or the first
+// configured registry when the request does not name one
+func (h *handler) registryForRequest(r *http.Request) (registryAPICalls, string, error) {
+	if len(h.registries) == 0 {
+		return nil, "", fmt.Errorf("no registry configured")
+	}
+
+	name := r.URL.Query().Get(registryQueryParam)
+	if name == "" {
+		return h.registries[0].RegistryAPI, h.registries[0].Name, nil
+	}
+
+	for _, registry := range h.registries {
+		if registry.Name == name {
+			return registry.RegistryAPI, registry.Name, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("unknown registry: %s", name)
+}
+
+// registryNames lists the configured registries for the registry switcher
+func (h *handler) registryNames() []string {
+	names := make([]string, len(h.registries))
+	for i, registry := range h.registries {
+		names[i] = registry.Name
+	}
+	return names
+}
+
+// statusClientClosedRequest is the non-standard status logged when the client
+// went away before the response was ready
+const statusClientClosedRequest = 499
+
+// registryErrorStatus returns the HTTP status reported for a failed registry call
+func registryErrorStatus(err error) int {
+	var registryErr *registryErrors.Error
+	switch {
+	case errors.As(err, &registryErr):
+		return registryErr.HTTPStatus()
+	case errors.Is(err, helpers.ErrRequestTimedOut):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, helpers.ErrRequestCanceled):
+		return statusClientClosedRequest
+	case errors.Is(err, helpers.ErrRegistryUnavailable):
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
+
+// registryErrorMessage returns the message shown for a failed registry call.
+// Errors reported by the registry are described rather than shown as returned.
+func registryErrorMessage(err error) string {
+	var registryErr *registryErrors.Error
+	if errors.As(err, &registryErr) {
+		return registryErr.FriendlyMessage()
+	}
+
+	return err.Error()
+}
+
+// registryErrorCode returns the Confluent error code of a failed registry call,
+// or 0 when the registry did not report one
+func registryErrorCode(err error) int {
+	var registryErr *registryErrors.Error
+	if errors.As(err, &registryErr) {
+		return registryErr.Code
+	}
+
+	return 0
+}
+
+// breakerReporter is implemented by registry APIs guarded by a circuit breaker
+type breakerReporter interface {
+	BreakerStatus() types.BreakerStatus
+}
+
+// defaultRetryAfter is suggested to the browser when the breaker does not say
+// when the registry will be called again
+const defaultRetryAfter = 30 * time.Second
+
+// sendPageError reports a failed registry call of a page load. While the registry
+// is considered down, the registry unavailable page is shown instead of the error.
+func (h *handler) sendPageError(w http.ResponseWriter, registryAPI registryAPICalls, registryName string, err error) {
+	if !errors.Is(err, helpers.ErrRegistryUnavailable) {
+		http.Error(w, registryErrorMessage(err), registryErrorStatus(err))
+
+		return
+	}
+
+	retryAfter := defaultRetryAfter
+	if reporter, ok := registryAPI.(breakerReporter); ok {
+		if status := reporter.BreakerStatus(); status.OpenUntil != nil {
+			retryAfter = max(time.Until(*status.OpenUntil), time.Second)
+		}
+	}
+	retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+
+	t := template.Must(template.New("unavailable").Parse(unavailableTemplate))
+	data := struct {
+		Registry   string
+		Registries []string
+		RetryAfter int
+	}{
+		Registry:   registryName,
+		Registries: h.registryNames(),
+		RetryAfter: retryAfterSeconds,
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	t.Execute(w, data)
+}
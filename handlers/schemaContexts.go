@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"slices"
+
+	"kafka-board/types"
+)
+
+// contextGroup holds the subject configs of one schema context on the home page
+type contextGroup struct {
+	Context string
+	Configs []types.SubjectConfigInterface
+}
+
+// subjectsInContext keeps the subjects of a schema context, or every subject when
+// context is empty
+func subjectsInContext(subjects []string, context string) []string {
+	if context == "" {
+		return subjects
+	}
+
+	var inContext []string
+	for _, subject := range subjects {
+		if subjectContext, _ := types.SplitContext(subject); subjectContext == context {
+			inContext = append(inContext, subject)
+		}
+	}
+	return inContext
+}
+
+// groupByContext groups subject configs by schema context, in the order of contexts.
+// Contexts without subjects are left out, and contexts only seen in subject names
+// come last.
+func groupByContext(contexts []string, configs []types.SubjectConfigInterface) []contextGroup {
+	groups := make([]contextGroup, 0, len(contexts))
+	for _, context := range contexts {
+		groups = append(groups, contextGroup{Context: context})
+	}
+
+	for _, config := range configs {
+		context, _ := types.SplitContext(config.GetName())
+		i := slices.IndexFunc(groups, func(group contextGroup) bool { return group.Context == context })
+		if i < 0 {
+			groups = append(groups, contextGroup{Context: context})
+			i = len(groups) - 1
+		}
+		groups[i].Configs = append(groups[i].Configs, config)
+	}
+
+	return slices.DeleteFunc(groups, func(group contextGroup) bool { return len(group.Configs) == 0 })
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"kafka-board/schemaLint"
+)
+
+func TestHandleHomePageContext(t *testing.T) {
+	hostile := `.team"</script><script>alert(1)</script>`
+
+	tests := []struct {
+		name           string
+		context        string
+		expectedStatus int
+		expected       []string
+		unexpected     []string
+	}{
+		{
+			name:           "Known context - Escaped in the script and the markup",
+			context:        hostile,
+			expectedStatus: http.StatusOK,
+			expected: []string{
+				`const selectedContext = ".team\"\u003C/script\u003E\u003Cscript\u003Ealert(1)\u003C/script\u003E";`,
+				`in context .team&#34;&lt;/script&gt;&lt;script&gt;alert(1)&lt;/script&gt;`,
+			},
+			unexpected: []string{"<script>alert(1)"},
+		},
+		{
+			name:           "Unknown context - Not found",
+			context:        ".other",
+			expectedStatus: http.StatusNotFound,
+			unexpected:     []string{".other"},
+		},
+	}
+
+	h := ReturnHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), []Registry{
+		{Name: "dev", RegistryAPI: &mockRegistryAPI{contexts: []string{".", hostile}}},
+	}, schemaLint.RuleSet{})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/?context="+url.QueryEscape(tt.context), nil)
+			w := httptest.NewRecorder()
+
+			h.HandleHomePage(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.expectedStatus)
+			}
+			body := w.Body.String()
+			for _, expected := range tt.expected {
+				if !strings.Contains(body, expected) {
+					t.Errorf("body does not hold %q", expected)
+				}
+			}
+			for _, unexpected := range tt.unexpected {
+				if strings.Contains(body, unexpected) {
+					t.Errorf("body holds %q", unexpected)
+				}
+			}
+		})
+	}
+}
@@ -559,6 +559,54 @@ var homeTemplate string = `<!DOCTYPE html>
             margin-bottom: 12px;
         }
 
+        .level-form {
+            display: flex;
+            flex-wrap: wrap;
+            align-items: center;
+            gap: 10px;
+            margin-top: 15px;
+        }
+
+        .level-select {
+            padding: 8px 12px;
+            border: 1px solid var(--primary-light);
+            border-radius: 8px;
+            font-size: 0.95em;
+            color: var(--text-primary);
+            background-color: var(--card-background);
+        }
+
+        .level-button {
+            background: var(--primary-light);
+            color: var(--primary-dark);
+            border: none;
+            padding: 8px 16px;
+            border-radius: 20px;
+            cursor: pointer;
+            font-weight: 600;
+            transition: all var(--transition-speed) ease;
+        }
+
+        .level-button:hover {
+            transform: translateY(-1px);
+            box-shadow: 0 2px 4px var(--shadow-color);
+        }
+
+        .level-result {
+            width: 100%;
+            color: var(--text-secondary);
+            font-size: 0.95em;
+        }
+
+        .level-result ul {
+            margin: 8px 0 0 0;
+            padding-left: 20px;
+        }
+
+        .level-result .level-error {
+            color: #c0392b;
+        }
+
         .test-buttons-container {
             display: flex;
             justify-content: flex-start;
@@ -609,12 +657,84 @@ var homeTemplate string = `<!DOCTYPE html>
         .left-button, .right-button {
             margin-bottom: 15px;
         }
+
+        .config-error {
+            color: #e74c3c;
+            font-size: 0.9em;
+            word-break: break-word;
+        }
+
+        .registry-select {
+            padding: 8px 16px;
+            border: 1px solid var(--primary-color);
+            border-radius: 20px;
+            background-color: #e8f2f9;
+            color: #357abd;
+            font-weight: 600;
+            margin: 0 5px;
+            cursor: pointer;
+        }
+
+        .context-select {
+            padding: 10px 18px;
+            border: 2px solid var(--primary-light);
+            border-radius: 20px;
+            background-color: var(--card-background);
+            color: var(--primary-dark);
+            font-weight: 600;
+            margin-bottom: 15px;
+            cursor: pointer;
+        }
+
+        .fingerprint-results {
+            width: 80%;
+            margin-top: 15px;
+            padding: 15px 20px;
+            background: var(--card-background);
+            border-radius: 12px;
+            box-shadow: 0 2px 4px var(--shadow-color);
+            text-align: left;
+        }
+
+        .fingerprint-results a {
+            display: block;
+            padding: 4px 0;
+            color: var(--primary-dark);
+            font-weight: 600;
+            text-decoration: none;
+        }
+
+        .fingerprint-results .fingerprint-note {
+            color: var(--text-secondary);
+            font-style: italic;
+        }
+
+        .context-title {
+            display: flex;
+            align-items: center;
+            gap: 10px;
+            color: var(--primary-dark);
+            font-size: 1.2em;
+            margin: 25px 0 15px;
+        }
+
+        .context-count {
+            background: var(--primary-light);
+            border-radius: 12px;
+            padding: 2px 10px;
+            font-size: 0.8em;
+        }
     </style>
 </head>
 <body>
     <div class="header-container">
         <h1>✨ Kafka Schema & Payload Validator ✨</h1>
         <div class="header-stats">
+            {{if gt (len .Registries) 1}}
+            <select id="registrySelect" class="registry-select" onchange="switchRegistry(this.value)">
+                {{range .Registries}}<option value="{{.}}"{{if eq . $.Registry}} selected{{end}}>🗄️ {{.}}</option>{{end}}
+            </select>
+            {{end}}
             <a href="https://slack.com" target="_blank" class="slack-button" style="padding: 8px 20px; cursor: pointer; transition: all 0.3s ease; display: inline-block; background-color: #e8f2f9; color: #357abd; border-radius: 20px; box-shadow: 0 2px 4px rgba(0, 0, 0, 0.1); text-decoration: none; font-weight: 600; margin: 0 5px;">🔗 Slack</a>
             <a href="https://www.lemonde.fr" target="_blank" class="github-button" style="padding: 8px 20px; cursor: pointer; transition: all 0.3s ease; display: inline-block; background-color: #e8f2f9; color: #357abd; border-radius: 20px; box-shadow: 0 2px 4px rgba(0, 0, 0, 0.1); text-decoration: none; font-weight: 600; margin: 0 5px;">🐙 GitHub</a>
         </div>
@@ -622,9 +742,16 @@ var homeTemplate string = `<!DOCTYPE html>
     <div class="search-container">
         <div class="subject-counter">
             <span class="stat-icon">📊</span>
-            <span>{{len .Configs}} Subjects registered in the current cluster</span>
+            <span>{{len .Configs}} Subjects registered {{if .Context}}in context {{html .Context}}{{else}}in the current cluster{{end}}</span>
         </div>
-        <input type="text" id="searchInput" class="search-input" placeholder="Search subjects... 👀" onkeyup="filterSubjects()">
+        {{if gt (len .Contexts) 1}}
+        <select id="contextSelect" class="context-select" onchange="switchContext(this.value)">
+            <option value="">🗂️ All contexts</option>
+            {{range .Contexts}}<option value="{{html .}}"{{if eq . $.Context}} selected{{end}}>🗂️ {{if eq . "."}}Default context{{else}}{{html .}}{{end}}</option>{{end}}
+        </select>
+        {{end}}
+        <input type="text" id="searchInput" class="search-input" placeholder="Search subjects or fingerprints... 👀" onkeyup="filterSubjects()">
+        <div id="fingerprintResults" class="fingerprint-results hidden"></div>
     </div>
 
     <!-- Global Config Card -->
@@ -671,10 +798,44 @@ var homeTemplate string = `<!DOCTYPE html>
                 <span class="alias-tag">{{.GlobalConfig.Alias}}</span>
             </div>
         </div>
+        <div class="property">
+            <span class="property-label">Mode:</span>
+            <div class="property-value">
+                {{if .GlobalMode.Error}}
+                    <span class="icon-badge icon-badge-none" title="{{html .GlobalMode.Error}}">❓ Unavailable</span>
+                {{else if eq .GlobalMode.Mode "READWRITE"}}
+                    <span class="icon-badge icon-badge-true">✏️ Read-write</span>
+                {{else}}
+                    <span class="icon-badge icon-badge-false">🔒 {{.GlobalMode.Mode}}</span>
+                {{end}}
+            </div>
+        </div>
+        <div class="level-form" data-subject="" data-level="{{.GlobalConfig.CompatibilityLevel}}">
+            <select class="level-select">
+                {{range .Levels}}<option value="{{.}}">{{.}}</option>{{end}}
+            </select>
+            <button class="level-button" onclick="previewLevel(this)">Preview</button>
+            {{if not .ReadOnly}}
+            <button class="level-button" onclick="applyLevel(this)">Apply</button>
+            {{end}}
+            <div class="level-result hidden"></div>
+        </div>
+        {{if not .ReadOnly}}
+        <div class="level-form mode-form" data-subject="" data-mode="{{.GlobalMode.Mode}}">
+            <select class="level-select">
+                {{range .ModeNames}}<option value="{{.}}">{{.}}</option>{{end}}
+            </select>
+            <button class="level-button" onclick="applyMode(this)">Set mode</button>
+            <div class="level-result hidden"></div>
+        </div>
+        {{end}}
     </div>
 
     <!-- Subject Configs -->
     <div id="subjectConfigs">
+        {{range .ContextGroups}}
+        <div class="context-group" data-context="{{html .Context}}">
+        <h2 class="context-title">🗂️ {{if eq .Context "."}}Default context{{else}}{{html .Context}}{{end}} <span class="context-count">{{len .Configs}}</span></h2>
         {{range .Configs}}
         <div class="subject-card" data-name="{{.GetName}}">
             <div class="subject-name">
@@ -720,6 +881,17 @@ var homeTemplate string = `<!DOCTYPE html>
                     <span class="alias-tag">{{.Alias}}</span>
                 </div>
             </div>
+            {{else if eq (printf "%T" .) "types.SubjectConfigError"}}
+            <div class="property">
+                <span class="property-label">Status:</span>
+                <div class="property-value">
+                    <span class="icon-badge icon-badge-false">❌ Config unavailable</span>
+                </div>
+            </div>
+            <div class="property">
+                <span class="property-label">Error:</span>
+                <div class="property-value config-error">{{.Error | html}}</div>
+            </div>
             {{else}}
             <div class="property">
                 <span class="property-label">Status:</span>
@@ -760,11 +932,55 @@ var homeTemplate string = `<!DOCTYPE html>
                 </div>
             </div>
             {{end}}
+            {{with index $.Modes .GetName}}
+            <div class="property">
+                <span class="property-label">Mode:</span>
+                <div class="property-value">
+                    {{if .Error}}
+                        <span class="icon-badge icon-badge-none" title="{{html .Error}}">❓ Unavailable</span>
+                    {{else if .TakesGlobalDefault}}
+                        <span class="icon-badge icon-badge-none">🌍 Global ({{$.GlobalMode.Mode}})</span>
+                    {{else if eq .Mode "READWRITE"}}
+                        <span class="icon-badge icon-badge-true">✏️ Read-write</span>
+                    {{else}}
+                        <span class="icon-badge icon-badge-false">🔒 {{.Mode}}</span>
+                    {{end}}
+                </div>
+            </div>
+            {{end}}
+            <div class="level-form" data-subject="{{.GetName}}" data-level="{{if eq (printf "%T" .) "types.SubjectConfig"}}{{.CompatibilityLevel}}{{else}}{{$.GlobalConfig.CompatibilityLevel}}{{end}}">
+                <select class="level-select">
+                    {{range $.Levels}}<option value="{{.}}">{{.}}</option>{{end}}
+                </select>
+                <button class="level-button" onclick="previewLevel(this)">Preview</button>
+                {{if not $.ReadOnly}}
+                <button class="level-button" onclick="applyLevel(this)">Apply</button>
+                {{if eq (printf "%T" .) "types.SubjectConfig"}}
+                <button class="level-button" onclick="resetLevel(this)">Reset to global default</button>
+                {{end}}
+                {{end}}
+                <div class="level-result hidden"></div>
+            </div>
+            {{if not $.ReadOnly}}
+            {{$mode := index $.Modes .GetName}}
+            <div class="level-form mode-form" data-subject="{{.GetName}}" data-mode="{{if $mode.TakesGlobalDefault}}{{$.GlobalMode.Mode}}{{else}}{{$mode.Mode}}{{end}}">
+                <select class="level-select">
+                    {{range $.ModeNames}}<option value="{{.}}">{{.}}</option>{{end}}
+                </select>
+                <button class="level-button" onclick="applyMode(this)">Set mode</button>
+                {{if and (not $mode.TakesGlobalDefault) (not $mode.Error)}}
+                <button class="level-button" onclick="resetMode(this)">Reset to global mode</button>
+                {{end}}
+                <div class="level-result hidden"></div>
+            </div>
+            {{end}}
             <div class="test-buttons-container">
                 <button class="test-button" onclick="viewSchema('{{.GetName}}')">View Schema</button>
             </div>
         </div>
         {{end}}
+        </div>
+        {{end}}
     </div>
 
     <div id="no-results" class="no-results hidden">
@@ -804,12 +1020,232 @@ var homeTemplate string = `<!DOCTYPE html>
             return subjectEmojis[randomIndex];
         }
 
+        const registry = "{{.Registry}}";
+
+        function switchRegistry(registryName) {
+            window.location.href = '/?registry=' + encodeURIComponent(registryName);
+        }
+
+        const selectedContext = "{{js .Context}}";
+
+        function switchContext(contextName) {
+            let url = '/?registry=' + encodeURIComponent(registry);
+            if (contextName !== '') {
+                url += '&context=' + encodeURIComponent(contextName);
+            }
+            window.location.href = url;
+        }
+
         function viewSchema(topicName) {
-            window.location.href = '/schema/?topic=' + encodeURIComponent(topicName);
+            window.location.href = '/schema/?topic=' + encodeURIComponent(topicName) +
+                                   '&registry=' + encodeURIComponent(registry);
+        }
+
+        function levelTarget(form) {
+            return form.dataset.subject === '' ? 'the global level' : form.dataset.subject;
+        }
+
+        function showLevelResult(form, message, isError) {
+            const result = form.querySelector('.level-result');
+            result.replaceChildren();
+            const text = document.createElement('div');
+            text.textContent = message;
+            if (isError) {
+                text.className = 'level-error';
+            }
+            result.appendChild(text);
+            result.classList.remove('hidden');
+            return result;
+        }
+
+        // Lists the versions breaking the level, per subject
+        function displayPreview(form, preview) {
+            const result = showLevelResult(form, preview.message, false);
+            const list = document.createElement('ul');
+            (preview.subjects || []).forEach(subject => {
+                if (subject.skipped) {
+                    const item = document.createElement('li');
+                    item.textContent = subject.subject + ': ' + subject.skipped;
+                    list.appendChild(item);
+                }
+                subject.violations.forEach(violation => {
+                    const item = document.createElement('li');
+                    item.textContent = subject.subject + ' version ' + violation.version +
+                        ' is incompatible with version ' + violation.incompatibleWith.join(', ') + ': ' +
+                        violation.incompatibilities.map(i => i.type + ' ' + i.description).join('; ');
+                    list.appendChild(item);
+                });
+            });
+            if (list.children.length > 0) {
+                result.appendChild(list);
+            }
+        }
+
+        async function fetchPreview(form) {
+            const level = form.querySelector('.level-select').value;
+            const response = await fetch('/compatibility-level/preview?subject=' + encodeURIComponent(form.dataset.subject) +
+                                         '&level=' + encodeURIComponent(level) +
+                                         '&registry=' + encodeURIComponent(registry));
+            const data = await response.json();
+            if (!response.ok) {
+                throw new Error(data.message || 'Preview failed');
+            }
+            return data;
+        }
+
+        async function previewLevel(button) {
+            const form = button.closest('.level-form');
+            showLevelResult(form, 'Checking the existing versions...', false);
+            try {
+                displayPreview(form, await fetchPreview(form));
+            } catch (error) {
+                showLevelResult(form, error.message, true);
+            }
+        }
+
+        async function sendLevelChange(form, method, url, body) {
+            const response = await fetch(url, {
+                method: method,
+                headers: {'Content-Type': 'application/json'},
+                body: body ? JSON.stringify(body) : undefined
+            });
+            const data = await response.json();
+            if (!response.ok) {
+                showLevelResult(form, data.message || 'Request failed', true);
+                return;
+            }
+            showLevelResult(form, data.message + ', reloading...', false);
+            window.location.reload();
+        }
+
+        // Previews the level before asking to apply it
+        async function applyLevel(button) {
+            const form = button.closest('.level-form');
+            const level = form.querySelector('.level-select').value;
+            let preview;
+            try {
+                preview = await fetchPreview(form);
+            } catch (error) {
+                showLevelResult(form, error.message, true);
+                return;
+            }
+            displayPreview(form, preview);
+
+            if (!confirm('Set the compatibility level of ' + levelTarget(form) + ' to ' + level + '?\n\n' + preview.message)) {
+                return;
+            }
+            await sendLevelChange(form, 'PUT', '/compatibility-level?registry=' + encodeURIComponent(registry),
+                                  {subject: form.dataset.subject, level: level});
+        }
+
+        async function resetLevel(button) {
+            const form = button.closest('.level-form');
+            if (!confirm('Remove the compatibility level of ' + levelTarget(form) + ' so it takes the global default?')) {
+                return;
+            }
+            await sendLevelChange(form, 'DELETE', '/compatibility-level?subject=' + encodeURIComponent(form.dataset.subject) +
+                                  '&registry=' + encodeURIComponent(registry));
+        }
+
+        function modeTarget(form) {
+            return form.dataset.subject === '' ? 'the registry' : form.dataset.subject;
+        }
+
+        // IMPORT is forced, the registry refuses it otherwise once schemas exist
+        async function applyMode(button) {
+            const form = button.closest('.mode-form');
+            const mode = form.querySelector('.level-select').value;
+            let question = 'Set the mode of ' + modeTarget(form) + ' to ' + mode + '?';
+            if (mode === 'IMPORT') {
+                question += '\n\nNew schemas will only be taken with their IDs, as during a migration.';
+            } else if (mode !== 'READWRITE') {
+                question += '\n\nNew schemas will be refused until the mode is set back to READWRITE.';
+            }
+            if (!confirm(question)) {
+                return;
+            }
+            await sendLevelChange(form, 'PUT', '/mode?registry=' + encodeURIComponent(registry),
+                                  {subject: form.dataset.subject, mode: mode, force: mode === 'IMPORT'});
+        }
+
+        async function resetMode(button) {
+            const form = button.closest('.mode-form');
+            if (!confirm('Remove the mode of ' + modeTarget(form) + ' so it takes the global mode?')) {
+                return;
+            }
+            await sendLevelChange(form, 'DELETE', '/mode?subject=' + encodeURIComponent(form.dataset.subject) +
+                                  '&registry=' + encodeURIComponent(registry));
+        }
+
+        // Search terms that look like a SHA-256 or Rabin fingerprint, or a prefix of one
+        const fingerprintPattern = /^[0-9a-fA-F]{8,64}$/;
+        let fingerprintTimer;
+
+        function searchFingerprint(term) {
+            const results = document.getElementById('fingerprintResults');
+            clearTimeout(fingerprintTimer);
+            if (!fingerprintPattern.test(term)) {
+                results.classList.add('hidden');
+                return;
+            }
+
+            // Wait for the user to stop typing, the search reads every version
+            fingerprintTimer = setTimeout(async () => {
+                const url = '/fingerprint-search/?fingerprint=' + encodeURIComponent(term) +
+                            '&context=' + encodeURIComponent(selectedContext) +
+                            '&registry=' + encodeURIComponent(registry);
+                results.replaceChildren(fingerprintNote('Searching fingerprints...'));
+                results.classList.remove('hidden');
+
+                try {
+                    const response = await fetch(url);
+                    const data = await response.json();
+                    if (!response.ok) {
+                        throw new Error(data.message || 'Request failed');
+                    }
+                    if (document.getElementById('searchInput').value.trim() !== term) {
+                        return;
+                    }
+                    displayFingerprintMatches(results, data);
+                } catch (error) {
+                    results.replaceChildren(fingerprintNote('Fingerprint search failed: ' + error.message));
+                }
+            }, 400);
+        }
+
+        function fingerprintNote(text) {
+            const note = document.createElement('div');
+            note.className = 'fingerprint-note';
+            note.textContent = text;
+            return note;
+        }
+
+        function displayFingerprintMatches(results, data) {
+            results.replaceChildren();
+            if (data.matches.length === 0) {
+                results.appendChild(fingerprintNote('No version has this fingerprint'));
+            }
+            data.matches.forEach(match => {
+                const link = document.createElement('a');
+                link.href = '/schema/?topic=' + encodeURIComponent(match.subject) +
+                            '&registry=' + encodeURIComponent(registry);
+                link.textContent = '🔏 ' + match.subject + ' v' + match.version + ' (ID ' + match.id + ', ' + match.schemaType + ')';
+                results.appendChild(link);
+            });
+            if (data.skipped.length > 0) {
+                results.appendChild(fingerprintNote('Could not read ' + data.skipped.join(', ')));
+            }
+            if (data.partial) {
+                results.appendChild(fingerprintNote('The search timed out, ' + data.remaining + ' subjects were not searched'));
+            }
+            if (data.matches.length > 0) {
+                document.getElementById('no-results').classList.add('hidden');
+            }
         }
 
         function filterSubjects() {
             const input = document.getElementById('searchInput');
+            searchFingerprint(input.value.trim());
             const filter = input.value.toUpperCase();
             const subjectConfigs = document.getElementById('subjectConfigs');
             const globalConfig = document.getElementById('globalConfig');
@@ -821,7 +1257,7 @@ var homeTemplate string = `<!DOCTYPE html>
             subjectConfigs.style.display = 'none';
             noResults.classList.add('hidden');
             
-            if (filter === '') {
+            if (filter === '' && selectedContext === '') {
                 // If no search term, only show global config
                 globalConfig.style.display = 'block';
                 return;
@@ -844,6 +1280,12 @@ var homeTemplate string = `<!DOCTYPE html>
                 }
             }
 
+            // Hide the contexts without a matching subject
+            document.querySelectorAll('.context-group').forEach(group => {
+                const visible = [...group.getElementsByClassName('subject-card')].some(card => card.style.display !== 'none');
+                group.style.display = visible ? '' : 'none';
+            });
+
             if (visibleCount === 0) {
                 noResults.classList.remove('hidden');
             } else {
@@ -854,6 +1296,13 @@ var homeTemplate string = `<!DOCTYPE html>
         // Call filterSubjects on page load to set initial state
         document.addEventListener('DOMContentLoaded', function() {
             filterSubjects();
+            document.querySelectorAll('.level-form').forEach(form => {
+                const select = form.querySelector('.level-select');
+                const current = form.dataset.level || form.dataset.mode;
+                if ([...select.options].some(option => option.value === current)) {
+                    select.value = current;
+                }
+            });
             const subjectCards = document.querySelectorAll('.subject-card:not(.global-config)');
             subjectCards.forEach(card => {
                 const emojiSpan = card.querySelector('.subject-emoji');
@@ -1080,6 +1529,23 @@ var schemaTemplate string = `<!DOCTYPE html>
             color: #f39c12;
         }
 
+        .icon-badge-fingerprint {
+            background-color: #eceff1;
+            color: #546e7a;
+            font-family: 'Consolas', 'Monaco', 'Courier New', monospace;
+            word-break: break-all;
+        }
+
+        .fingerprints {
+            flex-wrap: wrap;
+        }
+
+        .canonical-form summary {
+            cursor: pointer;
+            color: var(--text-secondary);
+            font-weight: 600;
+        }
+
         .icon-badge-subject {
             background-color: #f3e5f5;
             color: #9b59b6;
@@ -1165,59 +1631,364 @@ var schemaTemplate string = `<!DOCTYPE html>
             width: auto;
         }
 
+        .message-type-select {
+            padding: 4px 8px;
+            border: 1px solid var(--primary-color);
+            border-radius: 12px;
+            background-color: var(--primary-light);
+            color: var(--primary-dark);
+            font-weight: 600;
+        }
+
+        .registry-select {
+            padding: 8px 16px;
+            border: 1px solid var(--primary-color);
+            border-radius: 20px;
+            background-color: #e8f2f9;
+            color: #357abd;
+            font-weight: 600;
+            margin: 0 5px;
+            cursor: pointer;
+        }
+
+        .versions-toolbar {
+            max-width: 800px;
+            margin: 0 auto 20px;
+            color: var(--text-secondary);
+        }
+
+        .deleted-card {
+            opacity: 0.7;
+            border-style: dashed;
+        }
+
+        .icon-badge-deleted {
+            background-color: #ffebee;
+            color: #e74c3c;
+            border: 1px solid #e74c3c;
+        }
+
+        .danger-button {
+            padding: 8px 20px;
+            border: 1px solid #e74c3c;
+            border-radius: 20px;
+            background-color: #ffebee;
+            color: #c0392b;
+            font-weight: 600;
+            cursor: pointer;
+            margin-right: 10px;
+        }
+
+        .restore-button {
+            padding: 8px 20px;
+            border: 1px solid var(--primary-color);
+            border-radius: 20px;
+            background-color: var(--primary-light);
+            color: var(--primary-dark);
+            font-weight: 600;
+            cursor: pointer;
+            margin-right: 10px;
+        }
+
+        .subject-actions, .version-actions {
+            margin-top: 15px;
+        }
+
+        .compare-versions {
+            margin-top: 15px;
+        }
+
+        .compare-versions select {
+            padding: 6px 12px;
+            border: 1px solid var(--primary-color);
+            border-radius: 20px;
+            background-color: var(--primary-light);
+            color: var(--primary-dark);
+            font-weight: 600;
+        }
+
+        .action-result {
+            margin-top: 10px;
+            font-weight: 600;
+        }
+
+        .action-result.action-error {
+            color: #c0392b;
+        }
+
+        .load-schema-button {
+            padding: 8px 20px;
+            border: 1px solid var(--primary-color);
+            border-radius: 20px;
+            background-color: var(--primary-light);
+            color: var(--primary-dark);
+            font-weight: 600;
+            cursor: pointer;
+        }
+
     </style>
 </head>
 <body>
     <div class="header-container">
-        <a href="/" class="back-button">Back to Dashboard</a>
+        <a href="/?registry={{.Registry}}" class="back-button">Back to Dashboard</a>
         <h1>✨ Kafka Schema & Payload Validator ✨</h1>
         <div class="header-stats">
+            {{if gt (len .Registries) 1}}
+            <select id="registrySelect" class="registry-select" onchange="switchRegistry(this.value)">
+                {{range .Registries}}<option value="{{.}}"{{if eq . $.Registry}} selected{{end}}>🗄️ {{.}}</option>{{end}}
+            </select>
+            {{end}}
             <a href="https://slack.com" target="_blank" class="slack-button" style="padding: 8px 20px; cursor: pointer; transition: all 0.3s ease; display: inline-block; background-color: #e8f2f9; color: #357abd; border-radius: 20px; box-shadow: 0 2px 4px rgba(0, 0, 0, 0.1); text-decoration: none; font-weight: 600; margin: 0 5px;">🔗 Slack</a>
             <a href="https://www.lemonde.fr" target="_blank" class="github-button" style="padding: 8px 20px; cursor: pointer; transition: all 0.3s ease; display: inline-block; background-color: #e8f2f9; color: #357abd; border-radius: 20px; box-shadow: 0 2px 4px rgba(0, 0, 0, 0.1); text-decoration: none; font-weight: 600; margin: 0 5px;">🐙 GitHub</a>
         </div>
     </div>
+    <div class="versions-toolbar">
+        <label>
+            <input type="checkbox" id="showDeleted" onchange="toggleDeleted(this.checked)"{{if .IncludeDeleted}} checked{{end}}>
+            Show soft-deleted versions
+        </label>
+        {{if not .ReadOnly}}
+        <div class="subject-actions">
+            <button class="danger-button" onclick="deleteSchema(0, false)">🗑️ Delete subject</button>
+            {{if .IncludeDeleted}}
+            <button class="danger-button" onclick="deleteSchema(0, true)">💥 Delete subject permanently</button>
+            {{end}}
+        </div>
+        {{end}}
+        {{if gt (len .Schemas) 1}}
+        <div class="compare-versions">
+            Compare
+            <select id="diffFrom">
+                {{range $i, $schema := .Schemas}}<option value="{{.Version}}"{{if eq (len (slice $.Schemas $i)) 2}} selected{{end}}>v{{.Version}}</option>{{end}}
+            </select>
+            with
+            <select id="diffTo">
+                {{range $i, $schema := .Schemas}}<option value="{{.Version}}"{{if eq (len (slice $.Schemas $i)) 1}} selected{{end}}>v{{.Version}}</option>{{end}}
+            </select>
+            <button class="restore-button" onclick="compareVersions()">🔍 Show diff</button>
+        </div>
+        {{end}}
+        <div id="actionResult" class="action-result"></div>
+    </div>
     {{range .Schemas}}
-    <div class="schema-card">
+    <div class="schema-card{{if .Deleted}} deleted-card{{end}}" data-version="{{.Version}}">
         <div class="test-buttons-container">
             <div class="left-button">
-                <button class="test-button" onclick="testSchema('{{$.SubjectName}}', {{.Version}}, {{.Id}})">Test against this schema</button>
+                <button class="test-button" onclick="testSchema('{{$.SubjectName}}', {{.Version}}, this)">Test against this schema</button>
             </div>
         </div>
         <div class="property">
             <span class="property-label">Version:</span>
             <div class="property-value">
                 <span class="icon-badge icon-badge-version">🔢 {{.Version}}</span>
+                {{if .Deleted}}<span class="icon-badge icon-badge-deleted">🗑️ Soft-deleted</span>{{end}}
             </div>
         </div>
+        {{if not $.ReadOnly}}
+        <div class="version-actions">
+            {{if .Deleted}}
+            <button class="restore-button" onclick="restoreVersion({{.Version}})">♻️ Restore</button>
+            <button class="danger-button" onclick="deleteSchema({{.Version}}, true)">💥 Delete permanently</button>
+            {{else}}
+            <button class="danger-button" onclick="deleteSchema({{.Version}}, false)">🗑️ Delete version</button>
+            {{end}}
+        </div>
+        {{end}}
+        <div class="schema-details">
+            {{if .Schema}}
+            {{template "schemaDetails" .}}
+            {{else}}
+            <button class="load-schema-button" onclick="loadSchema(this)">Show schema</button>
+            {{end}}
+        </div>
+    </div>
+    {{end}}
+    <div class="footer">
+        <p>🚀 Global Commerce - Vidar</p>
+    </div>
+    {{define "schemaDetails"}}
         <div class="property">
             <span class="property-label">ID:</span>
             <div class="property-value">
-                <span class="icon-badge icon-badge-id">🆔 {{.Id}}</span>
+                <span class="icon-badge icon-badge-id" data-schema-id="{{.Id}}">🆔 {{.Id}}</span>
             </div>
         </div>
         <div class="property">
             <span class="property-label">Schema Type:</span>
             <div class="property-value">
-                <span class="icon-badge icon-badge-type">📝 {{.SchemaType}}</span>
+                <span class="icon-badge icon-badge-type">📝 {{.GetSchemaType}}</span>
+            </div>
+        </div>
+        {{with messageTypes .}}
+        <div class="property">
+            <span class="property-label">Message Type:</span>
+            <div class="property-value">
+                <select class="message-type-select">
+                    {{range .}}<option value="{{.}}">{{.}}</option>{{end}}
+                </select>
             </div>
         </div>
+        {{end}}
         <div class="property">
             <span class="property-label">Schema:</span>
             <div class="schema-content">
                 <pre> {{.Schema | formatJSON | html}}</pre>
             </div>
         </div>
-    </div>
+        {{with canonical .}}
+        <div class="property">
+            <span class="property-label">Fingerprints:</span>
+            <div class="property-value fingerprints">
+                <span class="icon-badge icon-badge-fingerprint" title="SHA-256 of the canonical form">🔏 SHA-256 {{.SHA256}}</span>
+                {{if .Rabin}}<span class="icon-badge icon-badge-fingerprint" title="Rabin (CRC-64-AVRO) fingerprint of the Parsing Canonical Form">🔏 Rabin {{.Rabin}}</span>{{end}}
+            </div>
+        </div>
+        <div class="property">
+            <span class="property-label">Canonical:</span>
+            <details class="canonical-form">
+                <summary>Show canonical form</summary>
+                <pre>{{html .Form}}</pre>
+            </details>
+        </div>
+        {{end}}
     {{end}}
-    <div class="footer">
-        <p>🚀 Global Commerce - Vidar</p>
-    </div>
 
     <script>
-        function testSchema(subjectName, version, id) {
-            window.location.href = '/test-schema/?topic=' + encodeURIComponent(subjectName) + 
-                                 '&version=' + encodeURIComponent(version) + 
-                                 '&id=' + encodeURIComponent(id);
+        const registry = "{{.Registry}}";
+
+        function switchRegistry(registryName) {
+            window.location.href = '/schema/?topic=' + encodeURIComponent("{{.SubjectName}}") +
+                                   '&registry=' + encodeURIComponent(registryName);
+        }
+
+        const subjectName = "{{.SubjectName}}";
+
+        function compareVersions() {
+            const from = document.getElementById('diffFrom').value;
+            const to = document.getElementById('diffTo').value;
+            window.location.href = '/schema-diff/?topic=' + encodeURIComponent(subjectName) +
+                                   '&from=' + encodeURIComponent(from) + '&to=' + encodeURIComponent(to) +
+                                   '&registry=' + encodeURIComponent(registry);
+        }
+
+        function showActionResult(message, isError) {
+            const result = document.getElementById('actionResult');
+            result.textContent = message;
+            result.classList.toggle('action-error', isError);
+            result.scrollIntoView({behavior: 'smooth', block: 'center'});
+        }
+
+        async function postAction(url, body) {
+            const response = await fetch(url + '?registry=' + encodeURIComponent(registry), {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify(body)
+            });
+            const data = await response.json();
+            if (!response.ok) {
+                throw new Error(data.message || 'Request failed');
+            }
+            return data;
+        }
+
+        // Deletes need the subject name typed again, version 0 deletes the whole subject
+        async function deleteSchema(version, permanent) {
+            const target = version === 0 ? 'every version of ' + subjectName : 'version ' + version + ' of ' + subjectName;
+            const action = (permanent ? 'Permanently delete ' : 'Soft-delete ') + target + '?' +
+                (permanent ? ' This cannot be undone.' : ' Soft-deleted versions can be restored.');
+            const typed = prompt(action + '\n\nType ' + subjectName + ' to confirm.');
+            if (typed === null) {
+                return;
+            }
+            if (typed !== subjectName) {
+                showActionResult('The subject name does not match, nothing was deleted', true);
+                return;
+            }
+
+            try {
+                const data = await postAction('/delete-schema', {subject: subjectName, version: version, permanent: permanent, confirm: typed});
+                showActionResult(data.message + ', reloading...', false);
+                if (version === 0 && permanent) {
+                    window.location.href = '/?registry=' + encodeURIComponent(registry);
+                } else {
+                    toggleDeleted(true);
+                }
+            } catch (error) {
+                showActionResult(error.message, true);
+            }
+        }
+
+        async function restoreVersion(version) {
+            if (!confirm('Restore version ' + version + ' of ' + subjectName + '? Its schema is registered again as a new version.')) {
+                return;
+            }
+
+            try {
+                const data = await postAction('/restore-version', {subject: subjectName, version: version});
+                showActionResult(data.message + ', reloading...', false);
+                toggleDeleted(true);
+            } catch (error) {
+                showActionResult(error.message, true);
+            }
+        }
+
+        function toggleDeleted(showDeleted) {
+            let url = '/schema/?topic=' + encodeURIComponent("{{.SubjectName}}") +
+                      '&registry=' + encodeURIComponent(registry);
+            if (showDeleted) {
+                url += '&deleted=true';
+            }
+            window.location.href = url;
+        }
+
+        // Schema bodies are only fetched when a version is expanded
+        async function loadSchema(buttonElement) {
+            const details = buttonElement.closest('.schema-details');
+            const card = buttonElement.closest('.schema-card');
+            const url = '/schema-version/?topic=' + encodeURIComponent("{{.SubjectName}}") +
+                        '&version=' + encodeURIComponent(card.dataset.version) +
+                        '&registry=' + encodeURIComponent(registry);
+
+            buttonElement.textContent = 'Loading...';
+            buttonElement.disabled = true;
+
+            try {
+                const response = await fetch(url);
+                if (!response.ok) {
+                    throw new Error(await response.text());
+                }
+                details.innerHTML = await response.text();
+                return true;
+            } catch (error) {
+                console.error("Error loading schema:", error);
+                buttonElement.textContent = 'Could not load schema, retry';
+                buttonElement.disabled = false;
+                return false;
+            }
+        }
+
+        async function testSchema(subjectName, version, buttonElement) {
+            const card = buttonElement.closest('.schema-card');
+
+            // The schema ID is only known once the version is loaded
+            const loadButton = card.querySelector('.load-schema-button');
+            if (loadButton && !(await loadSchema(loadButton))) {
+                return;
+            }
+            const id = card.querySelector('[data-schema-id]').dataset.schemaId;
+
+            let url = '/test-schema/?topic=' + encodeURIComponent(subjectName) + 
+                      '&version=' + encodeURIComponent(version) + 
+                      '&id=' + encodeURIComponent(id) +
+                      '&registry=' + encodeURIComponent(registry);
+
+            // Protobuf schemas can define several messages, pass on the chosen one
+            const messageTypeSelect = card.querySelector('.message-type-select');
+            if (messageTypeSelect) {
+                url += '&messageType=' + encodeURIComponent(messageTypeSelect.value);
+            }
+
+            window.location.href = url;
         }
     </script>
 </body>
@@ -1431,6 +2202,110 @@ var testSchemaTemplate string = `<!DOCTYPE html>
             color: var(--text-secondary);
         }
 
+        .version-scope {
+            padding: 8px 16px;
+            border: 1px solid var(--primary-color);
+            border-radius: 20px;
+            background-color: var(--primary-light);
+            color: var(--primary-dark);
+            font-weight: 600;
+        }
+
+        .register-form {
+            display: flex;
+            align-items: center;
+            gap: 15px;
+            width: 100%;
+            margin-top: 15px;
+        }
+
+        .register-form label {
+            color: var(--text-secondary);
+        }
+
+        .submit-button:disabled {
+            opacity: 0.5;
+            cursor: not-allowed;
+            transform: none;
+        }
+
+        .version-matrix {
+            width: 100%;
+            margin-top: 10px;
+            border-collapse: collapse;
+            background: #ffffff;
+        }
+
+        .version-matrix th, .version-matrix td {
+            padding: 8px 12px;
+            border-bottom: 1px solid #e0e6ed;
+            text-align: left;
+            vertical-align: top;
+            overflow-wrap: anywhere;
+        }
+
+        .version-matrix th {
+            color: var(--text-secondary);
+            background: var(--primary-light);
+        }
+
+        .version-matrix ul {
+            margin: 0;
+            padding-left: 18px;
+        }
+
+        .incompatibility-list {
+            margin: 10px 0 0;
+            padding-left: 0;
+            list-style: none;
+        }
+
+        .incompatibility-item {
+            margin-bottom: 10px;
+            padding: 10px 14px;
+            background: #fff5f5;
+            border-left: 3px solid #e74c3c;
+            border-radius: 6px;
+            overflow-wrap: anywhere;
+        }
+
+        .incompatibility-type {
+            font-weight: 600;
+            color: #c0392b;
+            margin-right: 8px;
+        }
+
+        .incompatibility-path {
+            font-family: monospace;
+            background: var(--primary-light);
+            color: var(--primary-dark);
+            padding: 2px 6px;
+            border-radius: 4px;
+        }
+
+        .incompatibility-description {
+            margin-top: 6px;
+            color: var(--text-primary);
+        }
+
+        .lint-item.lint-warning {
+            background: #fffaf0;
+            border-left-color: #e67e22;
+        }
+
+        .lint-item.lint-info {
+            background: var(--primary-light);
+            border-left-color: var(--primary-color);
+        }
+
+        .lint-item.lint-warning .incompatibility-type {
+            color: #d35400;
+        }
+
+        .lint-item.lint-info .incompatibility-type {
+            color: var(--primary-dark);
+        }
+
         .footer {
             position: fixed;
             bottom: 0;
@@ -1648,13 +2523,38 @@ var testSchemaTemplate string = `<!DOCTYPE html>
             display: flex;
             align-items: flex-start;
         }
+
+        .message-type-select {
+            padding: 4px 8px;
+            border: 1px solid var(--primary-color);
+            border-radius: 12px;
+            background-color: var(--primary-light);
+            color: var(--primary-dark);
+            font-weight: 600;
+        }
+
+        .registry-select {
+            padding: 8px 16px;
+            border: 1px solid var(--primary-color);
+            border-radius: 20px;
+            background-color: #e8f2f9;
+            color: #357abd;
+            font-weight: 600;
+            margin: 0 5px;
+            cursor: pointer;
+        }
     </style>
 </head>
 <body>
     <div class="header-container">
-        <a href="/schema/?topic={{.SubjectName}}" class="back-button">Back to Schema View</a>
+        <a href="/schema/?topic={{urlquery .SubjectName}}&registry={{.Registry}}" class="back-button">Back to Schema View</a>
         <h1>✨ Kafka Schema Dashboard ✨</h1>
         <div class="header-stats">
+            {{if gt (len .Registries) 1}}
+            <select id="registrySelect" class="registry-select" onchange="switchRegistry(this.value)">
+                {{range .Registries}}<option value="{{.}}"{{if eq . $.Registry}} selected{{end}}>🗄️ {{.}}</option>{{end}}
+            </select>
+            {{end}}
             <a href="https://slack.com" target="_blank" class="slack-button" style="padding: 8px 20px; cursor: pointer; transition: all 0.3s ease; display: inline-block; background-color: #e8f2f9; color: #357abd; border-radius: 20px; box-shadow: 0 2px 4px rgba(0, 0, 0, 0.1); text-decoration: none; font-weight: 600; margin: 0 5px;">🔗 Slack</a>
             <a href="https://www.lemonde.fr" target="_blank" class="github-button" style="padding: 8px 20px; cursor: pointer; transition: all 0.3s ease; display: inline-block; background-color: #e8f2f9; color: #357abd; border-radius: 20px; box-shadow: 0 2px 4px rgba(0, 0, 0, 0.1); text-decoration: none; font-weight: 600; margin: 0 5px;">🐙 GitHub</a>
         </div>
@@ -1674,6 +2574,18 @@ var testSchemaTemplate string = `<!DOCTYPE html>
                 <span class="info-label">ID:</span>
                 <span class="icon-badge icon-badge-id">🆔 {{.SchemaID}}</span>
             </div>
+            <div class="info-item">
+                <span class="info-label">Type:</span>
+                <span class="icon-badge icon-badge-type">📝 {{.SchemaType}}</span>
+            </div>
+            {{if .MessageTypes}}
+            <div class="info-item">
+                <span class="info-label">Message Type:</span>
+                <select id="messageType" class="message-type-select">
+                    {{range .MessageTypes}}<option value="{{.}}"{{if eq . $.MessageType}} selected{{end}}>{{.}}</option>{{end}}
+                </select>
+            </div>
+            {{end}}
         </div>
         <div class="property" style="width: 100%">
             <span class="property-label">Schema:</span>
@@ -1685,11 +2597,26 @@ var testSchemaTemplate string = `<!DOCTYPE html>
             <div class="property" style="width: 100%; margin-bottom: 10px;">
                 <span class="property-label">Enter JSON to test compatibility 📝</span>
             </div>
-            <textarea id="testJson" placeholder="Paste your JSON here..."></textarea>
+            <textarea id="testJson" placeholder="{{if eq .SchemaType "PROTOBUF"}}Paste your .proto schema or JSON payload here...{{else}}Paste your JSON here...{{end}}"></textarea>
             <div class="buttons-container">
+                <select id="versionScope" class="version-scope" title="Versions the new schema is tested against">
+                    <option value="">This version (v{{.Version}})</option>
+                    <option value="latest">Latest version</option>
+                    <option value="all">All versions (transitive)</option>
+                </select>
                 <button id="testButton" class="submit-button">Test compatibility of new schema against this schema</button>
                 <button id="testButton2" class="submit-button">Test compatibility of payload against this schema</button>
             </div>
+            {{if and (not .ReadOnly) (or (eq .Mode "") (eq .Mode "READWRITE"))}}
+            <div class="register-form">
+                <button id="registerButton" class="submit-button" disabled title="Test the new schema first, it can be registered once compatible">Register as a new version of {{.SubjectName}}</button>
+                <label><input type="checkbox" id="normalize"> Normalize the schema</label>
+            </div>
+            {{else if not .ReadOnly}}
+            <div class="register-form">
+                <label>🔒 {{.SubjectName}} is in {{.Mode}} mode, new schemas cannot be registered</label>
+            </div>
+            {{end}}
         </div>
         <div id="resultContainer" class="result-container">
             <div class="result-title">Compatibility Test Results 📊</div>
@@ -1709,6 +2636,28 @@ var testSchemaTemplate string = `<!DOCTYPE html>
                 <span class="result-label">Message:</span>
                 <span id="messageResult"></span>
             </div>
+            <div id="registeredResult" class="result-item" style="display: none;">
+                <span class="result-label">Registered:</span>
+                <a id="registeredLink"></a>
+            </div>
+            <div id="versionsResult" style="display: none;">
+                <span class="result-label">Versions:</span>
+                <table class="version-matrix">
+                    <thead>
+                        <tr><th>Version</th><th>Compatibility</th><th>Error Code</th><th>Reasons</th></tr>
+                    </thead>
+                    <tbody id="versionMatrix"></tbody>
+                </table>
+            </div>
+            <div id="incompatibilitiesResult" style="display: none;">
+                <span class="result-label">Incompatibilities:</span>
+                <ul id="incompatibilityList" class="incompatibility-list"></ul>
+            </div>
+            <div id="lintResult" style="display: none;">
+                <span class="result-label">Lint:</span>
+                <span id="lintSummary"></span>
+                <ul id="lintList" class="incompatibility-list"></ul>
+            </div>
         </div>
     </div>
 
@@ -1721,6 +2670,15 @@ var testSchemaTemplate string = `<!DOCTYPE html>
         document.addEventListener('DOMContentLoaded', function() {
             document.getElementById('testButton').addEventListener('click', testSchema);
             document.getElementById('testButton2').addEventListener('click', testPayload);
+
+            // A registration needs a passing test of the schema as it is entered
+            const registerButton = document.getElementById('registerButton');
+            if (registerButton) {
+                registerButton.addEventListener('click', registerSchema);
+                document.getElementById('testJson').addEventListener('input', function() {
+                    registerButton.disabled = true;
+                });
+            }
         });
         
         // Shared function to display validation results for both schema and payload tests
@@ -1814,10 +2772,180 @@ var testSchemaTemplate string = `<!DOCTYPE html>
             document.getElementById('messageResult').innerHTML = 
                 '<span class="icon-badge ' + messageBadgeClass + '">' + messageDisplay + '</span>';
 
+            displayIncompatibilities(data.incompatibilities || []);
+            displayVersionMatrix(data.versions || []);
+            displayRegistered(data.registered);
+            displayLint(data.lint);
+
             // Show the result container
             document.getElementById('resultContainer').style.display = 'block';
         }
+
+        // Shows the outcome for each version when testing against several versions
+        function displayVersionMatrix(versions) {
+            const container = document.getElementById('versionsResult');
+            const body = document.getElementById('versionMatrix');
+            body.innerHTML = '';
+
+            versions.forEach(function(result) {
+                const row = document.createElement('tr');
+
+                const version = document.createElement('td');
+                version.textContent = 'v' + result.version;
+                row.appendChild(version);
+
+                let badgeClass = 'icon-badge-warning';
+                let badgeText = 'Undefined';
+                if (result.is_compatible === true) {
+                    badgeClass = 'icon-badge-true';
+                    badgeText = 'Compatible';
+                } else if (result.is_compatible === false) {
+                    badgeClass = 'icon-badge-false';
+                    badgeText = 'Not Compatible';
+                }
+                const compatibility = document.createElement('td');
+                const badge = document.createElement('span');
+                badge.className = 'icon-badge ' + badgeClass;
+                badge.textContent = badgeText;
+                compatibility.appendChild(badge);
+                row.appendChild(compatibility);
+
+                const errorCode = document.createElement('td');
+                errorCode.textContent = result.error_code ? result.error_code : 'None';
+                row.appendChild(errorCode);
+
+                const reasons = document.createElement('td');
+                const incompatibilities = result.incompatibilities || [];
+                if (incompatibilities.length > 0) {
+                    const list = document.createElement('ul');
+                    incompatibilities.forEach(function(incompatibility) {
+                        const item = document.createElement('li');
+                        item.textContent = incompatibility.type +
+                            (incompatibility.path ? ' at ' + incompatibility.path : '') +
+                            ': ' + incompatibility.description;
+                        list.appendChild(item);
+                    });
+                    reasons.appendChild(list);
+                } else {
+                    reasons.textContent = (result.message && result.message !== 'None') ? result.message : '-';
+                }
+                row.appendChild(reasons);
+
+                body.appendChild(row);
+            });
+
+            container.style.display = versions.length > 0 ? 'block' : 'none';
+        }
+
+        // Links to the version created by a registration
+        function displayRegistered(registered) {
+            const container = document.getElementById('registeredResult');
+            if (!registered) {
+                container.style.display = 'none';
+                return;
+            }
+
+            const link = document.getElementById('registeredLink');
+            link.href = '/schema/?topic=' + encodeURIComponent(registered.subject) +
+                        '&registry=' + encodeURIComponent(registry);
+            link.textContent = registered.subject + ' v' + registered.version + ' (ID ' + registered.id + ')';
+            container.style.display = 'flex';
+        }
+
+        // Lists every reason the registry gave for rejecting the schema, in full
+        function displayIncompatibilities(incompatibilities) {
+            const container = document.getElementById('incompatibilitiesResult');
+            const list = document.getElementById('incompatibilityList');
+            list.innerHTML = '';
+
+            incompatibilities.forEach(function(incompatibility) {
+                const item = document.createElement('li');
+                item.className = 'incompatibility-item';
+
+                const type = document.createElement('span');
+                type.className = 'incompatibility-type';
+                type.textContent = incompatibility.type;
+                item.appendChild(type);
+
+                if (incompatibility.path) {
+                    const path = document.createElement('span');
+                    path.className = 'incompatibility-path';
+                    path.textContent = incompatibility.path;
+                    item.appendChild(path);
+                }
+
+                const description = document.createElement('div');
+                description.className = 'incompatibility-description';
+                description.textContent = incompatibility.description;
+                item.appendChild(description);
+
+                list.appendChild(item);
+            });
+
+            container.style.display = incompatibilities.length > 0 ? 'block' : 'none';
+        }
+
+        // Lists the lint findings of the tested schema, with the rule and severity of each
+        function displayLint(lint) {
+            const container = document.getElementById('lintResult');
+            const summary = document.getElementById('lintSummary');
+            const list = document.getElementById('lintList');
+            list.innerHTML = '';
+
+            if (!lint) {
+                container.style.display = 'none';
+                return;
+            }
+
+            let badgeClass = 'icon-badge-true';
+            let summaryText = 'No findings';
+            if (lint.skipped) {
+                badgeClass = 'icon-badge-none';
+                summaryText = lint.skipped;
+            } else if (lint.findings.length > 0) {
+                badgeClass = lint.errors > 0 ? 'icon-badge-false' : 'icon-badge-warning';
+                summaryText = lint.errors + ' error(s), ' + lint.warnings + ' warning(s), ' +
+                              (lint.findings.length - lint.errors - lint.warnings) + ' info';
+            }
+            summary.innerHTML = '';
+            const badge = document.createElement('span');
+            badge.className = 'icon-badge ' + badgeClass;
+            badge.textContent = summaryText;
+            summary.appendChild(badge);
+
+            lint.findings.forEach(function(finding) {
+                const item = document.createElement('li');
+                item.className = 'incompatibility-item lint-item lint-' + finding.severity;
+
+                const rule = document.createElement('span');
+                rule.className = 'incompatibility-type';
+                rule.textContent = finding.severity.toUpperCase() + ' ' + finding.rule;
+                item.appendChild(rule);
+
+                const path = document.createElement('span');
+                path.className = 'incompatibility-path';
+                path.textContent = finding.path;
+                item.appendChild(path);
+
+                const message = document.createElement('div');
+                message.className = 'incompatibility-description';
+                message.textContent = finding.message;
+                item.appendChild(message);
+
+                list.appendChild(item);
+            });
+
+            container.style.display = 'block';
+        }
         
+const registry = "{{.Registry}}";
+
+// Schema IDs differ between registries, go back to the subject in the chosen one
+function switchRegistry(registryName) {
+    window.location.href = '/schema/?topic=' + encodeURIComponent("{{.SubjectName}}") +
+                           '&registry=' + encodeURIComponent(registryName);
+}
+
 function testPayload() {
     const testJsonText = document.getElementById('testJson').value;
     const id = "{{.SchemaID}}";  // Use the template variable, not hardcoded "1"
@@ -1851,9 +2979,12 @@ function testPayload() {
     }
 
     // Prepare the request
-    const url = '/test-payload?id=' + encodeURIComponent(id);
+    const url = '/test-payload?id=' + encodeURIComponent(id) +
+                '&registry=' + encodeURIComponent(registry);
+    const messageTypeSelect = document.getElementById('messageType');
     const requestBody = JSON.stringify({
-        payload: testJsonText
+        payload: testJsonText,
+        messageType: messageTypeSelect ? messageTypeSelect.value : ""
     });
     
     // Make the request
@@ -1907,9 +3038,12 @@ function testPayload() {
     testButton.textContent = 'Testing...';
     testButton.disabled = true;
 
-    let parsedJson;
+    // Protobuf schemas are .proto text and are sent as-is
+    let parsedJson = testJsonText;
     try {
-        parsedJson = JSON.parse(testJsonText);
+        if ("{{.SchemaType}}" !== "PROTOBUF") {
+            parsedJson = JSON.parse(testJsonText);
+        }
     } catch (error) {
         console.error("Invalid JSON!", error);
         
@@ -1928,7 +3062,7 @@ function testPayload() {
         return;
     }
     
-    fetch('/test-schema/', {
+    fetch('/test-schema/?registry=' + encodeURIComponent(registry), {
         method: 'POST',
         headers: {
             'Content-Type': 'application/json'
@@ -1937,7 +3071,8 @@ function testPayload() {
             subject: subject,
             version: version,
             id: id,
-            json: parsedJson
+            json: parsedJson,
+            against: document.getElementById('versionScope').value
         })
     })
     .then(response => response.json())
@@ -1954,6 +3089,11 @@ function testPayload() {
         
         // Use the shared displayValidationResult function with data as-is
         displayValidationResult(data);
+
+        const registerButton = document.getElementById('registerButton');
+        if (registerButton) {
+            registerButton.disabled = data.is_compatible !== true;
+        }
     })
     .catch(error => {
         // Only handle network or parse errors
@@ -1970,6 +3110,373 @@ function testPayload() {
         });
     });
 }
+
+function registerSchema() {
+    const registerButton = document.getElementById('registerButton');
+    const originalButtonText = registerButton.textContent;
+
+    let parsedJson = document.getElementById('testJson').value;
+    if ("{{.SchemaType}}" !== "PROTOBUF") {
+        parsedJson = JSON.parse(parsedJson);
+    }
+
+    if (!confirm('Register this schema as a new version of {{.SubjectName}}?')) {
+        return;
+    }
+
+    registerButton.textContent = 'Registering...';
+    registerButton.disabled = true;
+
+    fetch('/register-schema?registry=' + encodeURIComponent(registry), {
+        method: 'POST',
+        headers: {
+            'Content-Type': 'application/json'
+        },
+        body: JSON.stringify({
+            subject: "{{.SubjectName}}",
+            id: "{{.SchemaID}}",
+            json: parsedJson,
+            normalize: document.getElementById('normalize').checked
+        })
+    })
+    .then(response => response.json())
+    .then(data => {
+        registerButton.textContent = originalButtonText;
+        displayValidationResult(data);
+    })
+    .catch(error => {
+        console.error("Network or parse error:", error);
+        registerButton.textContent = originalButtonText;
+
+        displayValidationResult({
+            is_compatible: false,
+            http_status: 500,
+            error_code: "NETWORK_ERROR",
+            message: "Network or parsing error occurred"
+        });
+    });
+}
+    </script>
+</body>
+</html>`
+
+var unavailableTemplate string = `<!DOCTYPE html>
+<html>
+<head>
+    <title>Registry Unavailable - Schema Registry Dashboard</title>
+    <meta http-equiv="refresh" content="{{.RetryAfter}}">
+    <style>
+        :root {
+            --primary-color: #4a90e2;
+            --primary-dark: #357abd;
+            --primary-light: #e8f2f9;
+            --text-primary: #2c3e50;
+            --text-secondary: #546e7a;
+            --shadow-color: rgba(0, 0, 0, 0.1);
+            --transition-speed: 0.3s;
+        }
+
+        body {
+            font-family: 'Segoe UI', Arial, sans-serif;
+            max-width: 800px;
+            margin: 0 auto;
+            padding: 20px;
+            background: linear-gradient(to bottom, #1a5fb4, #80bdff, #ffffff);
+            color: var(--text-primary);
+            line-height: 1.6;
+            min-height: 100vh;
+        }
+
+        .unavailable-card {
+            margin-top: 80px;
+            padding: 40px;
+            text-align: center;
+            background: rgba(255, 255, 255, 0.95);
+            border-radius: 15px;
+            border-left: 6px solid #e67e22;
+            box-shadow: 0 4px 6px var(--shadow-color);
+        }
+
+        .unavailable-card h1 {
+            color: #d35400;
+            font-size: 2em;
+            margin-bottom: 10px;
+        }
+
+        .unavailable-details {
+            color: var(--text-secondary);
+            margin: 15px 0 25px;
+        }
+
+        .unavailable-actions {
+            display: flex;
+            justify-content: center;
+            gap: 10px;
+            flex-wrap: wrap;
+        }
+
+        .retry-button, .registry-select {
+            padding: 8px 20px;
+            border: 1px solid var(--primary-color);
+            border-radius: 20px;
+            background-color: var(--primary-light);
+            color: var(--primary-dark);
+            font-weight: 600;
+            cursor: pointer;
+            text-decoration: none;
+            transition: transform var(--transition-speed) ease;
+        }
+
+        .retry-button:hover {
+            transform: scale(1.05);
+        }
+    </style>
+</head>
+<body>
+    <div class="unavailable-card">
+        <h1>⚠️ Registry unavailable</h1>
+        <p>The <strong>{{.Registry}}</strong> schema registry is not responding.</p>
+        <p class="unavailable-details">
+            Calls to it are paused after repeated failures. This page retries in {{.RetryAfter}} seconds.
+        </p>
+        <div class="unavailable-actions">
+            <a href="" class="retry-button">🔄 Retry now</a>
+            {{if gt (len .Registries) 1}}
+            <select id="registrySelect" class="registry-select" onchange="switchRegistry(this.value)">
+                {{range .Registries}}<option value="{{.}}"{{if eq . $.Registry}} selected{{end}}>🗄️ {{.}}</option>{{end}}
+            </select>
+            {{end}}
+        </div>
+    </div>
+
+    <script>
+        function switchRegistry(registryName) {
+            window.location.href = '/?registry=' + encodeURIComponent(registryName);
+        }
+    </script>
+</body>
+</html>`
+
+var diffTemplate string = `<!DOCTYPE html>
+<html>
+<head>
+    <title>Schema Diff - {{html .SubjectName}} v{{.From.Version}} → v{{.To.Version}}</title>
+    <style>
+        :root {
+            --primary-color: #4a90e2;
+            --primary-dark: #357abd;
+            --primary-light: #e8f2f9;
+            --text-primary: #2c3e50;
+            --text-secondary: #546e7a;
+            --shadow-color: rgba(0, 0, 0, 0.1);
+            --transition-speed: 0.3s;
+        }
+
+        body {
+            font-family: 'Segoe UI', Arial, sans-serif;
+            max-width: 1200px;
+            margin: 0 auto;
+            padding: 20px;
+            background: linear-gradient(to bottom, #1a5fb4, #80bdff, #ffffff);
+            color: var(--text-primary);
+            line-height: 1.6;
+            min-height: 100vh;
+            padding-bottom: 80px;
+        }
+
+        .header-container {
+            text-align: center;
+            margin-bottom: 30px;
+            padding: 20px;
+            background: rgba(255, 255, 255, 0.9);
+            border-radius: 15px;
+            box-shadow: 0 4px 6px var(--shadow-color);
+        }
+
+        h1 {
+            display: inline-block;
+            margin: 20px 0;
+            font-size: 2em;
+            background-color: var(--primary-light);
+            color: var(--primary-dark);
+            padding: 10px 25px;
+            border-radius: 25px;
+            box-shadow: 0 4px 6px var(--shadow-color);
+        }
+
+        .back-button {
+            background: linear-gradient(135deg, var(--primary-color), var(--primary-dark));
+            color: white;
+            padding: 8px 20px;
+            border-radius: 25px;
+            font-weight: 600;
+            box-shadow: 0 2px 4px var(--shadow-color);
+            text-decoration: none;
+            position: fixed;
+            bottom: 20px;
+            left: 20px;
+            z-index: 200;
+        }
+
+        .registry-select, .version-select, .compare-button {
+            padding: 8px 16px;
+            border: 1px solid var(--primary-color);
+            border-radius: 20px;
+            background-color: var(--primary-light);
+            color: var(--primary-dark);
+            font-weight: 600;
+            margin: 0 5px;
+            cursor: pointer;
+        }
+
+        .diff-card {
+            background: white;
+            border-radius: 8px;
+            padding: 20px;
+            margin: 20px 0;
+            box-shadow: 0 2px 4px var(--shadow-color);
+        }
+
+        .diff-card h2 {
+            margin-top: 0;
+            color: var(--primary-dark);
+            font-size: 1.3em;
+        }
+
+        .diff-notice {
+            color: var(--text-secondary);
+            font-style: italic;
+        }
+
+        .change-table {
+            width: 100%;
+            border-collapse: collapse;
+        }
+
+        .change-table th, .change-table td {
+            text-align: left;
+            padding: 6px 10px;
+            border-bottom: 1px solid var(--primary-light);
+            vertical-align: top;
+        }
+
+        .change-table code {
+            word-break: break-all;
+        }
+
+        .change-kind {
+            display: inline-block;
+            padding: 2px 8px;
+            border-radius: 12px;
+            font-size: 0.85em;
+            font-weight: 600;
+            background-color: var(--primary-light);
+            color: var(--primary-dark);
+        }
+
+        .change-kind.kind-added {
+            background-color: #e8f5e9;
+            color: #2e7d32;
+        }
+
+        .change-kind.kind-removed {
+            background-color: #ffebee;
+            color: #c0392b;
+        }
+
+        .text-diff {
+            background: #f8f8f8;
+            border-radius: 4px;
+            padding: 10px 0;
+            font-family: monospace;
+            overflow-x: auto;
+        }
+
+        .diff-line {
+            white-space: pre;
+            padding: 0 15px;
+        }
+
+        .diff-line.line-added {
+            background-color: #e8f5e9;
+            color: #2e7d32;
+        }
+
+        .diff-line.line-removed {
+            background-color: #ffebee;
+            color: #c0392b;
+        }
+    </style>
+</head>
+<body>
+    <div class="header-container">
+        <a href="/schema/?topic={{urlquery .SubjectName}}&registry={{urlquery .Registry}}" class="back-button">Back to Schema View</a>
+        <h1>🔍 {{html .SubjectName}}</h1>
+        <div>
+            <select id="fromVersion" class="version-select">
+                {{range .Versions}}<option value="{{.Version}}"{{if eq .Version $.From.Version}} selected{{end}}>v{{.Version}}{{if .Deleted}} (soft-deleted){{end}}</option>{{end}}
+            </select>
+            →
+            <select id="toVersion" class="version-select">
+                {{range .Versions}}<option value="{{.Version}}"{{if eq .Version $.To.Version}} selected{{end}}>v{{.Version}}{{if .Deleted}} (soft-deleted){{end}}</option>{{end}}
+            </select>
+            <button class="compare-button" onclick="compareVersions()">Compare</button>
+            {{if gt (len .Registries) 1}}
+            <select id="registrySelect" class="registry-select" onchange="switchRegistry(this.value)">
+                {{range .Registries}}<option value="{{html .}}"{{if eq . $.Registry}} selected{{end}}>🗄️ {{html .}}</option>{{end}}
+            </select>
+            {{end}}
+        </div>
+    </div>
+
+    <div class="diff-card">
+        <h2>Structural changes</h2>
+        {{if not .Diff.Structural}}
+        <p class="diff-notice">{{html .Diff.Fallback}}, see the text diff below.</p>
+        {{else if not .Diff.Changes}}
+        <p class="diff-notice">No structural changes between v{{.From.Version}} and v{{.To.Version}}.</p>
+        {{else}}
+        <table class="change-table">
+            <tr><th>Change</th><th>Path</th><th>Before</th><th>After</th></tr>
+            {{range .Diff.Changes}}
+            <tr>
+                <td><span class="change-kind{{if or (eq .Kind "FIELD_ADDED") (eq .Kind "DEFINITION_ADDED") (eq .Kind "REQUIRED_ADDED")}} kind-added{{else if or (eq .Kind "FIELD_REMOVED") (eq .Kind "DEFINITION_REMOVED") (eq .Kind "REQUIRED_REMOVED")}} kind-removed{{end}}">{{.Kind}}</span></td>
+                <td><code>{{html .Path}}</code></td>
+                <td><code>{{html .Before}}</code></td>
+                <td><code>{{html .After}}</code></td>
+            </tr>
+            {{end}}
+        </table>
+        {{end}}
+    </div>
+
+    <div class="diff-card">
+        <h2>Text diff</h2>
+        {{if .Diff.TextFallback}}<p class="diff-notice">{{html .Diff.TextFallback}}.</p>{{end}}
+        <div class="text-diff">
+            {{range .Diff.Lines}}<div class="diff-line{{if eq .Op "+"}} line-added{{else if eq .Op "-"}} line-removed{{end}}">{{.Op}} {{html .Text}}</div>{{end}}
+        </div>
+    </div>
+
+    <script>
+        const subjectName = "{{js .SubjectName}}";
+        const registry = "{{js .Registry}}";
+
+        function diffURL(from, to, registryName) {
+            return '/schema-diff/?topic=' + encodeURIComponent(subjectName) +
+                   '&from=' + encodeURIComponent(from) + '&to=' + encodeURIComponent(to) +
+                   '&registry=' + encodeURIComponent(registryName);
+        }
+
+        function compareVersions() {
+            const from = document.getElementById('fromVersion').value;
+            const to = document.getElementById('toVersion').value;
+            window.location.href = diffURL(from, to, registry);
+        }
+
+        function switchRegistry(registryName) {
+            window.location.href = diffURL({{.From.Version}}, {{.To.Version}}, registryName);
+        }
     </script>
 </body>
 </html>`
@@ -1,33 +1,66 @@
 package handlers
 
 import (
+	"context"
 	"kafka-board/helpers"
+	"kafka-board/schemaLint"
 	"kafka-board/types"
 	"log/slog"
 )
 
 type handler struct {
-	registryAPI registryAPICalls
-	logger      *slog.Logger
-	helpers     *helpers.Helpers
+	registries []Registry
+	logger     *slog.Logger
+	helpers    *helpers.Helpers
+	// Rules tested schemas are linted with
+	lintRules schemaLint.RuleSet
+	// Canonical forms of the most recently shown or searched schemas
+	canonicalForms *canonicalCache
 }
 
-// returnHandler creates and returns a new handler that implements registryAPICalls
-// It can be extended to accept configuration options like base URLs, credentials, etc.
-func ReturnHandler(logger *slog.Logger, registryConcreteImplementation registryAPICalls) *handler {
+// Registry is a schema registry served by the handler, listed under its name
+type Registry struct {
+	Name        string
+	RegistryAPI registryAPICalls
+}
+
+// returnHandler creates and returns a new handler serving the given registries.
+// The first registry is used when a request does not name one.
+func ReturnHandler(logger *slog.Logger, registries []Registry, lintRules schemaLint.RuleSet) *handler {
 	return &handler{
-		logger:      logger,
-		registryAPI: registryConcreteImplementation,
-		helpers:     helpers.ReturnHelpers(logger),
+		logger:         logger,
+		registries:     registries,
+		helpers:        helpers.ReturnHelpers(logger),
+		lintRules:      lintRules,
+		canonicalForms: newCanonicalCache(maxCanonicalForms),
 	}
 }
 
+// registryAPICalls are the registry calls used by the handlers. Every call takes
+// the context of the incoming request, so it stops when the request goes away.
 type registryAPICalls interface {
 	// API methods
-	ReturnSubjects() ([]string, error)
-	ReturnSubjectConfigs(subjectNames []string) ([]types.SubjectConfigInterface, error)
-	GetGlobalConfig() (types.GlobalConfig, error)
-	GetSchemas(subjectName string) ([]types.Schema, error)
-	TestSchema(subjectName string, version int, testJSON string) (types.Response, error)
-	GetSchema(id string) (types.Schema, error)
+	ReturnSubjects(ctx context.Context) ([]string, error)
+	ReturnContexts(ctx context.Context) ([]string, error)
+	ReturnSubjectConfigs(ctx context.Context, subjectNames []string) ([]types.SubjectConfigInterface, error)
+	GetGlobalConfig(ctx context.Context) (types.GlobalConfig, error)
+	GetSchemas(ctx context.Context, subjectName string, includeDeleted bool) ([]types.Schema, error)
+	GetSubjectVersion(ctx context.Context, subjectName string, version int, includeDeleted bool) (types.Schema, error)
+	TestSchema(ctx context.Context, subjectName string, version int, proposed types.Schema) (types.Response, error)
+	TestSchemaAgainstVersions(ctx context.Context, subjectName string, latestOnly bool, proposed types.Schema) (types.Response, error)
+	RegisterSchema(ctx context.Context, subjectName string, proposed types.Schema, normalize bool) (types.Response, error)
+	ImportSchema(ctx context.Context, subjectName string, schema types.Schema) error
+	UpdateCompatibilityLevel(ctx context.Context, subjectName string, level string) error
+	DeleteCompatibilityLevel(ctx context.Context, subjectName string) error
+	UpdateConfig(ctx context.Context, subjectName string, config types.ConfigPayload) error
+	PreviewCompatibilityLevel(ctx context.Context, subjectName string, level string) (types.LevelPreview, error)
+	GetMode(ctx context.Context, subjectName string) (types.SubjectMode, error)
+	ReturnSubjectModes(ctx context.Context, subjectNames []string) ([]types.SubjectMode, error)
+	UpdateMode(ctx context.Context, subjectName string, mode string, force bool) error
+	DeleteMode(ctx context.Context, subjectName string) error
+	DeleteSubject(ctx context.Context, subjectName string, permanent bool) ([]int, error)
+	DeleteSubjectVersion(ctx context.Context, subjectName string, version int, permanent bool) error
+	RestoreSubjectVersion(ctx context.Context, subjectName string, version int) (types.Response, error)
+	GetSchema(ctx context.Context, id string) (types.Schema, error)
+	ResolveReferences(ctx context.Context, schema types.Schema) ([]types.ResolvedReference, error)
 }
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kafka-board/schemaLint"
+	"kafka-board/types"
+)
+
+// writeEndpoint is a handler changing the registry, with a request it accepts
+type writeEndpoint struct {
+	name   string
+	handle func(h *handler) http.HandlerFunc
+	method string
+	target string
+	body   string
+}
+
+var writeEndpoints = []writeEndpoint{
+	{
+		name:   "register schema",
+		handle: func(h *handler) http.HandlerFunc { return h.HandleRegisterSchema },
+		method: http.MethodPost,
+		target: "/register-schema",
+		body:   `{"subject": "orders", "id": "1", "json": {"type": "object"}}`,
+	},
+	{
+		name:   "set compatibility level",
+		handle: func(h *handler) http.HandlerFunc { return h.HandleCompatibilityLevel },
+		method: http.MethodPut,
+		target: "/compatibility-level",
+		body:   `{"subject": "orders", "level": "FULL"}`,
+	},
+	{
+		name:   "reset compatibility level",
+		handle: func(h *handler) http.HandlerFunc { return h.HandleCompatibilityLevel },
+		method: http.MethodDelete,
+		target: "/compatibility-level?subject=orders",
+	},
+	{
+		name:   "set mode",
+		handle: func(h *handler) http.HandlerFunc { return h.HandleMode },
+		method: http.MethodPut,
+		target: "/mode",
+		body:   `{"subject": "orders", "mode": "READONLY"}`,
+	},
+	{
+		name:   "reset mode",
+		handle: func(h *handler) http.HandlerFunc { return h.HandleMode },
+		method: http.MethodDelete,
+		target: "/mode?subject=orders",
+	},
+	{
+		name:   "delete version",
+		handle: func(h *handler) http.HandlerFunc { return h.HandleDeleteSchema },
+		method: http.MethodPost,
+		target: "/delete-schema",
+		body:   `{"subject": "orders", "version": 1, "permanent": false, "confirm": "orders"}`,
+	},
+	{
+		name:   "restore version",
+		handle: func(h *handler) http.HandlerFunc { return h.HandleRestoreVersion },
+		method: http.MethodPost,
+		target: "/restore-version",
+		body:   `{"subject": "orders", "version": 1}`,
+	},
+}
+
+func TestWriteRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		// Changes the accepted request of an endpoint
+		prepare  func(req *http.Request)
+		method   string
+		body     string
+		readOnly bool
+		// Only applies to endpoints taking a body, not to DELETE
+		bodyOnly       bool
+		expectedStatus int
+		expectWrite    bool
+	}{
+		{
+			name: "Request from the board - Written",
+			prepare: func(req *http.Request) {
+				req.Header.Set("Origin", "http://example.com")
+				req.Header.Set("Sec-Fetch-Site", "same-origin")
+			},
+			expectedStatus: http.StatusOK,
+			expectWrite:    true,
+		},
+		{
+			name:           "Request without browser headers - Written",
+			expectedStatus: http.StatusOK,
+			expectWrite:    true,
+		},
+		{
+			name:           "Wrong method - Method not allowed",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "Cross-site fetch - Forbidden",
+			prepare:        func(req *http.Request) { req.Header.Set("Sec-Fetch-Site", "cross-site") },
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Other origin - Forbidden",
+			prepare:        func(req *http.Request) { req.Header.Set("Origin", "https://attacker.example") },
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Other referer - Forbidden",
+			prepare:        func(req *http.Request) { req.Header.Set("Referer", "https://attacker.example/page") },
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Form body - Unsupported media type",
+			prepare:        func(req *http.Request) { req.Header.Set("Content-Type", "application/x-www-form-urlencoded") },
+			bodyOnly:       true,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "Malformed body - Bad request",
+			body:           `{"subject": `,
+			bodyOnly:       true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Read-only board - Forbidden",
+			readOnly:       true,
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, endpoint := range writeEndpoints {
+		for _, tt := range tests {
+			if tt.bodyOnly && endpoint.method == http.MethodDelete {
+				continue
+			}
+
+			t.Run(endpoint.name+"/"+tt.name, func(t *testing.T) {
+				if tt.readOnly {
+					t.Setenv("READ_ONLY", "true")
+				}
+
+				registryAPI := &mockRegistryAPI{mockSchema: types.Schema{Subject: "orders", Version: 1, Id: 1, SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object"}`}}
+				h := ReturnHandler(logger, []Registry{{Name: "dev", RegistryAPI: registryAPI}}, schemaLint.RuleSet{})
+
+				method, body := endpoint.method, endpoint.body
+				if tt.method != "" {
+					method = tt.method
+				}
+				if tt.body != "" {
+					body = tt.body
+				}
+
+				req := httptest.NewRequest(method, endpoint.target, strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				if tt.prepare != nil {
+					tt.prepare(req)
+				}
+				w := httptest.NewRecorder()
+
+				endpoint.handle(h)(w, req)
+
+				if w.Code != tt.expectedStatus {
+					t.Errorf("status = %d, want %d: %s", w.Code, tt.expectedStatus, w.Body.String())
+				}
+				if wrote := len(registryAPI.writes) > 0; wrote != tt.expectWrite {
+					t.Errorf("writes = %q, want a write: %t", registryAPI.writes, tt.expectWrite)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"kafka-board/types"
+
+	"github.com/hamba/avro/v2"
+)
+
+// ParseAvroSchema parses an Avro schema string with its own name cache so that
+// schemas from different subjects can reuse record names without clashing.
+// Referenced schemas are parsed first so their named types are known.
+func ParseAvroSchema(schemaStr string, references []types.ResolvedReference) (avro.Schema, error) {
+	cache := &avro.SchemaCache{}
+	for _, reference := range references {
+		if _, err := avro.ParseWithCache(reference.Schema.Schema, "", cache); err != nil {
+			return nil, fmt.Errorf("error parsing referenced schema %s: %w", reference.Name, err)
+		}
+	}
+
+	return avro.ParseWithCache(schemaStr, "", cache)
+}
+
+// validateAvroPayload validates a JSON-decoded payload against an Avro schema.
+// Error messages follow the "<field>: <description>" format used by gojsonschema
+// so both schema types render the same way in the UI.
+func validateAvroPayload(payload interface{}, schema types.Schema, references []types.ResolvedReference) (bool, []string, error) {
+	avroSchema, err := ParseAvroSchema(schema.Schema, references)
+	if err != nil {
+		return false, nil, fmt.Errorf("error parsing Avro schema: %w", err)
+	}
+
+	errors := validateAvroValue(avroSchema, payload, "")
+	if len(errors) > 0 {
+		return false, errors, nil
+	}
+
+	return true, nil, nil
+}
+
+// validateAvroValue walks the schema and value together and returns one
+// message per mismatch found
+func validateAvroValue(schema avro.Schema, value any, path string) []string {
+	switch s := schema.(type) {
+	case *avro.RefSchema:
+		return validateAvroValue(s.Schema(), value, path)
+
+	case *avro.UnionSchema:
+		return validateAvroUnion(s, value, path)
+
+	case *avro.RecordSchema:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return []string{invalidPayloadType(path, "record", value)}
+		}
+
+		var errors []string
+		known := make(map[string]bool, len(s.Fields()))
+		for _, field := range s.Fields() {
+			known[field.Name()] = true
+
+			fieldValue, present := object[field.Name()]
+			if !present {
+				if !field.HasDefault() {
+					errors = append(errors, fmt.Sprintf("%s: %s is required", payloadFieldName(path), field.Name()))
+				}
+				continue
+			}
+
+			errors = append(errors, validateAvroValue(field.Type(), fieldValue, joinPayloadPath(path, field.Name()))...)
+		}
+
+		for _, key := range sortedKeys(object) {
+			if !known[key] {
+				errors = append(errors, fmt.Sprintf("%s: Additional property %s is not allowed", payloadFieldName(path), key))
+			}
+		}
+
+		return errors
+
+	case *avro.EnumSchema:
+		symbol, ok := value.(string)
+		if !ok {
+			return []string{invalidPayloadType(path, "enum", value)}
+		}
+		for _, allowed := range s.Symbols() {
+			if symbol == allowed {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %s must be one of the following: %s",
+			payloadFieldName(path), payloadFieldName(path), quoteAll(s.Symbols()))}
+
+	case *avro.ArraySchema:
+		items, ok := value.([]any)
+		if !ok {
+			return []string{invalidPayloadType(path, "array", value)}
+		}
+
+		var errors []string
+		for i, item := range items {
+			errors = append(errors, validateAvroValue(s.Items(), item, joinPayloadPath(path, fmt.Sprintf("%d", i)))...)
+		}
+		return errors
+
+	case *avro.MapSchema:
+		entries, ok := value.(map[string]any)
+		if !ok {
+			return []string{invalidPayloadType(path, "map", value)}
+		}
+
+		var errors []string
+		for _, key := range sortedKeys(entries) {
+			errors = append(errors, validateAvroValue(s.Values(), entries[key], joinPayloadPath(path, key))...)
+		}
+		return errors
+
+	case *avro.FixedSchema:
+		str, ok := value.(string)
+		if !ok {
+			return []string{invalidPayloadType(path, "fixed", value)}
+		}
+		// Avro JSON encodes bytes as one code point per byte
+		if utf8.RuneCountInString(str) != s.Size() {
+			return []string{fmt.Sprintf("%s: Fixed value must be exactly %d bytes, given: %d",
+				payloadFieldName(path), s.Size(), utf8.RuneCountInString(str))}
+		}
+		return nil
+	}
+
+	return validateAvroPrimitive(schema.Type(), value, path)
+}
+
+// validateAvroPrimitive checks a value against one of the Avro primitive types
+func validateAvroPrimitive(avroType avro.Type, value any, path string) []string {
+	switch avroType {
+	case avro.Null:
+		if value == nil {
+			return nil
+		}
+	case avro.Boolean:
+		if _, ok := value.(bool); ok {
+			return nil
+		}
+	case avro.String, avro.Bytes:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+	case avro.Int, avro.Long:
+		number, ok := value.(float64)
+		if ok && number == math.Trunc(number) {
+			if avroType == avro.Int && (number < math.MinInt32 || number > math.MaxInt32) {
+				return []string{fmt.Sprintf("%s: Value %v is out of range for int", payloadFieldName(path), number)}
+			}
+			return nil
+		}
+	case avro.Float, avro.Double:
+		if _, ok := value.(float64); ok {
+			return nil
+		}
+	default:
+		return []string{fmt.Sprintf("%s: Unsupported Avro type %s", payloadFieldName(path), avroType)}
+	}
+
+	return []string{invalidPayloadType(path, string(avroType), value)}
+}
+
+// validateAvroUnion accepts both the Avro JSON encoding, where non-null union
+// values are wrapped as {"<type name>": value}, and plain JSON values
+func validateAvroUnion(s *avro.UnionSchema, value any, path string) []string {
+	if wrapped, ok := value.(map[string]any); ok && len(wrapped) == 1 {
+		for name, inner := range wrapped {
+			if branch, _ := s.Types().Get(name); branch != nil {
+				return validateAvroValue(branch, inner, path)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(s.Types()))
+	for _, branch := range s.Types() {
+		if len(validateAvroValue(branch, value, path)) == 0 {
+			return nil
+		}
+		names = append(names, avroTypeName(branch))
+	}
+
+	return []string{fmt.Sprintf("%s: Value does not match any type of union [%s], given: %s",
+		payloadFieldName(path), strings.Join(names, ", "), jsonTypeName(value))}
+}
+
+// avroTypeName returns the full name of named types and the type otherwise
+func avroTypeName(schema avro.Schema) string {
+	if named, ok := schema.(avro.NamedSchema); ok {
+		return named.FullName()
+	}
+	return string(schema.Type())
+}
@@ -0,0 +1,102 @@
+package helpers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"kafka-board/types"
+)
+
+func TestValidateAvroPayload(t *testing.T) {
+	schema := types.Schema{
+		Id: 1,
+		Schema: `{
+			"type": "record",
+			"name": "Order",
+			"namespace": "com.example",
+			"fields": [
+				{"name": "id", "type": "int"},
+				{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "SHIPPED"]}},
+				{"name": "note", "type": ["null", "string"], "default": null},
+				{"name": "tags", "type": {"type": "array", "items": "string"}, "default": []}
+			]
+		}`,
+	}
+
+	tests := []struct {
+		name          string
+		payload       string
+		expectedValid bool
+		expectedError string
+	}{
+		{
+			name:          "valid payload",
+			payload:       `{"id": 1, "status": "NEW", "note": "hello", "tags": ["a"]}`,
+			expectedValid: true,
+		},
+		{
+			name:          "valid payload with Avro JSON union encoding",
+			payload:       `{"id": 1, "status": "NEW", "note": {"string": "hello"}}`,
+			expectedValid: true,
+		},
+		{
+			name:          "missing required field",
+			payload:       `{"status": "NEW"}`,
+			expectedValid: false,
+			expectedError: "(root): id is required",
+		},
+		{
+			name:          "wrong field type",
+			payload:       `{"id": "one", "status": "NEW"}`,
+			expectedValid: false,
+			expectedError: "id: Invalid type. Expected: int, given: string",
+		},
+		{
+			name:          "unknown enum symbol",
+			payload:       `{"id": 1, "status": "LOST"}`,
+			expectedValid: false,
+			expectedError: `status: status must be one of the following: "NEW", "SHIPPED"`,
+		},
+		{
+			name:          "union mismatch",
+			payload:       `{"id": 1, "status": "NEW", "note": 5}`,
+			expectedValid: false,
+			expectedError: "note: Value does not match any type of union [null, string], given: integer",
+		},
+		{
+			name:          "array item mismatch",
+			payload:       `{"id": 1, "status": "NEW", "tags": ["a", true]}`,
+			expectedValid: false,
+			expectedError: "tags.1: Invalid type. Expected: string, given: boolean",
+		},
+		{
+			name:          "additional property",
+			payload:       `{"id": 1, "status": "NEW", "extra": 1}`,
+			expectedValid: false,
+			expectedError: "(root): Additional property extra is not allowed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var payload interface{}
+			if err := json.Unmarshal([]byte(test.payload), &payload); err != nil {
+				t.Fatalf("invalid test payload: %v", err)
+			}
+
+			isValid, errors, err := ValidatePayload(payload, schema, nil, "")
+			if err != nil {
+				t.Fatalf("ValidatePayload() unexpected error: %v", err)
+			}
+
+			if isValid != test.expectedValid {
+				t.Errorf("ValidatePayload() valid = %v, want %v (errors: %v)", isValid, test.expectedValid, errors)
+			}
+
+			if test.expectedError != "" && !strings.Contains(strings.Join(errors, "; "), test.expectedError) {
+				t.Errorf("Expected errors to contain '%s', got '%v'", test.expectedError, errors)
+			}
+		})
+	}
+}
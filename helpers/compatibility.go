@@ -0,0 +1,93 @@
+package helpers
+
+import (
+	"regexp"
+	"strings"
+
+	"kafka-board/types"
+)
+
+// verboseMessageKeys are the keys of the entries of a verbose compatibility result,
+// e.g. {errorType:'READER_FIELD_MISSING_DEFAULT_VALUE', description:'...', additionalInfo:'...'}
+var verboseMessageKeys = regexp.MustCompile(`(?:^\{|,)\s*(errorType|description|additionalInfo|oldSchemaVersion|oldSchema|validateFields|compatibility)\s*:`)
+
+// verboseMessagePath finds the location of an incompatibility in its description,
+// e.g. "... at path '/fields/0/type' in the new schema ..."
+var verboseMessagePath = regexp.MustCompile(`at path '([^']*)'`)
+
+// ParseIncompatibilities turns the messages of a verbose compatibility result into
+// structured incompatibilities. The registry formats each message as a loosely
+// quoted object whose values may contain quotes themselves, so values are split on
+// the known keys. Entries describing the checked version rather than an
+// incompatibility are skipped, messages in any other format are kept as descriptions.
+func ParseIncompatibilities(messages []string) []types.Incompatibility {
+	incompatibilities := []types.Incompatibility{}
+
+	for _, message := range messages {
+		fields := parseVerboseMessage(message)
+		if fields == nil {
+			incompatibilities = append(incompatibilities, types.Incompatibility{
+				Type:        "UNKNOWN",
+				Description: strings.TrimSpace(message),
+			})
+			continue
+		}
+
+		if fields["errorType"] == "" {
+			continue
+		}
+
+		incompatibility := types.Incompatibility{
+			Type:        fields["errorType"],
+			Description: fields["description"],
+		}
+		if match := verboseMessagePath.FindStringSubmatch(incompatibility.Description); match != nil {
+			incompatibility.Path = match[1]
+		}
+		if incompatibility.Path == "" && strings.HasPrefix(fields["additionalInfo"], "/") {
+			incompatibility.Path = fields["additionalInfo"]
+		}
+
+		incompatibilities = append(incompatibilities, incompatibility)
+	}
+
+	return incompatibilities
+}
+
+// parseVerboseMessage returns the fields of one verbose message, or nil when the
+// message is not in the registry format
+func parseVerboseMessage(message string) map[string]string {
+	message = strings.TrimSpace(message)
+	if !strings.HasPrefix(message, "{") || !strings.HasSuffix(message, "}") {
+		return nil
+	}
+
+	keys := verboseMessageKeys.FindAllStringSubmatchIndex(message, -1)
+	if len(keys) == 0 || keys[0][0] != 0 {
+		return nil
+	}
+
+	fields := make(map[string]string, len(keys))
+	for i, key := range keys {
+		end := len(message) - 1
+		if i+1 < len(keys) {
+			end = keys[i+1][0]
+		}
+
+		name := message[key[2]:key[3]]
+		fields[name] = unquoteVerboseValue(message[key[1]:end])
+	}
+
+	return fields
+}
+
+func unquoteVerboseValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '\'' || first == '"') && first == last {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
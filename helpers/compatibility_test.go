@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+
+	"kafka-board/types"
+)
+
+func TestParseIncompatibilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		expected []types.Incompatibility
+	}{
+		{
+			name: "avro incompatibility with quotes inside the description",
+			messages: []string{
+				"{errorType:'READER_FIELD_MISSING_DEFAULT_VALUE', description:'The field 'email' at path '/fields/2' in the new schema has no default value and is missing in the old schema', additionalInfo:'email'}",
+				"{oldSchemaVersion: 3}",
+				"{oldSchema: '{\"type\":\"record\",\"name\":\"Customer\",\"fields\":[]}'}",
+				"{validateFields: 'false', compatibility: 'BACKWARD'}",
+			},
+			expected: []types.Incompatibility{{
+				Type:        "READER_FIELD_MISSING_DEFAULT_VALUE",
+				Path:        "/fields/2",
+				Description: "The field 'email' at path '/fields/2' in the new schema has no default value and is missing in the old schema",
+			}},
+		},
+		{
+			name: "json schema incompatibilities with double quotes",
+			messages: []string{
+				`{errorType:"PROPERTY_REMOVED_FROM_CLOSED_CONTENT_MODEL", description:"The new schema removes a property at path '#/properties/age' from a closed content model"}`,
+				`{errorType:"TYPE_NARROWED", description:"A type was narrowed"}`,
+			},
+			expected: []types.Incompatibility{
+				{
+					Type:        "PROPERTY_REMOVED_FROM_CLOSED_CONTENT_MODEL",
+					Path:        "#/properties/age",
+					Description: "The new schema removes a property at path '#/properties/age' from a closed content model",
+				},
+				{Type: "TYPE_NARROWED", Description: "A type was narrowed"},
+			},
+		},
+		{
+			name:     "messages in another format are kept as they are",
+			messages: []string{"Schema being registered is incompatible with an earlier schema"},
+			expected: []types.Incompatibility{{
+				Type:        "UNKNOWN",
+				Description: "Schema being registered is incompatible with an earlier schema",
+			}},
+		},
+		{
+			name:     "no messages",
+			expected: []types.Incompatibility{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incompatibilities := ParseIncompatibilities(tt.messages)
+			if !reflect.DeepEqual(incompatibilities, tt.expected) {
+				t.Errorf("ParseIncompatibilities() = %+v, want %+v", incompatibilities, tt.expected)
+			}
+		})
+	}
+}
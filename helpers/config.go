@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"os"
+	"strconv"
 )
 
 // GetServerAddress returns the server address with port from environment
@@ -12,3 +13,16 @@ func GetServerAddress() string {
 	}
 	return ":" + port
 }
+
+// GetAdminToken returns the bearer token required by the admin endpoints.
+// The admin endpoints are disabled when it is not set.
+func GetAdminToken() string {
+	return os.Getenv("ADMIN_TOKEN")
+}
+
+// IsReadOnly reports whether the board runs in read-only mode, set with
+// READ_ONLY=true. Actions changing the registry are disabled in read-only mode.
+func IsReadOnly() bool {
+	readOnly, _ := strconv.ParseBool(os.Getenv("READ_ONLY"))
+	return readOnly
+}
@@ -2,13 +2,28 @@ package helpers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"time"
 )
 
+// Errors returned when a registry call does not complete, check them with errors.Is
+var (
+	// ErrRequestCanceled is returned when the caller gave up, e.g. the browser tab was closed
+	ErrRequestCanceled = errors.New("request was canceled")
+
+	// ErrRequestTimedOut is returned when the call ran past its deadline
+	ErrRequestTimedOut = errors.New("request timed out")
+
+	// ErrRegistryUnavailable is returned without calling the registry while it is
+	// considered down, after too many consecutive failed calls
+	ErrRegistryUnavailable = errors.New("registry unavailable")
+)
+
 // Registry API configuration
 var (
 	// GetRegistryURL returns the base URL for the Schema Registry API
@@ -68,29 +83,44 @@ func MakeHTTPRequest(req *http.Request) (*http.Response, error) {
 //
 // Parameters:
 //   - ctx: Context for the request execution
+//   - client: The HTTP client to send the request with
 //   - req: The HTTP request to execute
 //
 // Returns:
 //   - *http.Response: The HTTP response
-//   - error: An error if the request fails or is canceled
-func MakeHTTPRequestWithContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+//   - error: An error if the request fails, wrapping ErrRequestCanceled or
+//     ErrRequestTimedOut when the request did not complete in time
+func MakeHTTPRequestWithContext(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
 	// Apply the context to the request
 	req = req.WithContext(ctx)
 
-	resp, err := httpClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		if ctx.Err() == context.Canceled {
-			return nil, fmt.Errorf("request was canceled: %w", err)
-		}
-		if ctx.Err() == context.DeadlineExceeded {
-			return nil, fmt.Errorf("request timed out: %w", err)
-		}
-		return nil, fmt.Errorf("error making request to %s: %w", req.URL.String(), err)
+		return nil, RequestContextError(ctx, fmt.Errorf("error making request to %s: %w", req.URL.String(), err))
 	}
 
 	return resp, nil
 }
 
+// RequestContextError tells cancellations and timeouts apart from other errors.
+// err is wrapped in ErrRequestCanceled or ErrRequestTimedOut when the context
+// is done or the client timed out, and returned as-is otherwise.
+func RequestContextError(ctx context.Context, err error) error {
+	if errors.Is(err, ErrRequestCanceled) || errors.Is(err, ErrRequestTimedOut) {
+		return err
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("%w: %v", ErrRequestCanceled, err)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("%w: %v", ErrRequestTimedOut, err)
+	}
+
+	return err
+}
+
 // ReadResponseBody reads and returns the response body
 // It handles closing the response body automatically
 //
@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"kafka-board/types"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// jsonSchemaBaseURL is the base given to schemas without an id so that relative
+// $ref values, which is how the registry names references, can be resolved
+const jsonSchemaBaseURL = "https://kafka-board.local/"
+
+// compileJSONSchema compiles a JSON schema with every referenced schema preloaded,
+// so $ref values pointing to other subjects resolve without network access
+func compileJSONSchema(schemaStr string, references []types.ResolvedReference) (*gojsonschema.Schema, error) {
+	if len(references) == 0 {
+		return gojsonschema.NewSchema(gojsonschema.NewStringLoader(schemaStr))
+	}
+
+	var root map[string]any
+	if err := json.Unmarshal([]byte(schemaStr), &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	// Draft-04 uses id, later drafts use $id
+	baseURL, _ := root["$id"].(string)
+	if baseURL == "" {
+		baseURL, _ = root["id"].(string)
+	}
+	if baseURL == "" {
+		baseURL = jsonSchemaBaseURL + "schema.json"
+		root["$id"] = baseURL
+		root["id"] = baseURL
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema id %s: %w", baseURL, err)
+	}
+
+	loader := gojsonschema.NewSchemaLoader()
+	for _, reference := range references {
+		name, err := url.Parse(reference.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reference name %s: %w", reference.Name, err)
+		}
+
+		if err := loader.AddSchema(base.ResolveReference(name).String(), gojsonschema.NewStringLoader(reference.Schema.Schema)); err != nil {
+			return nil, fmt.Errorf("error loading referenced schema %s: %w", reference.Name, err)
+		}
+	}
+
+	return loader.Compile(gojsonschema.NewGoLoader(root))
+}
@@ -0,0 +1,42 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"kafka-board/types"
+)
+
+func TestValidateJSONPayloadWithReferences(t *testing.T) {
+	schema := types.Schema{
+		SchemaType: types.SchemaTypeJSON,
+		Schema:     `{"type": "object", "properties": {"address": {"$ref": "address.json"}}, "required": ["address"]}`,
+	}
+	references := []types.ResolvedReference{
+		{
+			Name: "address.json",
+			Schema: types.Schema{
+				SchemaType: types.SchemaTypeJSON,
+				Schema:     `{"type": "object", "properties": {"street": {"type": "string"}}, "required": ["street"]}`,
+			},
+		},
+	}
+
+	isValid, errors, err := ValidatePayload(map[string]any{"address": map[string]any{"street": "Main"}}, schema, references, "")
+	if err != nil || !isValid {
+		t.Fatalf("ValidatePayload() = %v, %v, %v, want a valid payload", isValid, errors, err)
+	}
+
+	isValid, errors, err = ValidatePayload(map[string]any{"address": map[string]any{"street": 1}}, schema, references, "")
+	if err != nil {
+		t.Fatalf("ValidatePayload() unexpected error: %v", err)
+	}
+	if isValid || !strings.Contains(strings.Join(errors, "; "), "address.street: Invalid type. Expected: string, given: integer") {
+		t.Errorf("ValidatePayload() = %v, %v, want a type error on address.street", isValid, errors)
+	}
+
+	// Without the reference graph the $ref cannot be resolved
+	if _, _, err := ValidatePayload(map[string]any{}, schema, nil, ""); err == nil {
+		t.Errorf("ValidatePayload() expected an error when references are missing")
+	}
+}
@@ -0,0 +1,353 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"kafka-board/types"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protoFileName is the name given to the schema text when compiling it
+const protoFileName = "schema.proto"
+
+// ParseProtobufSchema compiles a .proto schema text into a file descriptor.
+// Referenced schemas are served under their reference name, which is the import
+// path. Imports of the well-known google/protobuf types are resolved automatically.
+func ParseProtobufSchema(schemaStr string, references []types.ResolvedReference) (protoreflect.FileDescriptor, error) {
+	sources := map[string]string{
+		protoFileName: schemaStr,
+	}
+	for _, reference := range references {
+		sources[reference.Name] = reference.Schema.Schema
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(sources),
+		}),
+	}
+
+	files, err := compiler.Compile(context.Background(), protoFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return files[0], nil
+}
+
+// ProtobufMessageTypes returns the fully qualified names of every message
+// defined in a .proto schema, nested messages included, in declaration order
+func ProtobufMessageTypes(schemaStr string, references []types.ResolvedReference) ([]string, error) {
+	file, err := ParseProtobufSchema(schemaStr, references)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	var collect func(messages protoreflect.MessageDescriptors)
+	collect = func(messages protoreflect.MessageDescriptors) {
+		for i := 0; i < messages.Len(); i++ {
+			message := messages.Get(i)
+			// Map entries are generated by the compiler and cannot be chosen
+			if message.IsMapEntry() {
+				continue
+			}
+			names = append(names, string(message.FullName()))
+			collect(message.Messages())
+		}
+	}
+	collect(file.Messages())
+
+	return names, nil
+}
+
+// findProtobufMessage looks up a message by fully qualified name, defaulting to
+// the first message of the file as the schema registry serializers do
+func findProtobufMessage(file protoreflect.FileDescriptor, messageType string) (protoreflect.MessageDescriptor, error) {
+	if messageType == "" {
+		if file.Messages().Len() == 0 {
+			return nil, fmt.Errorf("schema does not define any message")
+		}
+		return file.Messages().Get(0), nil
+	}
+
+	var find func(messages protoreflect.MessageDescriptors) protoreflect.MessageDescriptor
+	find = func(messages protoreflect.MessageDescriptors) protoreflect.MessageDescriptor {
+		for i := 0; i < messages.Len(); i++ {
+			message := messages.Get(i)
+			if string(message.FullName()) == messageType {
+				return message
+			}
+			if nested := find(message.Messages()); nested != nil {
+				return nested
+			}
+		}
+		return nil
+	}
+
+	if message := find(file.Messages()); message != nil {
+		return message, nil
+	}
+
+	return nil, fmt.Errorf("message type %s not found in schema", messageType)
+}
+
+// validateProtobufPayload validates a JSON-decoded payload against a message of a
+// .proto schema using the proto3 JSON mapping
+func validateProtobufPayload(payload interface{}, schema types.Schema, references []types.ResolvedReference, messageType string) (bool, []string, error) {
+	file, err := ParseProtobufSchema(schema.Schema, references)
+	if err != nil {
+		return false, nil, fmt.Errorf("error parsing Protobuf schema: %w", err)
+	}
+
+	descriptor, err := findProtobufMessage(file, messageType)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// Walk the payload first to report every mismatch with its field path
+	if errors := validateProtobufMessage(descriptor, payload, ""); len(errors) > 0 {
+		return false, errors, nil
+	}
+
+	// protojson has the final say, it knows the JSON forms of the well-known types
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return false, nil, fmt.Errorf("error marshalling payload: %w", err)
+	}
+
+	message := dynamicpb.NewMessage(descriptor)
+	if err := protojson.Unmarshal(payloadJSON, message); err != nil {
+		return false, []string{fmt.Sprintf("%s: %v", payloadFieldName(""), err)}, nil
+	}
+
+	return true, nil, nil
+}
+
+// validateProtobufMessage checks a decoded JSON object against a message descriptor
+// following the proto3 JSON mapping. Fields can be named by JSON name or proto name.
+func validateProtobufMessage(descriptor protoreflect.MessageDescriptor, value any, path string) []string {
+	// Well-known types have their own JSON representations, protojson checks them
+	if descriptor.FullName().Parent() == "google.protobuf" {
+		return nil
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok {
+		return []string{invalidPayloadType(path, "object", value)}
+	}
+
+	var errors []string
+	setOneofs := make(map[protoreflect.FullName]string)
+	fields := descriptor.Fields()
+	for _, key := range sortedKeys(object) {
+		field := fields.ByJSONName(key)
+		if field == nil {
+			field = fields.ByName(protoreflect.Name(key))
+		}
+		if field == nil {
+			errors = append(errors, fmt.Sprintf("%s: Additional property %s is not allowed", payloadFieldName(path), key))
+			continue
+		}
+
+		// null stands for the default value of any field
+		fieldValue := object[key]
+		if fieldValue == nil {
+			continue
+		}
+
+		if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			if other, set := setOneofs[oneof.FullName()]; set {
+				errors = append(errors, fmt.Sprintf("%s: Only one of %s and %s can be set (oneof %s)",
+					payloadFieldName(path), other, key, oneof.Name()))
+			}
+			setOneofs[oneof.FullName()] = key
+		}
+
+		errors = append(errors, validateProtobufField(field, fieldValue, joinPayloadPath(path, key))...)
+	}
+
+	return errors
+}
+
+// validateProtobufField checks the value of a map, repeated or singular field
+func validateProtobufField(field protoreflect.FieldDescriptor, value any, path string) []string {
+	switch {
+	case field.IsMap():
+		entries, ok := value.(map[string]any)
+		if !ok {
+			return []string{invalidPayloadType(path, "object", value)}
+		}
+
+		var errors []string
+		for _, key := range sortedKeys(entries) {
+			errors = append(errors, validateProtobufScalar(field.MapKey(), key, joinPayloadPath(path, key))...)
+			errors = append(errors, validateProtobufValue(field.MapValue(), entries[key], joinPayloadPath(path, key))...)
+		}
+		return errors
+
+	case field.IsList():
+		items, ok := value.([]any)
+		if !ok {
+			return []string{invalidPayloadType(path, "array", value)}
+		}
+
+		var errors []string
+		for i, item := range items {
+			errors = append(errors, validateProtobufValue(field, item, joinPayloadPath(path, strconv.Itoa(i)))...)
+		}
+		return errors
+	}
+
+	return validateProtobufValue(field, value, path)
+}
+
+// validateProtobufValue checks a single value of a field
+func validateProtobufValue(field protoreflect.FieldDescriptor, value any, path string) []string {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return validateProtobufMessage(field.Message(), value, path)
+
+	case protoreflect.EnumKind:
+		if field.Enum().FullName() == "google.protobuf.NullValue" {
+			return nil
+		}
+		switch v := value.(type) {
+		case string:
+			if field.Enum().Values().ByName(protoreflect.Name(v)) != nil {
+				return nil
+			}
+			return []string{fmt.Sprintf("%s: %s must be one of the following: %s",
+				payloadFieldName(path), payloadFieldName(path), quoteAll(protobufEnumNames(field.Enum())))}
+		case float64:
+			// Enums accept their numeric values, unknown numbers included
+			if v == math.Trunc(v) {
+				return nil
+			}
+		}
+		return []string{invalidPayloadType(path, "enum", value)}
+	}
+
+	return validateProtobufScalar(field, value, path)
+}
+
+// validateProtobufScalar checks a scalar value. Numbers may be given as JSON
+// numbers or strings, as allowed by the proto3 JSON mapping.
+func validateProtobufScalar(field protoreflect.FieldDescriptor, value any, path string) []string {
+	kind := field.Kind()
+	switch kind {
+	case protoreflect.BoolKind:
+		switch v := value.(type) {
+		case bool:
+			return nil
+		case string:
+			// Map keys are always strings
+			if v == "true" || v == "false" {
+				return nil
+			}
+		}
+
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		if _, ok := protobufNumber(value); ok {
+			return nil
+		}
+
+	default:
+		number, ok := protobufNumber(value)
+		if !ok || number != math.Trunc(number) {
+			break
+		}
+
+		min, max := protobufIntegerRange(kind)
+		if number < min || number > max {
+			return []string{fmt.Sprintf("%s: Value %v is out of range for %s", payloadFieldName(path), value, kind)}
+		}
+		return nil
+	}
+
+	return []string{invalidPayloadType(path, kind.String(), value)}
+}
+
+// protobufNumber reads a number given either as a JSON number or a string
+func protobufNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		number, err := strconv.ParseFloat(v, 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// protobufIntegerRange returns the bounds of an integer kind
+func protobufIntegerRange(kind protoreflect.Kind) (float64, float64) {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return math.MinInt32, math.MaxInt32
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return 0, math.MaxUint32
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return 0, math.MaxUint64
+	default:
+		return math.MinInt64, math.MaxInt64
+	}
+}
+
+func protobufEnumNames(enum protoreflect.EnumDescriptor) []string {
+	names := make([]string, enum.Values().Len())
+	for i := range names {
+		names[i] = string(enum.Values().Get(i).Name())
+	}
+	return names
+}
+
+// TransformProtobufToSchemaFormat takes a .proto schema text and wraps it in the
+// Schema Registry format after checking that it compiles.
+//
+// Parameters:
+//   - protoStr: A string containing the .proto schema
+//   - references: The direct references sent along with the schema
+//   - resolved: Every schema reachable through the references, used to compile the schema
+//
+// Returns:
+//   - string: The JSON string in Schema Registry format
+//   - error: An error if the schema does not compile or if marshaling fails
+func (helper *Helpers) TransformProtobufToSchemaFormat(protoStr string, references []types.SchemaReference, resolved []types.ResolvedReference) (string, error) {
+	if protoStr == "" {
+		helper.logger.Debug("TransformProtobufToSchemaFormat - empty schema provided")
+		return "", fmt.Errorf("empty schema is not allowed")
+	}
+
+	if _, err := ParseProtobufSchema(protoStr, resolved); err != nil {
+		helper.logger.Debug("TransformProtobufToSchemaFormat - invalid Protobuf schema",
+			"error", err)
+		return "", fmt.Errorf("invalid Protobuf schema: %v", err)
+	}
+
+	formatted, err := json.Marshal(SchemaFormat{
+		Schema:     protoStr,
+		SchemaType: types.SchemaTypeProtobuf,
+		References: references,
+	})
+	if err != nil {
+		helper.logger.Debug("TransformProtobufToSchemaFormat - error formatting schema",
+			"error", err)
+		return "", fmt.Errorf("error formatting schema: %v", err)
+	}
+
+	return string(formatted), nil
+}
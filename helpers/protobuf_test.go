@@ -0,0 +1,124 @@
+package helpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kafka-board/types"
+)
+
+const testProtoSchema = `syntax = "proto3";
+package com.example;
+
+message Order {
+  int32 id = 1;
+  Status status = 2;
+  repeated string tags = 3;
+  message Line {
+    string sku = 1;
+    int64 quantity = 2;
+  }
+  repeated Line lines = 4;
+}
+
+message Customer {
+  string email_address = 1;
+}
+
+enum Status {
+  NEW = 0;
+  SHIPPED = 1;
+}
+`
+
+func TestProtobufMessageTypes(t *testing.T) {
+	messageTypes, err := ProtobufMessageTypes(testProtoSchema, nil)
+	if err != nil {
+		t.Fatalf("ProtobufMessageTypes() unexpected error: %v", err)
+	}
+
+	expected := []string{"com.example.Order", "com.example.Order.Line", "com.example.Customer"}
+	if !reflect.DeepEqual(messageTypes, expected) {
+		t.Errorf("ProtobufMessageTypes() = %v, want %v", messageTypes, expected)
+	}
+}
+
+func TestValidateProtobufPayload(t *testing.T) {
+	schema := types.Schema{
+		Id:         1,
+		SchemaType: types.SchemaTypeProtobuf,
+		Schema:     testProtoSchema,
+	}
+
+	tests := []struct {
+		name          string
+		payload       string
+		messageType   string
+		expectedValid bool
+		expectedError string
+	}{
+		{
+			name:          "valid payload for the first message",
+			payload:       `{"id": 1, "status": "SHIPPED", "tags": ["a"], "lines": [{"sku": "x", "quantity": "3"}]}`,
+			expectedValid: true,
+		},
+		{
+			name:          "valid payload for a chosen message using its JSON name",
+			payload:       `{"emailAddress": "a@example.com"}`,
+			messageType:   "com.example.Customer",
+			expectedValid: true,
+		},
+		{
+			name:          "unknown field",
+			payload:       `{"id": 1, "extra": true}`,
+			expectedValid: false,
+			expectedError: "(root): Additional property extra is not allowed",
+		},
+		{
+			name:          "wrong field type",
+			payload:       `{"id": "one"}`,
+			expectedValid: false,
+			expectedError: "id: Invalid type. Expected: int32, given: string",
+		},
+		{
+			name:          "nested message field error",
+			payload:       `{"lines": [{"sku": "x", "quantity": 1.5}]}`,
+			expectedValid: false,
+			expectedError: "lines.0.quantity: Invalid type. Expected: int64, given: number",
+		},
+		{
+			name:          "unknown enum value",
+			payload:       `{"status": "LOST"}`,
+			expectedValid: false,
+			expectedError: `status: status must be one of the following: "NEW", "SHIPPED"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var payload interface{}
+			if err := json.Unmarshal([]byte(test.payload), &payload); err != nil {
+				t.Fatalf("invalid test payload: %v", err)
+			}
+
+			isValid, errors, err := ValidatePayload(payload, schema, nil, test.messageType)
+			if err != nil {
+				t.Fatalf("ValidatePayload() unexpected error: %v", err)
+			}
+
+			if isValid != test.expectedValid {
+				t.Errorf("ValidatePayload() valid = %v, want %v (errors: %v)", isValid, test.expectedValid, errors)
+			}
+
+			if test.expectedError != "" && !strings.Contains(strings.Join(errors, "; "), test.expectedError) {
+				t.Errorf("Expected errors to contain '%s', got '%v'", test.expectedError, errors)
+			}
+		})
+	}
+
+	if _, _, err := ValidatePayload(map[string]any{}, schema, nil, "com.example.Missing"); err == nil {
+		t.Errorf("ValidatePayload() expected an error for an unknown message type")
+	}
+}
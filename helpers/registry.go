@@ -2,16 +2,19 @@ package helpers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
+	"kafka-board/registryErrors"
 	"kafka-board/types"
 )
 
 // SchemaFormat represents the structure required by the Schema Registry API endpoint
 type SchemaFormat struct {
-	Schema     string `json:"schema"`
-	SchemaType string `json:"schemaType"`
+	Schema     string                  `json:"schema"`
+	SchemaType string                  `json:"schemaType"`
+	References []types.SchemaReference `json:"references,omitempty"`
 }
 
 // isEmptyJSON checks if a parsed JSON value is empty (empty object, array, string, or null)
@@ -30,17 +33,31 @@ func (helper *Helpers) isEmptyJSON(jsonObj interface{}) bool {
 	}
 }
 
+// TransformToSchemaFormat wraps a schema of any supported type in the Schema Registry format.
+// JSON and Avro schemas are JSON documents, Protobuf schemas are .proto texts.
+// The direct references of the schema are sent along, resolved holds the whole
+// reference graph so the schema can be checked locally first.
+func (helper *Helpers) TransformToSchemaFormat(schema types.Schema, resolved []types.ResolvedReference) (string, error) {
+	if schema.GetSchemaType() == types.SchemaTypeProtobuf {
+		return helper.TransformProtobufToSchemaFormat(schema.Schema, schema.References, resolved)
+	}
+	return helper.TransformJSONToSchemaFormat(schema.Schema, schema.GetSchemaType(), schema.References, resolved)
+}
+
 // TransformJSONToSchemaFormat takes a JSON string and wraps it in the Schema Registry format.
 // It validates that the input is valid JSON before creating the wrapper structure.
-// This function is used to prepare JSON schemas for schema registry compatibility testing.
+// This function is used to prepare JSON and Avro schemas for schema registry compatibility testing.
 //
 // Parameters:
 //   - jsonStr: A string containing valid JSON to be wrapped
+//   - schemaType: The schema type to send to the registry (JSON or AVRO)
+//   - references: The direct references sent along with the schema
+//   - resolved: Every schema reachable through the references, used to parse Avro schemas
 //
 // Returns:
 //   - string: The JSON string in Schema Registry format
 //   - error: An error if the JSON is invalid or if marshaling fails
-func (helper *Helpers) TransformJSONToSchemaFormat(jsonStr string) (string, error) {
+func (helper *Helpers) TransformJSONToSchemaFormat(jsonStr string, schemaType string, references []types.SchemaReference, resolved []types.ResolvedReference) (string, error) {
 	// First validate the JSON by attempting to unmarshal it
 	var jsonObj interface{}
 
@@ -62,10 +79,24 @@ func (helper *Helpers) TransformJSONToSchemaFormat(jsonStr string) (string, erro
 		return "", fmt.Errorf("empty JSON is not allowed")
 	}
 
+	// Avro schemas are JSON documents too, but must also parse as Avro
+	switch schemaType {
+	case types.SchemaTypeJSON:
+	case types.SchemaTypeAvro:
+		if _, err := ParseAvroSchema(jsonStr, resolved); err != nil {
+			helper.logger.Debug("TransformJSONToSchemaFormat - invalid Avro schema",
+				"error", err)
+			return "", fmt.Errorf("invalid Avro schema: %v", err)
+		}
+	default:
+		return "", fmt.Errorf("unsupported schema type: %s", schemaType)
+	}
+
 	// Create the schema registry format wrapper
 	schemaRegistryFormat := SchemaFormat{
 		Schema:     jsonStr,
-		SchemaType: "JSON",
+		SchemaType: schemaType,
+		References: references,
 	}
 
 	// Marshal to JSON then unmarshal to map to check keys with key, ok idiom
@@ -109,14 +140,15 @@ func (helper *Helpers) TransformJSONToSchemaFormat(jsonStr string) (string, erro
 //
 // Returns:
 //   - Response: A structured response with compatibility information or error details
-//   - error: An error if processing fails or the registry reported an error
+//   - error: An error if processing fails, or a *registryErrors.Error if the registry reported one
 func (helper *Helpers) ProcessResponse(body []byte, statusCode int) (types.Response, error) {
 	// Handle different status codes appropriately
 	switch {
 	case statusCode == http.StatusOK:
 		// Success case - try to parse compatibility result
 		var result struct {
-			IsCompatible bool `json:"is_compatible"`
+			IsCompatible bool     `json:"is_compatible"`
+			Messages     []string `json:"messages"`
 		}
 
 		if err := json.Unmarshal(body, &result); err != nil {
@@ -132,84 +164,50 @@ func (helper *Helpers) ProcessResponse(body []byte, statusCode int) (types.Respo
 		}
 
 		helper.logger.Debug("ProcessResponse - success with valid compatibility result",
-			"isCompatible", result.IsCompatible)
+			"isCompatible", result.IsCompatible,
+			"messages", result.Messages)
 
 		// Success with valid compatibility result
-		return CreateResponseObject(
+		response := CreateResponseObject(
 			&result.IsCompatible,
 			"None", // Default message for success
 			statusCode,
 			0,
-		), nil
-
-	case statusCode == http.StatusNotFound || statusCode == http.StatusUnprocessableEntity:
-		// Expected error responses from registry - try to parse error details
-		var errorResponse struct {
-			ErrorCode int    `json:"error_code"`
-			Message   string `json:"message"`
-		}
-
-		if err := json.Unmarshal(body, &errorResponse); err != nil {
-			errMsg := fmt.Sprintf("error parsing error response: %v", err)
-
-			helper.logger.Debug("ProcessResponse - error parsing error response",
-				"error", errMsg)
-
-			return CreateResponseObject(
-				nil,
-				errMsg,
-				statusCode,
-				statusCode,
-			), fmt.Errorf("%s", errMsg)
+		)
+
+		// Verbose results explain why the schema is incompatible
+		if !result.IsCompatible {
+			response.Incompatibilities = ParseIncompatibilities(result.Messages)
+			if count := len(response.Incompatibilities); count > 0 {
+				response.Message = fmt.Sprintf("Incompatibilities found: %d", count)
+			}
 		}
 
-		// Ensure message has a value
-		if errorResponse.Message == "" {
-			errorResponse.Message = "Registry returned error with no message"
-		}
+		return response, nil
 
-		// Create descriptive error with registry details
-		err := fmt.Errorf("registry error: %s (code: %d, status: %d)",
-			errorResponse.Message,
-			errorResponse.ErrorCode,
-			statusCode)
+	default:
+		// The registry reported an error, e.g. an unknown version or an invalid schema
+		err := registryErrors.FromResponse(statusCode, body)
 
-		// Set IsCompatible to false for expected errors
-		falseVal := false
+		var registryErr *registryErrors.Error
+		errors.As(err, &registryErr)
 
-		helper.logger.Debug("ProcessResponse - error with expected error response",
+		helper.logger.Debug("ProcessResponse - error returned by the registry",
 			"error", err)
 
-		return CreateResponseObject(
-			&falseVal,
-			errorResponse.Message,
-			statusCode,
-			errorResponse.ErrorCode,
-		), err
-
-	case statusCode == http.StatusInternalServerError:
-		// Internal server error in registry
-		helper.logger.Debug("ProcessResponse - error with internal server error")
-
-		return CreateResponseObject(
-			nil,
-			"internal server error - attempt to validate schema failed",
-			statusCode,
-			0,
-		), fmt.Errorf("registry internal server error: status code %d", statusCode)
-
-	default:
-		// Unexpected status codes
-		errMsg := fmt.Sprintf("unexpected status code: %d, response: %s", statusCode, string(body))
-
-		helper.logger.Debug("ProcessResponse - error with unexpected status code",
-			"error", errMsg)
+		// Missing subjects and invalid schemas make the proposed schema incompatible,
+		// failures of the registry leave the compatibility undetermined
+		var isCompatible *bool
+		if errors.Is(err, registryErrors.ErrNotFound) || errors.Is(err, registryErrors.ErrInvalid) {
+			falseVal := false
+			isCompatible = &falseVal
+		}
 
 		return CreateResponseObject(
-			nil,
-			errMsg,
+			isCompatible,
+			registryErr.FriendlyMessage(),
 			statusCode,
-			statusCode,
-		), fmt.Errorf("registry returned unexpected status: %s", errMsg)
+			registryErr.Code,
+		), err
 	}
 }
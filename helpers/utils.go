@@ -1,7 +1,11 @@
 package helpers
 
 import (
+	"fmt"
 	"kafka-board/types"
+	"math"
+	"sort"
+	"strings"
 
 	"github.com/xeipuuv/gojsonschema"
 )
@@ -12,11 +16,30 @@ func CheckErr(e error) bool {
 	return e != nil
 }
 
-func ValidatePayload(payload interface{}, schema types.Schema) (bool, []string, error) {
-	schemaLoader := gojsonschema.NewStringLoader(schema.Schema)
-	documentLoader := gojsonschema.NewGoLoader(payload)
+// ValidatePayload validates a decoded JSON payload against a schema,
+// picking the validator from the schema type. references holds every schema
+// reachable through the references of schema. messageType selects the
+// Protobuf message to validate against and is ignored for other types.
+func ValidatePayload(payload interface{}, schema types.Schema, references []types.ResolvedReference, messageType string) (bool, []string, error) {
+	switch schema.GetSchemaType() {
+	case types.SchemaTypeJSON:
+		return validateJSONPayload(payload, schema, references)
+	case types.SchemaTypeAvro:
+		return validateAvroPayload(payload, schema, references)
+	case types.SchemaTypeProtobuf:
+		return validateProtobufPayload(payload, schema, references, messageType)
+	default:
+		return false, nil, fmt.Errorf("unsupported schema type: %s", schema.SchemaType)
+	}
+}
+
+func validateJSONPayload(payload interface{}, schema types.Schema, references []types.ResolvedReference) (bool, []string, error) {
+	compiledSchema, err := compileJSONSchema(schema.Schema, references)
+	if err != nil {
+		return false, nil, err
+	}
 
-	result, err := gojsonschema.Validate(schemaLoader, documentLoader)
+	result, err := compiledSchema.Validate(gojsonschema.NewGoLoader(payload))
 	if err != nil {
 		return false, nil, err
 	}
@@ -31,3 +54,63 @@ func ValidatePayload(payload interface{}, schema types.Schema) (bool, []string,
 
 	return true, nil, nil
 }
+
+// invalidPayloadType builds a gojsonschema style type mismatch message
+func invalidPayloadType(path string, expected string, value any) string {
+	return fmt.Sprintf("%s: Invalid type. Expected: %s, given: %s", payloadFieldName(path), expected, jsonTypeName(value))
+}
+
+// payloadFieldName mirrors gojsonschema by naming the top level value (root)
+func payloadFieldName(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+func joinPayloadPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// jsonTypeName returns the JSON type name of a decoded JSON value
+func jsonTypeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,254 @@
+// Package registryFake is an in-memory schema registry for the tests of the backup
+// and restore, in registryBackup and the admin handlers. Only _test.go files import it.
+//
+// It answers the way RegistryAPI does, errors included: listing a subject the
+// registry never had, or whose versions are all soft-deleted without asking for
+// deleted ones, fails with registryErrors.ErrSubjectNotFound, and so on. Every write
+// is recorded as one line in Writes.
+package registryFake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"kafka-board/registryErrors"
+	"kafka-board/types"
+)
+
+// Registry keeps subjects, configs and modes in memory
+type Registry struct {
+	// Versions of each subject in ascending order, soft-deleted ones have Deleted set
+	Versions map[string][]types.Schema
+	// Subject configs, subjects without one take the global config
+	Configs      map[string]types.SubjectConfig
+	GlobalConfig types.GlobalConfig
+	// Subject modes, subjects without one take the global mode
+	Modes      map[string]string
+	GlobalMode string
+	// Check tells whether a proposed schema is compatible, every schema is when nil
+	Check func(subjectName string, latestOnly bool, proposed types.Schema) types.Response
+	// Writes lists every write in order, e.g. `import "orders" v1 id 10`
+	Writes []string
+}
+
+// ReturnSubjects lists the subjects with a live version, sorted
+func (f *Registry) ReturnSubjects(ctx context.Context) ([]string, error) {
+	var subjects []string
+	for subjectName, versions := range f.Versions {
+		for _, version := range versions {
+			if !version.Deleted {
+				subjects = append(subjects, subjectName)
+				break
+			}
+		}
+	}
+	sort.Strings(subjects)
+	return subjects, nil
+}
+
+func (f *Registry) ReturnSubjectConfigs(ctx context.Context, subjectNames []string) ([]types.SubjectConfigInterface, error) {
+	configs := make([]types.SubjectConfigInterface, len(subjectNames))
+	for i, subjectName := range subjectNames {
+		configs[i] = types.SubjectGlobalConfig{Name: subjectName, TakesGlobalDefault: true}
+		if config, ok := f.Configs[subjectName]; ok {
+			config.Name = subjectName
+			config.SetDefaultNone()
+			configs[i] = config
+		}
+	}
+	return configs, nil
+}
+
+func (f *Registry) GetGlobalConfig(ctx context.Context) (types.GlobalConfig, error) {
+	global := f.GlobalConfig
+	global.SetDefaultNone()
+	return global, nil
+}
+
+// GetMode returns the global mode for an empty subject name
+func (f *Registry) GetMode(ctx context.Context, subjectName string) (types.SubjectMode, error) {
+	if subjectName == "" {
+		return types.SubjectMode{Mode: f.GlobalMode}, nil
+	}
+	mode, ok := f.Modes[subjectName]
+	return types.SubjectMode{Name: subjectName, Mode: mode, TakesGlobalDefault: !ok}, nil
+}
+
+func (f *Registry) ReturnSubjectModes(ctx context.Context, subjectNames []string) ([]types.SubjectMode, error) {
+	modes := make([]types.SubjectMode, len(subjectNames))
+	for i, subjectName := range subjectNames {
+		modes[i], _ = f.GetMode(ctx, subjectName)
+	}
+	return modes, nil
+}
+
+func (f *Registry) GetSchemas(ctx context.Context, subjectName string, includeDeleted bool) ([]types.Schema, error) {
+	var listed []types.Schema
+	for _, version := range f.Versions[subjectName] {
+		if includeDeleted || !version.Deleted {
+			listed = append(listed, types.Schema{Subject: subjectName, Version: version.Version, Deleted: version.Deleted})
+		}
+	}
+	if len(listed) == 0 {
+		return nil, registryErrors.ErrSubjectNotFound
+	}
+	return listed, nil
+}
+
+func (f *Registry) GetSubjectVersion(ctx context.Context, subjectName string, version int, includeDeleted bool) (types.Schema, error) {
+	versions, ok := f.Versions[subjectName]
+	if !ok {
+		return types.Schema{}, registryErrors.ErrSubjectNotFound
+	}
+	for _, schema := range versions {
+		if schema.Version == version && (includeDeleted || !schema.Deleted) {
+			schema.Subject = subjectName
+			schema.Deleted = false
+			return schema, nil
+		}
+	}
+	return types.Schema{}, registryErrors.ErrVersionNotFound
+}
+
+func (f *Registry) GetSchema(ctx context.Context, id string) (types.Schema, error) {
+	for _, versions := range f.Versions {
+		for _, schema := range versions {
+			if strconv.Itoa(schema.Id) == id {
+				return schema, nil
+			}
+		}
+	}
+	return types.Schema{}, registryErrors.ErrSchemaNotFound
+}
+
+// ResolveReferences fetches the schemas referenced by a schema, depth first
+func (f *Registry) ResolveReferences(ctx context.Context, schema types.Schema) ([]types.ResolvedReference, error) {
+	var resolved []types.ResolvedReference
+	for _, reference := range schema.References {
+		referenced, err := f.GetSubjectVersion(ctx, reference.Subject, reference.Version, false)
+		if err != nil {
+			return nil, err
+		}
+		nested, err := f.ResolveReferences(ctx, referenced)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(append(resolved, nested...), types.ResolvedReference{Name: reference.Name, Schema: referenced})
+	}
+	return resolved, nil
+}
+
+func (f *Registry) TestSchemaAgainstVersions(ctx context.Context, subjectName string, latestOnly bool, proposed types.Schema) (types.Response, error) {
+	if f.Check != nil {
+		return f.Check(subjectName, latestOnly, proposed), nil
+	}
+	isCompatible := true
+	return types.Response{IsCompatible: &isCompatible, Message: "The schema is compatible"}, nil
+}
+
+// ImportSchema adds a version with the ID and version number of the schema
+func (f *Registry) ImportSchema(ctx context.Context, subjectName string, schema types.Schema) error {
+	f.Writes = append(f.Writes, fmt.Sprintf("import %q v%d id %d", subjectName, schema.Version, schema.Id))
+	f.addVersion(subjectName, schema)
+	return nil
+}
+
+// RegisterSchema adds a version after the latest one, with an ID after every other
+func (f *Registry) RegisterSchema(ctx context.Context, subjectName string, proposed types.Schema, normalize bool) (types.Response, error) {
+	id, version := 0, 0
+	for _, versions := range f.Versions {
+		for _, schema := range versions {
+			id = max(id, schema.Id)
+		}
+	}
+	for _, schema := range f.Versions[subjectName] {
+		version = max(version, schema.Version)
+	}
+	id, version = id+1, version+1
+
+	f.Writes = append(f.Writes, fmt.Sprintf("register %q as v%d id %d", subjectName, version, id))
+	proposed.Version, proposed.Id = version, id
+	f.addVersion(subjectName, proposed)
+	return types.Response{Registered: &types.RegisteredSchema{Subject: subjectName, Id: id, Version: version}}, nil
+}
+
+func (f *Registry) DeleteSubjectVersion(ctx context.Context, subjectName string, version int, permanent bool) error {
+	write := fmt.Sprintf("delete %q v%d", subjectName, version)
+	if permanent {
+		write += " permanently"
+	}
+	f.Writes = append(f.Writes, write)
+
+	versions := f.Versions[subjectName]
+	for i := range versions {
+		if versions[i].Version == version {
+			if permanent {
+				f.Versions[subjectName] = append(versions[:i:i], versions[i+1:]...)
+				return nil
+			}
+			versions[i].Deleted = true
+			return nil
+		}
+	}
+	return registryErrors.ErrVersionNotFound
+}
+
+// UpdateConfig sets the config of a subject, or the global one for an empty name
+func (f *Registry) UpdateConfig(ctx context.Context, subjectName string, config types.ConfigPayload) error {
+	f.Writes = append(f.Writes, fmt.Sprintf("config %q %s", subjectName, config.Compatibility))
+
+	subjectConfig := types.SubjectConfig{
+		Name:               subjectName,
+		CompatibilityLevel: config.Compatibility,
+		Normalize:          config.Normalize,
+		Alias:              config.Alias,
+		CompatibilityGroup: config.CompatibilityGroup,
+		DefaultMetadata:    config.DefaultMetadata,
+		OverrideMetadata:   config.OverrideMetadata,
+		DefaultRuleSet:     config.DefaultRuleSet,
+		OverrideRuleSet:    config.OverrideRuleSet,
+	}
+	if subjectName == "" {
+		f.GlobalConfig = types.GlobalConfig(subjectConfig)
+		return nil
+	}
+	if f.Configs == nil {
+		f.Configs = make(map[string]types.SubjectConfig)
+	}
+	f.Configs[subjectName] = subjectConfig
+	return nil
+}
+
+// UpdateMode sets the mode of a subject, or the global one for an empty name
+func (f *Registry) UpdateMode(ctx context.Context, subjectName string, mode string, force bool) error {
+	f.Writes = append(f.Writes, fmt.Sprintf("mode %q %s force=%t", subjectName, mode, force))
+
+	if subjectName == "" {
+		f.GlobalMode = mode
+		return nil
+	}
+	if f.Modes == nil {
+		f.Modes = make(map[string]string)
+	}
+	f.Modes[subjectName] = mode
+	return nil
+}
+
+func (f *Registry) DeleteMode(ctx context.Context, subjectName string) error {
+	f.Writes = append(f.Writes, fmt.Sprintf("delete mode %q", subjectName))
+	delete(f.Modes, subjectName)
+	return nil
+}
+
+// addVersion stores a version of a subject, keeping versions in ascending order
+func (f *Registry) addVersion(subjectName string, schema types.Schema) {
+	if f.Versions == nil {
+		f.Versions = make(map[string][]types.Schema)
+	}
+	schema.Subject = subjectName
+	versions := append(f.Versions[subjectName], schema)
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	f.Versions[subjectName] = versions
+}
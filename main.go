@@ -5,7 +5,9 @@ import (
 	"kafka-board/confluentRegistryAPI"
 	"kafka-board/handlers"
 	"kafka-board/helpers"
+	"kafka-board/schemaLint"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -20,23 +22,82 @@ func main() {
 	// Initialize logger
 	logger = helpers.SetupLogger()
 
+	// Subcommands such as export and restore run instead of the server
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	// Every request context derives from baseCtx, canceling it stops the registry
+	// calls still running when the server shuts down
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	// Create server with timeouts
 	server := &http.Server{
 		Addr:         helpers.GetServerAddress(),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
+	}
+
+	// Initialize the registry clients, failing fast on bad credentials or TLS settings
+	registryAPIs, err := confluentRegistryAPI.ReturnRegistryAPIs(logger)
+	if err != nil {
+		logger.Error("Could not configure registry API",
+			"error", err)
+
+		os.Exit(1)
+	}
+
+	cacheConfig, err := confluentRegistryAPI.GetCacheConfig()
+	if err != nil {
+		logger.Error("Could not configure registry cache",
+			"error", err)
+
+		os.Exit(1)
+	}
+
+	// Registry reads are cached so that open dashboards do not hammer the registry
+	registries := make([]handlers.Registry, len(registryAPIs))
+	for i, registryAPI := range registryAPIs {
+		registries[i] = handlers.Registry{
+			Name:        registryAPI.Name(),
+			RegistryAPI: confluentRegistryAPI.ReturnCachedRegistryAPI(registryAPI, cacheConfig),
+		}
+	}
+
+	lintRules, err := schemaLint.GetRuleSet()
+	if err != nil {
+		logger.Error("Could not configure schema lint rules",
+			"error", err)
+
+		os.Exit(1)
 	}
 
 	// Initialize handler with logger
-	handler := handlers.ReturnHandler(logger, confluentRegistryAPI.ReturnRegistryAPI(logger))
+	handler := handlers.ReturnHandler(logger, registries, lintRules)
 
 	// Set up routes
 	http.HandleFunc("/", handler.HandleHomePage)
 	http.HandleFunc("/schema/", handler.HandleSchemaPage)
+	http.HandleFunc("/schema-version/", handler.HandleSchemaVersion)
+	http.HandleFunc("/schema-diff/", handler.HandleSchemaDiff)
+	http.HandleFunc("/fingerprint-search/", handler.HandleFingerprintSearch)
 	http.HandleFunc("/test-schema/", handler.HandleTestSchema)
 	http.HandleFunc("/health", handler.HandleHealthCheck)
 	http.HandleFunc("/test-payload", handler.HandleValidatePayload)
+	http.HandleFunc("/register-schema", handler.HandleRegisterSchema)
+	http.HandleFunc("/compatibility-level", handler.HandleCompatibilityLevel)
+	http.HandleFunc("/compatibility-level/preview", handler.HandleCompatibilityLevelPreview)
+	http.HandleFunc("/mode", handler.HandleMode)
+	http.HandleFunc("/delete-schema", handler.HandleDeleteSchema)
+	http.HandleFunc("/restore-version", handler.HandleRestoreVersion)
+	http.HandleFunc("/admin/cache/flush", handler.AdminOnly(handler.HandleCacheFlush))
+	http.HandleFunc("/admin/backup", handler.AdminOnly(handler.HandleBackup))
+	http.HandleFunc("/admin/restore", handler.AdminOnly(handler.HandleRestore))
 
 	// Channel to listen for errors coming from the listener.
 	serverErrors := make(chan error, 1)
@@ -70,6 +131,7 @@ func main() {
 			logger.Error("Could not stop server gracefully",
 				"error", err)
 
+			cancelRequests()
 			server.Close()
 		}
 	}
@@ -0,0 +1,287 @@
+package registryBackup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kafka-board/types"
+)
+
+const (
+	manifestFile = "manifest.json"
+	subjectsDir  = "subjects"
+	subjectFile  = "subject.json"
+	versionsDir  = "versions"
+)
+
+// IsTarball reports whether a backup path names a gzipped tarball rather than a directory
+func IsTarball(backupPath string) bool {
+	return strings.HasSuffix(backupPath, ".tar.gz") || strings.HasSuffix(backupPath, ".tgz")
+}
+
+// Write writes a backup to a directory, or to a gzipped tarball when the path ends
+// in .tar.gz or .tgz. Existing backups are never overwritten.
+func Write(backup Backup, backupPath string) error {
+	if IsTarball(backupPath) {
+		file, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return fmt.Errorf("error creating backup file: %v", err)
+		}
+		defer file.Close()
+
+		if err := WriteTarball(backup, file); err != nil {
+			os.Remove(backupPath)
+			return err
+		}
+		return file.Close()
+	}
+
+	if _, err := os.Stat(filepath.Join(backupPath, manifestFile)); err == nil {
+		return fmt.Errorf("%s already holds a backup", backupPath)
+	}
+
+	return backup.eachFile(func(name string, content []byte) error {
+		target := filepath.Join(backupPath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("error creating backup directory: %v", err)
+		}
+		if err := os.WriteFile(target, content, 0o644); err != nil {
+			return fmt.Errorf("error writing backup file: %v", err)
+		}
+		return nil
+	})
+}
+
+// WriteTarball writes a backup as a gzipped tarball
+func WriteTarball(backup Backup, w io.Writer) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err := backup.eachFile(func(name string, content []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(content)),
+			ModTime: backup.Manifest.ExportedAt,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("error writing backup tarball: %v", err)
+		}
+		if _, err := tarWriter.Write(content); err != nil {
+			return fmt.Errorf("error writing backup tarball: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("error writing backup tarball: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("error writing backup tarball: %v", err)
+	}
+	return nil
+}
+
+// Read reads a backup from a directory or a gzipped tarball
+func Read(backupPath string) (Backup, error) {
+	if IsTarball(backupPath) {
+		file, err := os.Open(backupPath)
+		if err != nil {
+			return Backup{}, fmt.Errorf("error opening backup file: %v", err)
+		}
+		defer file.Close()
+
+		return ReadTarball(file)
+	}
+
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(backupPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(backupPath, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(name)] = content
+		return nil
+	})
+	if err != nil {
+		return Backup{}, fmt.Errorf("error reading backup directory: %v", err)
+	}
+
+	return parseFiles(files)
+}
+
+// ReadTarball reads a backup from a gzipped tarball
+func ReadTarball(r io.Reader) (Backup, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return Backup{}, fmt.Errorf("error reading backup tarball: %v", err)
+	}
+	defer gzipReader.Close()
+
+	files := make(map[string][]byte)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Backup{}, fmt.Errorf("error reading backup tarball: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return Backup{}, fmt.Errorf("error reading backup tarball: %v", err)
+		}
+		files[path.Clean(header.Name)] = content
+	}
+
+	return parseFiles(files)
+}
+
+// eachFile calls write with the name and content of every file of the backup, in a
+// stable order
+func (b Backup) eachFile(write func(name string, content []byte) error) error {
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling manifest: %v", err)
+	}
+	if err := write(manifestFile, manifest); err != nil {
+		return err
+	}
+
+	for _, subject := range b.Subjects {
+		dir := path.Join(subjectsDir, escapeName(subject.Name))
+
+		content, err := json.MarshalIndent(subject, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling subject %s: %v", subject.Name, err)
+		}
+		if err := write(path.Join(dir, subjectFile), content); err != nil {
+			return err
+		}
+
+		for _, version := range subject.Versions {
+			content, err := json.MarshalIndent(version, "", "  ")
+			if err != nil {
+				return fmt.Errorf("error marshalling version %d of %s: %v", version.Version, subject.Name, err)
+			}
+			if err := write(path.Join(dir, versionsDir, strconv.Itoa(version.Version)+".json"), content); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseFiles builds a backup from its files, keyed by slash-separated path
+func parseFiles(files map[string][]byte) (Backup, error) {
+	content, ok := files[manifestFile]
+	if !ok {
+		return Backup{}, fmt.Errorf("not a backup, %s is missing", manifestFile)
+	}
+
+	var backup Backup
+	if err := json.Unmarshal(content, &backup.Manifest); err != nil {
+		return Backup{}, fmt.Errorf("error parsing %s: %v", manifestFile, err)
+	}
+	if backup.Manifest.FormatVersion != FormatVersion {
+		return Backup{}, fmt.Errorf("unsupported backup format version %d, expected %d", backup.Manifest.FormatVersion, FormatVersion)
+	}
+
+	// Versions are matched to their subject by directory
+	subjects := make(map[string]*Subject)
+	var dirs []string
+	for name, content := range files {
+		dir, file := path.Split(name)
+		if file != subjectFile || path.Dir(path.Clean(dir)) != subjectsDir {
+			continue
+		}
+
+		subject := &Subject{}
+		if err := json.Unmarshal(content, subject); err != nil {
+			return Backup{}, fmt.Errorf("error parsing %s: %v", name, err)
+		}
+		subjects[dir] = subject
+		dirs = append(dirs, dir)
+	}
+
+	for name, content := range files {
+		versionDir, file := path.Split(name)
+		if path.Base(versionDir) != versionsDir || !strings.HasSuffix(file, ".json") {
+			continue
+		}
+		subject, ok := subjects[path.Dir(path.Clean(versionDir))+"/"]
+		if !ok {
+			return Backup{}, fmt.Errorf("version file %s has no subject.json", name)
+		}
+
+		var version types.Schema
+		if err := json.Unmarshal(content, &version); err != nil {
+			return Backup{}, fmt.Errorf("error parsing %s: %v", name, err)
+		}
+		subject.Versions = append(subject.Versions, version)
+	}
+
+	// Subjects keep the order of the manifest, versions are sorted
+	order := make(map[string]int, len(backup.Manifest.Subjects))
+	for i, name := range backup.Manifest.Subjects {
+		order[name] = i
+	}
+	for _, dir := range dirs {
+		subject := subjects[dir]
+		if _, ok := order[subject.Name]; !ok {
+			return Backup{}, fmt.Errorf("subject %s is not listed in %s", subject.Name, manifestFile)
+		}
+		sort.Slice(subject.Versions, func(i, j int) bool { return subject.Versions[i].Version < subject.Versions[j].Version })
+		backup.Subjects = append(backup.Subjects, *subject)
+	}
+	sort.Slice(backup.Subjects, func(i, j int) bool { return order[backup.Subjects[i].Name] < order[backup.Subjects[j].Name] })
+
+	if len(backup.Subjects) != len(backup.Manifest.Subjects) {
+		return Backup{}, fmt.Errorf("backup holds %d subjects, %s lists %d", len(backup.Subjects), manifestFile, len(backup.Manifest.Subjects))
+	}
+
+	return backup, nil
+}
+
+// escapeName turns a subject name into a directory name, escaping every character
+// but letters, digits, '-' and '_' as %XX so names like ".." or "a/b" stay one
+// harmless path segment
+func escapeName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c == '-' || c == '_' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
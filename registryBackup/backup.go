@@ -0,0 +1,158 @@
+// Package registryBackup exports the content of a schema registry, subjects,
+// versions, configs and modes, and restores it into an empty registry.
+//
+// A backup is written as a directory, or as a gzipped tarball of the same files:
+//
+//	manifest.json                          format version, global config and mode
+//	subjects/<subject>/subject.json        subject name, config and mode
+//	subjects/<subject>/versions/<n>.json   one version with its ID, type and references
+//
+// Subject directory names are escaped, the subject name is read from subject.json.
+// Restores run in IMPORT mode so every schema keeps its ID and version.
+package registryBackup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kafka-board/helpers"
+	"kafka-board/types"
+)
+
+// FormatVersion is the version of the backup layout, restores refuse other versions
+const FormatVersion = 1
+
+// Source is the registry a backup is exported from
+type Source interface {
+	ReturnSubjects(ctx context.Context) ([]string, error)
+	ReturnSubjectConfigs(ctx context.Context, subjectNames []string) ([]types.SubjectConfigInterface, error)
+	GetGlobalConfig(ctx context.Context) (types.GlobalConfig, error)
+	GetMode(ctx context.Context, subjectName string) (types.SubjectMode, error)
+	ReturnSubjectModes(ctx context.Context, subjectNames []string) ([]types.SubjectMode, error)
+	GetSchemas(ctx context.Context, subjectName string, includeDeleted bool) ([]types.Schema, error)
+	GetSubjectVersion(ctx context.Context, subjectName string, version int, includeDeleted bool) (types.Schema, error)
+}
+
+// Backup is the content of a registry
+type Backup struct {
+	Manifest Manifest
+	Subjects []Subject
+}
+
+// Manifest describes a backup and holds the registry-wide settings
+type Manifest struct {
+	FormatVersion int `json:"formatVersion"`
+	// Name of the registry the backup was exported from
+	Registry     string              `json:"registry"`
+	ExportedAt   time.Time           `json:"exportedAt"`
+	GlobalConfig types.ConfigPayload `json:"globalConfig"`
+	GlobalMode   string              `json:"globalMode"`
+	Subjects     []string            `json:"subjects"`
+}
+
+// Subject is one subject of a backup with every version, soft-deleted ones included
+type Subject struct {
+	Name string `json:"subject"`
+	// Nil when the subject takes the global config
+	Config *types.ConfigPayload `json:"config,omitempty"`
+	// Empty when the subject takes the global mode
+	Mode string `json:"mode,omitempty"`
+	// Stored in files of their own
+	Versions []types.Schema `json:"-"`
+}
+
+// VersionCount returns the number of versions in the backup, and how many of them
+// are soft-deleted
+func (b Backup) VersionCount() (int, int) {
+	versions, deleted := 0, 0
+	for _, subject := range b.Subjects {
+		for _, version := range subject.Versions {
+			versions++
+			if version.Deleted {
+				deleted++
+			}
+		}
+	}
+	return versions, deleted
+}
+
+// Export reads every subject of the registry with its versions, config and mode.
+// Soft-deleted versions of live subjects are exported and flagged. Any read that
+// fails fails the export, a backup is never partial.
+func Export(ctx context.Context, source Source, registryName string) (Backup, error) {
+	subjects, err := source.ReturnSubjects(ctx)
+	if helpers.CheckErr(err) {
+		return Backup{}, fmt.Errorf("error listing subjects: %w", err)
+	}
+
+	globalConfig, err := source.GetGlobalConfig(ctx)
+	if helpers.CheckErr(err) {
+		return Backup{}, fmt.Errorf("error fetching the global config: %w", err)
+	}
+
+	globalMode, err := source.GetMode(ctx, "")
+	if helpers.CheckErr(err) {
+		return Backup{}, fmt.Errorf("error fetching the global mode: %w", err)
+	}
+
+	configs, err := source.ReturnSubjectConfigs(ctx, subjects)
+	if helpers.CheckErr(err) {
+		return Backup{}, fmt.Errorf("error fetching subject configs: %w", err)
+	}
+
+	modes, err := source.ReturnSubjectModes(ctx, subjects)
+	if helpers.CheckErr(err) {
+		return Backup{}, fmt.Errorf("error fetching subject modes: %w", err)
+	}
+
+	backup := Backup{
+		Manifest: Manifest{
+			FormatVersion: FormatVersion,
+			Registry:      registryName,
+			ExportedAt:    time.Now().UTC(),
+			GlobalConfig:  types.SubjectConfig(globalConfig).Payload(),
+			GlobalMode:    globalMode.Mode,
+			Subjects:      subjects,
+		},
+		Subjects: make([]Subject, 0, len(subjects)),
+	}
+
+	for i, subjectName := range subjects {
+		subject := Subject{Name: subjectName}
+
+		switch config := configs[i].(type) {
+		case types.SubjectConfig:
+			payload := config.Payload()
+			subject.Config = &payload
+		case types.SubjectConfigError:
+			return Backup{}, fmt.Errorf("error fetching the config of %s: %s", subjectName, config.Error)
+		}
+
+		if modes[i].Error != "" {
+			return Backup{}, fmt.Errorf("error fetching the mode of %s: %s", subjectName, modes[i].Error)
+		}
+		if !modes[i].TakesGlobalDefault {
+			subject.Mode = modes[i].Mode
+		}
+
+		versions, err := source.GetSchemas(ctx, subjectName, true)
+		if helpers.CheckErr(err) {
+			return Backup{}, fmt.Errorf("error listing the versions of %s: %w", subjectName, err)
+		}
+
+		for _, listed := range versions {
+			version, err := source.GetSubjectVersion(ctx, subjectName, listed.Version, true)
+			if helpers.CheckErr(err) {
+				return Backup{}, fmt.Errorf("error fetching version %d of %s: %w", listed.Version, subjectName, err)
+			}
+			version.Subject = subjectName
+			version.Deleted = listed.Deleted
+			subject.Versions = append(subject.Versions, version)
+		}
+
+		backup.Subjects = append(backup.Subjects, subject)
+	}
+
+	return backup, nil
+}
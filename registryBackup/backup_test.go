@@ -0,0 +1,139 @@
+package registryBackup
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"kafka-board/internal/registryFake"
+	"kafka-board/types"
+)
+
+func sourceRegistry() *registryFake.Registry {
+	return &registryFake.Registry{
+		Versions: map[string][]types.Schema{
+			"orders": {
+				{Subject: "orders", Version: 1, Id: 10, Schema: `{"type": "string"}`},
+				{Subject: "orders", Version: 2, Id: 12, Schema: `{"type": "record", "name": "Order", "fields": [{"name": "payment", "type": "Payment"}]}`,
+					References: []types.SchemaReference{{Name: "Payment", Subject: ":.team-a:../payments", Version: 1}}},
+				{Subject: "orders", Version: 3, Id: 14, Schema: `{"type": "int"}`, Deleted: true},
+			},
+			":.team-a:../payments": {
+				{Subject: ":.team-a:../payments", Version: 1, Id: 11, SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object"}`},
+			},
+		},
+		Configs:      map[string]types.SubjectConfig{"orders": {CompatibilityLevel: "FULL"}},
+		GlobalConfig: types.GlobalConfig{CompatibilityLevel: "BACKWARD"},
+		Modes:        map[string]string{":.team-a:../payments": types.ModeReadOnly},
+		GlobalMode:   types.ModeReadWrite,
+	}
+}
+
+func TestExportAndRestore(t *testing.T) {
+	backup, err := Export(context.Background(), sourceRegistry(), "dev")
+	if err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+
+	if backup.Manifest.GlobalConfig != (types.ConfigPayload{Compatibility: "BACKWARD"}) {
+		t.Errorf("GlobalConfig = %+v, want only BACKWARD", backup.Manifest.GlobalConfig)
+	}
+	if versions, deleted := backup.VersionCount(); versions != 4 || deleted != 1 {
+		t.Errorf("VersionCount() = %d, %d, want 4, 1", versions, deleted)
+	}
+
+	dir := t.TempDir()
+	for _, backupPath := range []string{filepath.Join(dir, "backup"), filepath.Join(dir, "backup.tar.gz")} {
+		t.Run(filepath.Base(backupPath), func(t *testing.T) {
+			if err := Write(backup, backupPath); err != nil {
+				t.Fatalf("Write() unexpected error: %v", err)
+			}
+			if err := Write(backup, backupPath); err == nil {
+				t.Errorf("Write() over an existing backup succeeded, want an error")
+			}
+
+			read, err := Read(backupPath)
+			if err != nil {
+				t.Fatalf("Read() unexpected error: %v", err)
+			}
+			if !read.Manifest.ExportedAt.Equal(backup.Manifest.ExportedAt) {
+				t.Errorf("ExportedAt = %v, want %v", read.Manifest.ExportedAt, backup.Manifest.ExportedAt)
+			}
+			read.Manifest.ExportedAt = backup.Manifest.ExportedAt
+			if !reflect.DeepEqual(read, backup) {
+				t.Errorf("Read() = %+v, want %+v", read, backup)
+			}
+
+			target := &registryFake.Registry{}
+			report, err := Restore(context.Background(), target, read)
+			if err != nil {
+				t.Fatalf("Restore() unexpected error: %v", err)
+			}
+
+			expectedWrites := []string{
+				`mode "" IMPORT force=false`,
+				`import "orders" v1 id 10`,
+				`import ":.team-a:../payments" v1 id 11`,
+				`import "orders" v2 id 12`,
+				`import "orders" v3 id 14`,
+				`delete "orders" v3`,
+				`config "" BACKWARD`,
+				`config "orders" FULL`,
+				`mode ":.team-a:../payments" READONLY force=false`,
+				`mode "" READWRITE force=false`,
+			}
+			if !reflect.DeepEqual(target.Writes, expectedWrites) {
+				t.Errorf("writes = %q, want %q", target.Writes, expectedWrites)
+			}
+
+			expectedReport := RestoreReport{Subjects: 2, Versions: 4, Deleted: 1, Configs: 2, Modes: 2}
+			if report != expectedReport {
+				t.Errorf("Restore() = %+v, want %+v", report, expectedReport)
+			}
+
+			restored, err := Export(context.Background(), target, "dev")
+			if err != nil {
+				t.Fatalf("Export() of the restored registry unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(restored.Subjects, backup.Subjects) {
+				t.Errorf("restored subjects = %+v, want %+v", restored.Subjects, backup.Subjects)
+			}
+		})
+	}
+}
+
+func TestRestoreIntoNonEmptyRegistry(t *testing.T) {
+	target := &registryFake.Registry{Versions: map[string][]types.Schema{"orders": {{Version: 1, Id: 1, Schema: `{"type": "string"}`}}}}
+
+	_, err := Restore(context.Background(), target, Backup{})
+	if !errors.Is(err, ErrRegistryNotEmpty) {
+		t.Errorf("Restore() error = %v, want %v", err, ErrRegistryNotEmpty)
+	}
+	if len(target.Writes) > 0 {
+		t.Errorf("writes = %q, want none", target.Writes)
+	}
+}
+
+func TestRestoreKeepingImportMode(t *testing.T) {
+	backup := Backup{
+		Manifest: Manifest{GlobalMode: types.ModeImport},
+		Subjects: []Subject{{Name: "orders", Versions: []types.Schema{{Version: 1, Id: 10, Schema: `{"type": "string"}`}}}},
+	}
+	target := &registryFake.Registry{}
+
+	report, err := Restore(context.Background(), target, backup)
+	if err != nil {
+		t.Fatalf("Restore() unexpected error: %v", err)
+	}
+
+	// The registry was switched to IMPORT for the restore and is left there
+	expectedWrites := []string{`mode "" IMPORT force=false`, `import "orders" v1 id 10`}
+	if !reflect.DeepEqual(target.Writes, expectedWrites) {
+		t.Errorf("writes = %q, want %q", target.Writes, expectedWrites)
+	}
+	if report.Modes != 0 {
+		t.Errorf("Modes = %d, want 0", report.Modes)
+	}
+}
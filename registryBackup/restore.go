@@ -0,0 +1,140 @@
+package registryBackup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"kafka-board/helpers"
+	"kafka-board/types"
+)
+
+// ErrRegistryNotEmpty is returned when restoring into a registry that has subjects
+var ErrRegistryNotEmpty = errors.New("the registry already has subjects, backups are only restored into an empty registry")
+
+// Target is the registry a backup is restored into
+type Target interface {
+	ReturnSubjects(ctx context.Context) ([]string, error)
+	ImportSchema(ctx context.Context, subjectName string, schema types.Schema) error
+	DeleteSubjectVersion(ctx context.Context, subjectName string, version int, permanent bool) error
+	UpdateConfig(ctx context.Context, subjectName string, config types.ConfigPayload) error
+	UpdateMode(ctx context.Context, subjectName string, mode string, force bool) error
+}
+
+// RestoreReport counts what a restore wrote to the registry
+type RestoreReport struct {
+	Subjects int `json:"subjects"`
+	Versions int `json:"versions"`
+	// Versions soft-deleted again after their import
+	Deleted int `json:"deleted"`
+	Configs int `json:"configs"`
+	Modes   int `json:"modes"`
+}
+
+// Restore replays a backup into an empty registry:
+//
+//  1. the registry is switched to IMPORT mode
+//  2. every version is imported with its ID and version, in ID order so referenced
+//     schemas, registered first, are there before the schemas using them
+//  3. soft-deleted versions are soft-deleted again
+//  4. the global and subject configs are set
+//  5. the subject modes are set, then the global mode of the backup
+//
+// A restore stopping on an error leaves the registry as far as it got, in IMPORT
+// mode, and returns what was written so far.
+func Restore(ctx context.Context, target Target, backup Backup) (RestoreReport, error) {
+	report := RestoreReport{}
+
+	subjects, err := target.ReturnSubjects(ctx)
+	if helpers.CheckErr(err) {
+		return report, fmt.Errorf("error listing subjects: %w", err)
+	}
+	if len(subjects) > 0 {
+		return report, ErrRegistryNotEmpty
+	}
+
+	if err := target.UpdateMode(ctx, "", types.ModeImport, false); helpers.CheckErr(err) {
+		return report, fmt.Errorf("error switching the registry to IMPORT mode: %w", err)
+	}
+
+	var versions []types.Schema
+	for _, subject := range backup.Subjects {
+		for _, version := range subject.Versions {
+			version.Subject = subject.Name
+			versions = append(versions, version)
+		}
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		if versions[i].Id != versions[j].Id {
+			return versions[i].Id < versions[j].Id
+		}
+		return versions[i].Version < versions[j].Version
+	})
+
+	imported := make(map[string]bool)
+	for _, version := range versions {
+		if err := target.ImportSchema(ctx, version.Subject, version); helpers.CheckErr(err) {
+			return report, fmt.Errorf("error importing version %d of %s: %w", version.Version, version.Subject, err)
+		}
+		report.Versions++
+		if !imported[version.Subject] {
+			imported[version.Subject] = true
+			report.Subjects++
+		}
+	}
+
+	// Versions referencing a soft-deleted one are deleted first, so go newest first
+	for i := len(versions) - 1; i >= 0; i-- {
+		version := versions[i]
+		if !version.Deleted {
+			continue
+		}
+		if err := target.DeleteSubjectVersion(ctx, version.Subject, version.Version, false); helpers.CheckErr(err) {
+			return report, fmt.Errorf("error soft-deleting version %d of %s: %w", version.Version, version.Subject, err)
+		}
+		report.Deleted++
+	}
+
+	// Configs can hold metadata maps, which the == operator cannot compare
+	if !reflect.ValueOf(backup.Manifest.GlobalConfig).IsZero() {
+		if err := target.UpdateConfig(ctx, "", backup.Manifest.GlobalConfig); helpers.CheckErr(err) {
+			return report, fmt.Errorf("error setting the global config: %w", err)
+		}
+		report.Configs++
+	}
+	for _, subject := range backup.Subjects {
+		if subject.Config == nil {
+			continue
+		}
+		if err := target.UpdateConfig(ctx, subject.Name, *subject.Config); helpers.CheckErr(err) {
+			return report, fmt.Errorf("error setting the config of %s: %w", subject.Name, err)
+		}
+		report.Configs++
+	}
+
+	// Subjects holding schemas only switch to IMPORT when forced
+	for _, subject := range backup.Subjects {
+		if subject.Mode == "" {
+			continue
+		}
+		if err := target.UpdateMode(ctx, subject.Name, subject.Mode, subject.Mode == types.ModeImport); helpers.CheckErr(err) {
+			return report, fmt.Errorf("error setting the mode of %s: %w", subject.Name, err)
+		}
+		report.Modes++
+	}
+
+	globalMode := backup.Manifest.GlobalMode
+	if globalMode == "" {
+		globalMode = types.ModeReadWrite
+	}
+	if globalMode != types.ModeImport {
+		if err := target.UpdateMode(ctx, "", globalMode, false); helpers.CheckErr(err) {
+			return report, fmt.Errorf("error setting the global mode back to %s: %w", globalMode, err)
+		}
+		report.Modes++
+	}
+
+	return report, nil
+}
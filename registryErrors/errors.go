@@ -0,0 +1,179 @@
+// Package registryErrors maps the error responses of the schema registry to Go errors.
+//
+// Every documented Confluent error code has a sentinel, so callers can tell the
+// failures apart with errors.Is, e.g. errors.Is(err, registryErrors.ErrSubjectNotFound),
+// or read the code and the registry message with errors.As into an *Error.
+// Codes without a sentinel still match the class of their HTTP status, e.g. an
+// unknown 422xx code matches ErrInvalid and keeps the registry message.
+package registryErrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error is an error response of the schema registry
+type Error struct {
+	// Confluent error code, e.g. 40401. Responses without one carry their HTTP status.
+	Code int
+	// HTTP status of the registry response
+	StatusCode int
+	// Message returned by the registry
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("registry error %d: %s", e.Code, e.Message)
+}
+
+// Is matches registry errors by code, so that errors returned by the registry
+// match the sentinel of their code, and by class, see ErrNotFound
+func (e *Error) Is(target error) bool {
+	if class, ok := target.(*errorClass); ok {
+		return class.matches(e)
+	}
+
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// FriendlyMessage describes the error for people using the board. The registry
+// message is kept where it says what to fix, e.g. why a schema is invalid.
+func (e *Error) FriendlyMessage() string {
+	known, ok := knownCodes[e.Code]
+	switch {
+	case !ok && e.Message != "":
+		return e.Message
+	case !ok:
+		return fmt.Sprintf("The registry returned an error (code %d)", e.Code)
+	case known.withDetail && e.Message != "" && e.Message != known.description:
+		return known.description + ": " + e.Message
+	}
+	return known.description
+}
+
+// HTTPStatus returns the status the board answers with when a registry call
+// fails with this error. Failures of the registry itself are reported as 502.
+func (e *Error) HTTPStatus() int {
+	switch {
+	case errors.Is(e, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(e, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(e, ErrInvalid):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadGateway
+}
+
+// Sentinels of the documented Confluent error codes
+var (
+	ErrSubjectNotFound            = newCode(40401, "The subject does not exist", false)
+	ErrVersionNotFound            = newCode(40402, "The version does not exist for this subject", false)
+	ErrSchemaNotFound             = newCode(40403, "No schema is registered with this ID", false)
+	ErrSubjectSoftDeleted         = newCode(40404, "The subject was soft-deleted", false)
+	ErrSubjectNotSoftDeleted      = newCode(40405, "The subject must be soft-deleted before it is deleted permanently", false)
+	ErrVersionSoftDeleted         = newCode(40406, "The version was soft-deleted", false)
+	ErrVersionNotSoftDeleted      = newCode(40407, "The version must be soft-deleted before it is deleted permanently", false)
+	ErrSubjectCompatibilityNotSet = newCode(40408, "The subject has no compatibility level of its own", false)
+	ErrSubjectModeNotSet          = newCode(40409, "The subject has no mode of its own", false)
+	ErrIncompatibleSchema         = newCode(409, "The schema is not compatible with the earlier versions", true)
+	ErrInvalidSchema              = newCode(42201, "The schema is not valid", true)
+	ErrInvalidVersion             = newCode(42202, "The version is not valid", true)
+	ErrInvalidCompatibilityLevel  = newCode(42203, "The compatibility level is not valid", true)
+	ErrInvalidMode                = newCode(42204, "The mode is not valid", true)
+	ErrOperationNotPermitted      = newCode(42205, "The registry mode does not permit this operation", true)
+	ErrReferenceExists            = newCode(42206, "The schema is referenced by other schemas", true)
+	ErrIDDoesNotMatch             = newCode(42207, "The schema ID is already used by another schema", true)
+	ErrInvalidSubject             = newCode(42208, "The subject name is not valid", true)
+	ErrSchemaTooLarge             = newCode(42209, "The schema is too large", true)
+	ErrInvalidRuleSet             = newCode(42210, "The rule set of the schema is not valid", true)
+	ErrInvalidMetadata            = newCode(42211, "The metadata of the schema is not valid", true)
+	ErrBackendStore               = newCode(50001, "The registry could not read or write its data store", false)
+	ErrOperationTimedOut          = newCode(50002, "The registry timed out", false)
+	ErrForwardingToPrimary        = newCode(50003, "The registry could not forward the request to its primary node", false)
+	ErrUnauthorized               = newCode(401, "The registry rejected the board's credentials", false)
+	ErrForbidden                  = newCode(403, "The board's credentials are not allowed to do this", false)
+	ErrUnknownEndpoint            = newCode(404, "The registry does not support this endpoint", false)
+	ErrUnprocessableRequest       = newCode(422, "The registry could not process the request", true)
+	ErrInternalServerError        = newCode(500, "The registry failed to handle the request", false)
+)
+
+// Classes of registry errors, matching every code of their HTTP status family
+var (
+	// ErrNotFound matches the 404 errors, e.g. a missing subject, version or schema
+	ErrNotFound error = &errorClass{name: "not found", status: http.StatusNotFound}
+	// ErrConflict matches the 409 errors, i.e. incompatible schemas
+	ErrConflict error = &errorClass{name: "conflict", status: http.StatusConflict}
+	// ErrInvalid matches the 422 errors, e.g. an invalid schema or version
+	ErrInvalid error = &errorClass{name: "invalid", status: http.StatusUnprocessableEntity}
+	// ErrServer matches the 5xx errors of the registry itself
+	ErrServer error = &errorClass{name: "server error", status: http.StatusInternalServerError}
+)
+
+type knownCode struct {
+	description string
+	withDetail  bool
+}
+
+var knownCodes = map[int]knownCode{}
+
+func newCode(code int, description string, withDetail bool) *Error {
+	sentinel := &Error{Code: code, StatusCode: statusOfCode(code), Message: description}
+	knownCodes[code] = knownCode{description: description, withDetail: withDetail}
+	return sentinel
+}
+
+// statusOfCode returns the HTTP status a Confluent error code is sent with:
+// 40401 is sent as 404, 409 as 409
+func statusOfCode(code int) int {
+	for code >= 1000 {
+		code /= 10
+	}
+	return code
+}
+
+// errorClass matches registry errors by the HTTP status family of their code
+type errorClass struct {
+	name   string
+	status int
+}
+
+func (c *errorClass) Error() string {
+	return "registry " + c.name
+}
+
+func (c *errorClass) matches(e *Error) bool {
+	status := statusOfCode(e.Code)
+	if c.status == http.StatusInternalServerError {
+		return status >= 500
+	}
+	return status == c.status
+}
+
+// FromResponse returns the error of a failed registry response. The error code and
+// message are read from the body, {"error_code": 40401, "message": "..."}, falling
+// back to the HTTP status and the raw body when the body is not a registry error.
+func FromResponse(statusCode int, body []byte) error {
+	var response struct {
+		ErrorCode int    `json:"error_code"`
+		Message   string `json:"message"`
+	}
+
+	registryErr := &Error{Code: statusCode, StatusCode: statusCode}
+	if err := json.Unmarshal(body, &response); err == nil && response.ErrorCode != 0 {
+		registryErr.Code = response.ErrorCode
+		registryErr.Message = response.Message
+	} else {
+		registryErr.Message = strings.TrimSpace(string(body))
+	}
+
+	if registryErr.Message == "" {
+		registryErr.Message = http.StatusText(statusCode)
+	}
+
+	return registryErr
+}
@@ -0,0 +1,131 @@
+package registryErrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestFromResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		body        string
+		wantIs      []error
+		wantNotIs   []error
+		wantCode    int
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name:        "subject not found",
+			statusCode:  http.StatusNotFound,
+			body:        `{"error_code":40401,"message":"Subject 'orders' not found."}`,
+			wantIs:      []error{ErrSubjectNotFound, ErrNotFound},
+			wantNotIs:   []error{ErrVersionNotFound, ErrInvalid},
+			wantCode:    40401,
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "The subject does not exist",
+		},
+		{
+			name:        "version not found",
+			statusCode:  http.StatusNotFound,
+			body:        `{"error_code":40402,"message":"Version 7 not found."}`,
+			wantIs:      []error{ErrVersionNotFound, ErrNotFound},
+			wantNotIs:   []error{ErrSubjectNotFound},
+			wantCode:    40402,
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "The version does not exist for this subject",
+		},
+		{
+			name:        "invalid schema keeps the reason given by the registry",
+			statusCode:  http.StatusUnprocessableEntity,
+			body:        `{"error_code":42201,"message":"Invalid schema: unknown type foo"}`,
+			wantIs:      []error{ErrInvalidSchema, ErrInvalid},
+			wantCode:    42201,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantMessage: "The schema is not valid: Invalid schema: unknown type foo",
+		},
+		{
+			name:        "invalid rule sets keep the reason given by the registry",
+			statusCode:  http.StatusUnprocessableEntity,
+			body:        `{"error_code":42210,"message":"Invalid rule set: missing rule name"}`,
+			wantIs:      []error{ErrInvalidRuleSet, ErrInvalid},
+			wantNotIs:   []error{ErrInvalidSchema, ErrInvalidMetadata},
+			wantCode:    42210,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantMessage: "The rule set of the schema is not valid: Invalid rule set: missing rule name",
+		},
+		{
+			name:        "invalid metadata",
+			statusCode:  http.StatusUnprocessableEntity,
+			body:        `{"error_code":42211,"message":"Invalid metadata"}`,
+			wantIs:      []error{ErrInvalidMetadata, ErrInvalid},
+			wantNotIs:   []error{ErrInvalidRuleSet},
+			wantCode:    42211,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantMessage: "The metadata of the schema is not valid: Invalid metadata",
+		},
+		{
+			name:        "unknown 422xx codes match the invalid class",
+			statusCode:  http.StatusUnprocessableEntity,
+			body:        `{"error_code":42299,"message":"Some future validation error"}`,
+			wantIs:      []error{ErrInvalid},
+			wantNotIs:   []error{ErrInvalidSchema, ErrNotFound, ErrServer},
+			wantCode:    42299,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantMessage: "Some future validation error",
+		},
+		{
+			name:        "backend errors are reported as a bad gateway",
+			statusCode:  http.StatusInternalServerError,
+			body:        `{"error_code":50001,"message":"Error in the backend data store"}`,
+			wantIs:      []error{ErrBackendStore, ErrServer},
+			wantCode:    50001,
+			wantStatus:  http.StatusBadGateway,
+			wantMessage: "The registry could not read or write its data store",
+		},
+		{
+			name:        "bodies without an error code fall back to the HTTP status",
+			statusCode:  http.StatusServiceUnavailable,
+			body:        "upstream connect error",
+			wantIs:      []error{ErrServer},
+			wantCode:    http.StatusServiceUnavailable,
+			wantStatus:  http.StatusBadGateway,
+			wantMessage: "upstream connect error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Errors stay recognizable once wrapped by the API client
+			err := fmt.Errorf("error fetching version: %w", FromResponse(tt.statusCode, []byte(tt.body)))
+
+			for _, target := range tt.wantIs {
+				if !errors.Is(err, target) {
+					t.Errorf("errors.Is(%v, %v) = false, want true", err, target)
+				}
+			}
+			for _, target := range tt.wantNotIs {
+				if errors.Is(err, target) {
+					t.Errorf("errors.Is(%v, %v) = true, want false", err, target)
+				}
+			}
+
+			var registryErr *Error
+			if !errors.As(err, &registryErr) {
+				t.Fatalf("errors.As(%v) = false, want a *Error", err)
+			}
+			if registryErr.Code != tt.wantCode {
+				t.Errorf("Code = %d, want %d", registryErr.Code, tt.wantCode)
+			}
+			if got := registryErr.HTTPStatus(); got != tt.wantStatus {
+				t.Errorf("HTTPStatus() = %d, want %d", got, tt.wantStatus)
+			}
+			if got := registryErr.FriendlyMessage(); got != tt.wantMessage {
+				t.Errorf("FriendlyMessage() = %q, want %q", got, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package registrySync
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteDiff writes a plan as one line per subject, prefixed with + for versions to
+// push, ~ for a config to update, ! for a conflict and = for a subject in sync,
+// followed by a summary line
+func (p Plan) WriteDiff(w io.Writer) error {
+	pushes, configs, conflicts := 0, 0, 0
+
+	var b strings.Builder
+	for _, subject := range p.Subjects {
+		switch {
+		case subject.Conflict != "":
+			conflicts++
+			fmt.Fprintf(&b, "! %s: %s\n", subject.Subject, subject.Conflict)
+			continue
+		case len(subject.Push) == 0 && subject.Config == nil:
+			fmt.Fprintf(&b, "= %s: %s in sync\n", subject.Subject, plural(subject.InSync, "version"))
+			continue
+		}
+
+		if len(subject.Push) > 0 {
+			pushes += len(subject.Push)
+			versions := make([]string, len(subject.Push))
+			for i, push := range subject.Push {
+				versions[i] = fmt.Sprintf("%d (ID %d)", push.Version, push.Id)
+				if !push.KeepId {
+					versions[i] = fmt.Sprintf("%d (ID %d taken, new ID)", push.Version, push.Id)
+				}
+			}
+			fmt.Fprintf(&b, "+ %s: push version %s, %d in sync\n", subject.Subject, strings.Join(versions, ", "), subject.InSync)
+		}
+
+		if subject.Config != nil {
+			configs++
+			fmt.Fprintf(&b, "~ %s: config %s\n", subject.Subject, strings.Join(configChanges(subject.Config), ", "))
+		}
+	}
+
+	mode := "full"
+	if p.Incremental {
+		mode = "incremental"
+	}
+	fmt.Fprintf(&b, "%s compared (%s), %s to push, %s to update, %s\n",
+		plural(len(p.Subjects), "subject"), mode, plural(pushes, "version"), plural(configs, "config"), plural(conflicts, "conflict"))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteSummary writes what a sync pushed, one line per subject, followed by a
+// summary line
+func (r Report) WriteSummary(w io.Writer) error {
+	pushed, configs := 0, 0
+
+	var b strings.Builder
+	for _, subject := range r.Subjects {
+		pushed += len(subject.Pushed)
+
+		var done []string
+		for _, version := range subject.Pushed {
+			if version.Id == version.SourceId && version.Version == version.SourceVersion {
+				done = append(done, fmt.Sprintf("version %d (ID %d)", version.Version, version.Id))
+				continue
+			}
+			done = append(done, fmt.Sprintf("version %d (ID %d) as version %d (ID %d)", version.SourceVersion, version.SourceId, version.Version, version.Id))
+		}
+		if subject.ConfigUpdated {
+			configs++
+			done = append(done, "config")
+		}
+
+		line := "pushed " + strings.Join(done, ", ")
+		if len(done) == 0 {
+			line = "nothing pushed"
+		}
+		if subject.Error != "" {
+			line += ", stopped: " + subject.Error
+		}
+		fmt.Fprintf(&b, "%s: %s\n", subject.Subject, line)
+	}
+
+	fmt.Fprintf(&b, "%s and %s pushed, %s failed\n", plural(pushed, "version"), plural(configs, "config"), plural(r.Failed(), "subject"))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// configChanges lists the settings a config change sets, e.g.
+// "compatibility BACKWARD -> FULL"
+func configChanges(change *ConfigChange) []string {
+	from := map[string]string{}
+	if change.From != nil {
+		from = change.From.Settings()
+	}
+	to := change.To.Settings()
+
+	names := make([]string, 0, len(to))
+	for name := range to {
+		names = append(names, name)
+	}
+	for name := range from {
+		if _, ok := to[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	value := func(settings map[string]string, name string) string {
+		if value, ok := settings[name]; ok {
+			return value
+		}
+		return "unset"
+	}
+
+	var changes []string
+	for _, name := range names {
+		if value(from, name) != value(to, name) {
+			changes = append(changes, fmt.Sprintf("%s %s -> %s", name, value(from, name), value(to, name)))
+		}
+	}
+	return changes
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
@@ -0,0 +1,505 @@
+// Package registrySync copies subjects selected by glob patterns from one schema
+// registry to another, with their versions and configs.
+//
+// A sync is planned first with Diff, which compares the subjects of both registries
+// without writing anything, then carried out with Apply. Versions are pushed in
+// IMPORT mode so they keep their ID and version number, unless the target already
+// uses the ID for another schema. Subjects whose versions differ between the two
+// registries are reported as conflicts and left alone.
+package registrySync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+
+	"kafka-board/helpers"
+	"kafka-board/registryErrors"
+	"kafka-board/types"
+)
+
+// Source is the registry subjects are copied from
+type Source interface {
+	ReturnSubjects(ctx context.Context) ([]string, error)
+	ReturnSubjectConfigs(ctx context.Context, subjectNames []string) ([]types.SubjectConfigInterface, error)
+	GetSchemas(ctx context.Context, subjectName string, includeDeleted bool) ([]types.Schema, error)
+	GetSubjectVersion(ctx context.Context, subjectName string, version int, includeDeleted bool) (types.Schema, error)
+}
+
+// Target is the registry subjects are copied to
+type Target interface {
+	Source
+	GetSchema(ctx context.Context, id string) (types.Schema, error)
+	GetMode(ctx context.Context, subjectName string) (types.SubjectMode, error)
+	ImportSchema(ctx context.Context, subjectName string, schema types.Schema) error
+	RegisterSchema(ctx context.Context, subjectName string, proposed types.Schema, normalize bool) (types.Response, error)
+	UpdateConfig(ctx context.Context, subjectName string, config types.ConfigPayload) error
+	UpdateMode(ctx context.Context, subjectName string, mode string, force bool) error
+	DeleteMode(ctx context.Context, subjectName string) error
+}
+
+// Options select the subjects to sync and how they are compared
+type Options struct {
+	// Glob patterns in path.Match syntax, e.g. "orders-*". Every subject when empty.
+	Subjects []string
+	// Only compare the latest version of each target subject with the source and push
+	// the versions after it, rather than comparing every version
+	Incremental bool
+}
+
+// Validate checks the subject patterns
+func (o Options) Validate() error {
+	for _, pattern := range o.Subjects {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid subject pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// selects reports whether a subject matches one of the patterns
+func (o Options) selects(subjectName string) bool {
+	if len(o.Subjects) == 0 {
+		return true
+	}
+	for _, pattern := range o.Subjects {
+		if matched, _ := path.Match(pattern, subjectName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan lists what a sync would write to the target registry
+type Plan struct {
+	Incremental bool          `json:"incremental"`
+	Subjects    []SubjectPlan `json:"subjects"`
+}
+
+// SubjectPlan is the difference between a subject in the source and in the target
+type SubjectPlan struct {
+	Subject string `json:"subject"`
+	// Versions of the source already in the target
+	InSync int           `json:"inSync"`
+	Push   []VersionPush `json:"push,omitempty"`
+	// Nil when the configs match or the source subject takes the global config
+	Config *ConfigChange `json:"config,omitempty"`
+	// Why the subject is left alone, e.g. a version differing between the registries
+	Conflict string `json:"conflict,omitempty"`
+}
+
+// VersionPush is a version of the source missing from the target
+type VersionPush struct {
+	Version int `json:"version"`
+	// ID in the source registry
+	Id int `json:"id"`
+	// False when the target uses the ID for another schema, the version is then
+	// registered with a new ID and the next free version number
+	KeepId bool         `json:"keepId"`
+	Schema types.Schema `json:"-"`
+}
+
+// ConfigChange is a subject config set in the source and different in the target
+type ConfigChange struct {
+	// Nil when the target subject takes the global config
+	From *types.ConfigPayload `json:"from"`
+	To   types.ConfigPayload  `json:"to"`
+}
+
+// Diff compares the selected subjects of both registries and plans the sync. It
+// only reads, a failed read fails the whole plan. Soft-deleted versions of the
+// source are not synced.
+func Diff(ctx context.Context, source Source, target Target, options Options) (Plan, error) {
+	if err := options.Validate(); err != nil {
+		return Plan{}, err
+	}
+
+	subjects, err := source.ReturnSubjects(ctx)
+	if helpers.CheckErr(err) {
+		return Plan{}, fmt.Errorf("error listing source subjects: %w", err)
+	}
+
+	var selected []string
+	for _, subjectName := range subjects {
+		if options.selects(subjectName) {
+			selected = append(selected, subjectName)
+		}
+	}
+
+	sourceConfigs, err := source.ReturnSubjectConfigs(ctx, selected)
+	if helpers.CheckErr(err) {
+		return Plan{}, fmt.Errorf("error fetching source configs: %w", err)
+	}
+	targetConfigs, err := target.ReturnSubjectConfigs(ctx, selected)
+	if helpers.CheckErr(err) {
+		return Plan{}, fmt.Errorf("error fetching target configs: %w", err)
+	}
+
+	plan := Plan{Incremental: options.Incremental, Subjects: make([]SubjectPlan, 0, len(selected))}
+	for i, subjectName := range selected {
+		subjectPlan, err := diffVersions(ctx, source, target, subjectName, options.Incremental)
+		if helpers.CheckErr(err) {
+			return Plan{}, err
+		}
+
+		if subjectPlan.Conflict == "" {
+			subjectPlan.Config, err = diffConfig(sourceConfigs[i], targetConfigs[i])
+			if helpers.CheckErr(err) {
+				return Plan{}, err
+			}
+		}
+
+		plan.Subjects = append(plan.Subjects, subjectPlan)
+	}
+
+	return plan, nil
+}
+
+// diffVersions compares the live versions of a subject in both registries. Only
+// versions after the latest one of the target are pushed, a version missing below
+// it or differing from the source is a conflict.
+func diffVersions(ctx context.Context, source Source, target Target, subjectName string, incremental bool) (SubjectPlan, error) {
+	subjectPlan := SubjectPlan{Subject: subjectName}
+
+	sourceVersions, err := source.GetSchemas(ctx, subjectName, false)
+	if helpers.CheckErr(err) {
+		return subjectPlan, fmt.Errorf("error listing the source versions of %s: %w", subjectName, err)
+	}
+
+	// Soft-deleted target versions are listed too, their numbers are taken. A subject
+	// the target never had has no versions.
+	targetVersions, err := target.GetSchemas(ctx, subjectName, true)
+	if helpers.CheckErr(err) && !errors.Is(err, registryErrors.ErrSubjectNotFound) {
+		return subjectPlan, fmt.Errorf("error listing the target versions of %s: %w", subjectName, err)
+	}
+
+	inTarget := make(map[int]bool, len(targetVersions))
+	targetLatest := 0
+	for _, version := range targetVersions {
+		inTarget[version.Version] = true
+		targetLatest = max(targetLatest, version.Version)
+	}
+
+	sourceLatest := 0
+	for _, version := range sourceVersions {
+		sourceLatest = max(sourceLatest, version.Version)
+	}
+	if targetLatest > sourceLatest {
+		subjectPlan.Conflict = fmt.Sprintf("the target has version %d, the source stops at version %d", targetLatest, sourceLatest)
+		return subjectPlan, nil
+	}
+
+	for _, version := range sourceVersions {
+		if version.Version > targetLatest {
+			schema, err := source.GetSubjectVersion(ctx, subjectName, version.Version, false)
+			if helpers.CheckErr(err) {
+				return subjectPlan, fmt.Errorf("error fetching source version %d of %s: %w", version.Version, subjectName, err)
+			}
+
+			keepId, err := idAvailable(ctx, target, schema)
+			if helpers.CheckErr(err) {
+				return subjectPlan, err
+			}
+
+			subjectPlan.Push = append(subjectPlan.Push, VersionPush{
+				Version: schema.Version,
+				Id:      schema.Id,
+				KeepId:  keepId,
+				Schema:  schema,
+			})
+			continue
+		}
+
+		if !inTarget[version.Version] {
+			subjectPlan.Conflict = fmt.Sprintf("version %d is missing from the target", version.Version)
+			subjectPlan.Push = nil
+			return subjectPlan, nil
+		}
+
+		// Versions below the latest of the target are trusted in incremental mode
+		if incremental && version.Version != targetLatest {
+			subjectPlan.InSync++
+			continue
+		}
+
+		same, err := sameVersion(ctx, source, target, subjectName, version.Version)
+		if helpers.CheckErr(err) {
+			return subjectPlan, err
+		}
+		if !same {
+			subjectPlan.Conflict = fmt.Sprintf("version %d differs in the target", version.Version)
+			subjectPlan.Push = nil
+			return subjectPlan, nil
+		}
+		subjectPlan.InSync++
+	}
+
+	return subjectPlan, nil
+}
+
+// sameVersion reports whether a version has the same schema in both registries
+func sameVersion(ctx context.Context, source Source, target Target, subjectName string, version int) (bool, error) {
+	sourceSchema, err := source.GetSubjectVersion(ctx, subjectName, version, false)
+	if helpers.CheckErr(err) {
+		return false, fmt.Errorf("error fetching source version %d of %s: %w", version, subjectName, err)
+	}
+
+	targetSchema, err := target.GetSubjectVersion(ctx, subjectName, version, true)
+	if helpers.CheckErr(err) {
+		return false, fmt.Errorf("error fetching target version %d of %s: %w", version, subjectName, err)
+	}
+
+	return sameSchema(sourceSchema, targetSchema), nil
+}
+
+// idAvailable reports whether a schema can be imported with its ID, i.e. the
+// target has no schema with that ID or has the same schema under it
+func idAvailable(ctx context.Context, target Target, schema types.Schema) (bool, error) {
+	existing, err := target.GetSchema(ctx, strconv.Itoa(schema.Id))
+	if errors.Is(err, registryErrors.ErrNotFound) {
+		return true, nil
+	}
+	if helpers.CheckErr(err) {
+		return false, fmt.Errorf("error looking up ID %d in the target: %w", schema.Id, err)
+	}
+
+	return sameSchema(existing, schema), nil
+}
+
+// sameSchema compares the text, type and references of two schemas
+func sameSchema(a types.Schema, b types.Schema) bool {
+	if a.Schema != b.Schema || a.GetSchemaType() != b.GetSchemaType() {
+		return false
+	}
+	if len(a.References) == 0 && len(b.References) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.References, b.References)
+}
+
+// diffConfig compares the config of a subject in both registries. Subjects taking
+// the global config in the source leave the target config as it is.
+func diffConfig(sourceConfig types.SubjectConfigInterface, targetConfig types.SubjectConfigInterface) (*ConfigChange, error) {
+	var change ConfigChange
+
+	switch config := sourceConfig.(type) {
+	case types.SubjectConfig:
+		change.To = config.Payload()
+	case types.SubjectConfigError:
+		return nil, fmt.Errorf("error fetching the source config of %s: %s", config.Name, config.Error)
+	default:
+		return nil, nil
+	}
+
+	switch config := targetConfig.(type) {
+	case types.SubjectConfig:
+		from := config.Payload()
+		// Configs can hold metadata maps, which the == operator cannot compare
+		if reflect.DeepEqual(from, change.To) {
+			return nil, nil
+		}
+		change.From = &from
+	case types.SubjectConfigError:
+		return nil, fmt.Errorf("error fetching the target config of %s: %s", config.Name, config.Error)
+	}
+
+	return &change, nil
+}
+
+// Report lists what a sync wrote to the target registry
+type Report struct {
+	Subjects []SubjectReport `json:"subjects"`
+}
+
+// SubjectReport is what a sync wrote for one subject
+type SubjectReport struct {
+	Subject       string          `json:"subject"`
+	Pushed        []PushedVersion `json:"pushed"`
+	ConfigUpdated bool            `json:"configUpdated"`
+	// Set when the sync of the subject stopped on an error
+	Error string `json:"error,omitempty"`
+}
+
+// PushedVersion is a version written to the target
+type PushedVersion struct {
+	SourceVersion int `json:"sourceVersion"`
+	SourceId      int `json:"sourceId"`
+	// Version and ID in the target, the source ones unless the ID was taken
+	Version int `json:"version"`
+	Id      int `json:"id"`
+}
+
+// Failed returns the number of subjects whose sync stopped on an error
+func (r Report) Failed() int {
+	failed := 0
+	for _, subject := range r.Subjects {
+		if subject.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// versionPush is a version to push with its subject
+type versionPush struct {
+	subject string
+	VersionPush
+	// Highest source ID of the versions of the subject up to this one
+	orderId int
+}
+
+// Apply carries out a plan. Versions of every subject are pushed in ID order, so
+// referenced schemas, registered first, reach the target before the schemas using
+// them, while the versions of a subject keep their order. A subject stops at its
+// first error, the others carry on. Subjects switched to IMPORT mode get their mode
+// back at the end.
+func Apply(ctx context.Context, target Target, plan Plan) Report {
+	report := Report{}
+	reports := make(map[string]*SubjectReport)
+	var pushes []versionPush
+
+	for _, subjectPlan := range plan.Subjects {
+		if subjectPlan.Conflict != "" || (len(subjectPlan.Push) == 0 && subjectPlan.Config == nil) {
+			continue
+		}
+		report.Subjects = append(report.Subjects, SubjectReport{Subject: subjectPlan.Subject, Pushed: []PushedVersion{}})
+
+		orderId := 0
+		for _, push := range subjectPlan.Push {
+			orderId = max(orderId, push.Id)
+			pushes = append(pushes, versionPush{subject: subjectPlan.Subject, VersionPush: push, orderId: orderId})
+		}
+	}
+	for i := range report.Subjects {
+		reports[report.Subjects[i].Subject] = &report.Subjects[i]
+	}
+
+	sort.SliceStable(pushes, func(i, j int) bool { return pushes[i].orderId < pushes[j].orderId })
+
+	modes := &importModes{target: target, original: make(map[string]types.SubjectMode)}
+	for _, push := range pushes {
+		subjectReport := reports[push.subject]
+		if subjectReport.Error != "" {
+			continue
+		}
+
+		pushed, err := pushVersion(ctx, target, modes, push)
+		if helpers.CheckErr(err) {
+			subjectReport.Error = err.Error()
+			continue
+		}
+		subjectReport.Pushed = append(subjectReport.Pushed, pushed)
+	}
+
+	for _, subjectName := range modes.switched {
+		if err := modes.restore(ctx, subjectName); helpers.CheckErr(err) && reports[subjectName].Error == "" {
+			reports[subjectName].Error = err.Error()
+		}
+	}
+
+	for _, subjectPlan := range plan.Subjects {
+		subjectReport, ok := reports[subjectPlan.Subject]
+		if !ok || subjectPlan.Config == nil || subjectReport.Error != "" {
+			continue
+		}
+		if err := target.UpdateConfig(ctx, subjectPlan.Subject, subjectPlan.Config.To); helpers.CheckErr(err) {
+			subjectReport.Error = fmt.Sprintf("error updating the config: %v", err)
+			continue
+		}
+		subjectReport.ConfigUpdated = true
+	}
+
+	return report
+}
+
+// pushVersion imports a version with its ID and version number, or registers it
+// when its ID is taken in the target
+func pushVersion(ctx context.Context, target Target, modes *importModes, push versionPush) (PushedVersion, error) {
+	pushed := PushedVersion{SourceVersion: push.Version, SourceId: push.Id}
+
+	if push.KeepId {
+		if err := modes.switchToImport(ctx, push.subject); helpers.CheckErr(err) {
+			return pushed, err
+		}
+		if err := target.ImportSchema(ctx, push.subject, push.Schema); helpers.CheckErr(err) {
+			return pushed, fmt.Errorf("error importing version %d: %w", push.Version, err)
+		}
+
+		pushed.Version, pushed.Id = push.Version, push.Id
+		return pushed, nil
+	}
+
+	// Registering new IDs is not allowed in IMPORT mode
+	if err := modes.restore(ctx, push.subject); helpers.CheckErr(err) {
+		return pushed, err
+	}
+	response, err := target.RegisterSchema(ctx, push.subject, push.Schema, false)
+	if helpers.CheckErr(err) {
+		return pushed, fmt.Errorf("error registering version %d: %w", push.Version, err)
+	}
+	if response.Registered == nil {
+		return pushed, fmt.Errorf("error registering version %d: %s", push.Version, response.Message)
+	}
+
+	pushed.Version, pushed.Id = response.Registered.Version, response.Registered.Id
+	return pushed, nil
+}
+
+// importModes switches target subjects to IMPORT mode and back, remembering the
+// mode each subject had before
+type importModes struct {
+	target Target
+	// Modes of the subjects currently in IMPORT mode, from before the switch
+	original map[string]types.SubjectMode
+	// Every subject switched at some point, in order
+	switched []string
+}
+
+// switchToImport puts a subject in IMPORT mode, unless it already is
+func (m *importModes) switchToImport(ctx context.Context, subjectName string) error {
+	if _, ok := m.original[subjectName]; ok {
+		return nil
+	}
+
+	mode, err := m.target.GetMode(ctx, subjectName)
+	if helpers.CheckErr(err) {
+		return fmt.Errorf("error fetching the target mode: %w", err)
+	}
+
+	// Subjects holding schemas only switch to IMPORT when forced
+	if err := m.target.UpdateMode(ctx, subjectName, types.ModeImport, true); helpers.CheckErr(err) {
+		return fmt.Errorf("error switching the target subject to IMPORT mode: %w", err)
+	}
+
+	m.original[subjectName] = mode
+	if !slices.Contains(m.switched, subjectName) {
+		m.switched = append(m.switched, subjectName)
+	}
+	return nil
+}
+
+// restore gives a subject switched to IMPORT mode its mode back
+func (m *importModes) restore(ctx context.Context, subjectName string) error {
+	mode, ok := m.original[subjectName]
+	if !ok {
+		return nil
+	}
+
+	var err error
+	if mode.TakesGlobalDefault {
+		err = m.target.DeleteMode(ctx, subjectName)
+	} else {
+		err = m.target.UpdateMode(ctx, subjectName, mode.Mode, mode.Mode == types.ModeImport)
+	}
+	if helpers.CheckErr(err) {
+		return fmt.Errorf("error setting the target subject mode back: %w", err)
+	}
+
+	delete(m.original, subjectName)
+	return nil
+}
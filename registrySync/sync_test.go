@@ -0,0 +1,358 @@
+package registrySync
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"kafka-board/confluentRegistryAPI"
+	"kafka-board/registryErrors"
+	"kafka-board/types"
+)
+
+// fakeRegistry keeps subjects in memory and records every write
+type fakeRegistry struct {
+	versions map[string][]types.Schema
+	configs  map[string]types.SubjectConfig
+	modes    map[string]string
+	writes   []string
+}
+
+func (f *fakeRegistry) ReturnSubjects(ctx context.Context) ([]string, error) {
+	var subjects []string
+	for subjectName := range f.versions {
+		subjects = append(subjects, subjectName)
+	}
+	sort.Strings(subjects)
+	return subjects, nil
+}
+
+func (f *fakeRegistry) ReturnSubjectConfigs(ctx context.Context, subjectNames []string) ([]types.SubjectConfigInterface, error) {
+	configs := make([]types.SubjectConfigInterface, len(subjectNames))
+	for i, subjectName := range subjectNames {
+		configs[i] = types.SubjectGlobalConfig{Name: subjectName, TakesGlobalDefault: true}
+		if config, ok := f.configs[subjectName]; ok {
+			config.Name = subjectName
+			config.SetDefaultNone()
+			configs[i] = config
+		}
+	}
+	return configs, nil
+}
+
+func (f *fakeRegistry) GetSchemas(ctx context.Context, subjectName string, includeDeleted bool) ([]types.Schema, error) {
+	var listed []types.Schema
+	for _, version := range f.versions[subjectName] {
+		listed = append(listed, types.Schema{Subject: subjectName, Version: version.Version})
+	}
+	return listed, nil
+}
+
+func (f *fakeRegistry) GetSubjectVersion(ctx context.Context, subjectName string, version int, includeDeleted bool) (types.Schema, error) {
+	for _, schema := range f.versions[subjectName] {
+		if schema.Version == version {
+			return schema, nil
+		}
+	}
+	return types.Schema{}, fmt.Errorf("version %d of %s not found", version, subjectName)
+}
+
+func (f *fakeRegistry) GetSchema(ctx context.Context, id string) (types.Schema, error) {
+	for _, versions := range f.versions {
+		for _, schema := range versions {
+			if fmt.Sprint(schema.Id) == id {
+				return schema, nil
+			}
+		}
+	}
+	return types.Schema{}, registryErrors.ErrSchemaNotFound
+}
+
+func (f *fakeRegistry) GetMode(ctx context.Context, subjectName string) (types.SubjectMode, error) {
+	mode, ok := f.modes[subjectName]
+	return types.SubjectMode{Name: subjectName, Mode: mode, TakesGlobalDefault: !ok}, nil
+}
+
+func (f *fakeRegistry) ImportSchema(ctx context.Context, subjectName string, schema types.Schema) error {
+	f.writes = append(f.writes, fmt.Sprintf("import %s v%d id %d", subjectName, schema.Version, schema.Id))
+	f.versions[subjectName] = append(f.versions[subjectName], schema)
+	return nil
+}
+
+func (f *fakeRegistry) RegisterSchema(ctx context.Context, subjectName string, proposed types.Schema, normalize bool) (types.Response, error) {
+	id, version := 100, len(f.versions[subjectName])+1
+	f.writes = append(f.writes, fmt.Sprintf("register %s as v%d id %d", subjectName, version, id))
+
+	proposed.Version, proposed.Id = version, id
+	f.versions[subjectName] = append(f.versions[subjectName], proposed)
+	return types.Response{Registered: &types.RegisteredSchema{Subject: subjectName, Id: id, Version: version}}, nil
+}
+
+func (f *fakeRegistry) UpdateConfig(ctx context.Context, subjectName string, config types.ConfigPayload) error {
+	f.writes = append(f.writes, fmt.Sprintf("config %s %s", subjectName, config.Compatibility))
+	return nil
+}
+
+func (f *fakeRegistry) UpdateMode(ctx context.Context, subjectName string, mode string, force bool) error {
+	f.writes = append(f.writes, fmt.Sprintf("mode %s %s force=%t", subjectName, mode, force))
+	return nil
+}
+
+func (f *fakeRegistry) DeleteMode(ctx context.Context, subjectName string) error {
+	f.writes = append(f.writes, fmt.Sprintf("delete mode %s", subjectName))
+	return nil
+}
+
+func schema(version int, id int, text string) types.Schema {
+	return types.Schema{Version: version, Id: id, SchemaType: types.SchemaTypeJSON, Schema: text}
+}
+
+func TestDiff(t *testing.T) {
+	source := func() *fakeRegistry {
+		return &fakeRegistry{
+			versions: map[string][]types.Schema{
+				"orders":   {schema(1, 1, `{"type": "object"}`), schema(2, 4, `{"type": "string"}`)},
+				"payments": {schema(1, 2, `{"type": "number"}`)},
+			},
+			configs: map[string]types.SubjectConfig{"orders": {CompatibilityLevel: "FULL"}},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		target      map[string][]types.Schema
+		configs     map[string]types.SubjectConfig
+		options     Options
+		expected    []SubjectPlan
+		expectError bool
+	}{
+		{
+			name:   "Empty target - Every version is pushed with its ID",
+			target: map[string][]types.Schema{},
+			options: Options{
+				Subjects: []string{"orders"},
+			},
+			expected: []SubjectPlan{
+				{Subject: "orders", Push: []VersionPush{{Version: 1, Id: 1, KeepId: true}, {Version: 2, Id: 4, KeepId: true}},
+					Config: &ConfigChange{To: types.ConfigPayload{Compatibility: "FULL"}}},
+			},
+		},
+		{
+			name: "Versions in sync - Only the new version is pushed",
+			target: map[string][]types.Schema{
+				"orders":   {schema(1, 1, `{"type": "object"}`)},
+				"payments": {schema(1, 2, `{"type": "number"}`)},
+			},
+			configs: map[string]types.SubjectConfig{"orders": {CompatibilityLevel: "FULL"}},
+			expected: []SubjectPlan{
+				{Subject: "orders", InSync: 1, Push: []VersionPush{{Version: 2, Id: 4, KeepId: true}}},
+				{Subject: "payments", InSync: 1},
+			},
+		},
+		{
+			name: "ID taken in the target - The version gets a new ID",
+			target: map[string][]types.Schema{
+				"orders": {schema(1, 1, `{"type": "object"}`)},
+				"other":  {schema(1, 4, `{"type": "boolean"}`)},
+			},
+			configs: map[string]types.SubjectConfig{"orders": {CompatibilityLevel: "FULL"}},
+			options: Options{Subjects: []string{"ord*"}},
+			expected: []SubjectPlan{
+				{Subject: "orders", InSync: 1, Push: []VersionPush{{Version: 2, Id: 4, KeepId: false}}},
+			},
+		},
+		{
+			name: "Version differs - The subject is a conflict",
+			target: map[string][]types.Schema{
+				"orders": {schema(1, 1, `{"type": "array"}`)},
+			},
+			options: Options{Subjects: []string{"orders"}},
+			expected: []SubjectPlan{
+				{Subject: "orders", Conflict: "version 1 differs in the target"},
+			},
+		},
+		{
+			name: "Target ahead of the source - The subject is a conflict",
+			target: map[string][]types.Schema{
+				"payments": {schema(1, 2, `{"type": "number"}`), schema(2, 3, `{"type": "integer"}`)},
+			},
+			options: Options{Subjects: []string{"payments"}},
+			expected: []SubjectPlan{
+				{Subject: "payments", Conflict: "the target has version 2, the source stops at version 1"},
+			},
+		},
+		{
+			name: "Incremental - Versions below the latest one of the target are not compared",
+			target: map[string][]types.Schema{
+				"orders": {schema(1, 1, `{"type": "array"}`), schema(2, 4, `{"type": "string"}`)},
+			},
+			configs: map[string]types.SubjectConfig{"orders": {CompatibilityLevel: "BACKWARD"}},
+			options: Options{Subjects: []string{"orders"}, Incremental: true},
+			expected: []SubjectPlan{
+				{Subject: "orders", InSync: 2, Config: &ConfigChange{
+					From: &types.ConfigPayload{Compatibility: "BACKWARD"},
+					To:   types.ConfigPayload{Compatibility: "FULL"},
+				}},
+			},
+		},
+		{
+			name:        "Invalid pattern - Error",
+			target:      map[string][]types.Schema{},
+			options:     Options{Subjects: []string{"orders["}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &fakeRegistry{versions: tt.target, configs: tt.configs}
+
+			plan, err := Diff(context.Background(), source(), target, tt.options)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Diff() expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Diff() unexpected error: %v", err)
+			}
+
+			for i := range plan.Subjects {
+				for j := range plan.Subjects[i].Push {
+					plan.Subjects[i].Push[j].Schema = types.Schema{}
+				}
+			}
+			if !reflect.DeepEqual(plan.Subjects, tt.expected) {
+				t.Errorf("Diff() = %+v, want %+v", plan.Subjects, tt.expected)
+			}
+			if len(target.writes) > 0 {
+				t.Errorf("Diff() wrote %q, want no writes", target.writes)
+			}
+		})
+	}
+}
+
+func TestDiffAgainstRegistry(t *testing.T) {
+	// The target never had the subject, the registry answers 40401 when listing it
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/subjects/orders/versions":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code": 40401, "message": "Subject 'orders' not found."}`)
+		case r.URL.Path == "/config/orders":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code": 40408, "message": "Subject 'orders' does not have subject-level compatibility configured"}`)
+		case strings.HasPrefix(r.URL.Path, "/schemas/ids/"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_code": 40403, "message": "Schema not found"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	target, err := confluentRegistryAPI.ReturnRegistryAPIForConfig(slog.Default(), confluentRegistryAPI.RegistryConfig{Name: "target", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("ReturnRegistryAPIForConfig() unexpected error: %v", err)
+	}
+	source := &fakeRegistry{versions: map[string][]types.Schema{"orders": {schema(1, 1, `{"type": "object"}`)}}}
+
+	plan, err := Diff(context.Background(), source, target, Options{})
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+
+	expected := []SubjectPlan{{Subject: "orders", Push: []VersionPush{{Version: 1, Id: 1, KeepId: true}}}}
+	for i := range plan.Subjects {
+		for j := range plan.Subjects[i].Push {
+			plan.Subjects[i].Push[j].Schema = types.Schema{}
+		}
+	}
+	if !reflect.DeepEqual(plan.Subjects, expected) {
+		t.Errorf("Diff() = %+v, want %+v", plan.Subjects, expected)
+	}
+}
+
+func TestApply(t *testing.T) {
+	source := &fakeRegistry{
+		versions: map[string][]types.Schema{
+			"orders":   {schema(1, 1, `{"type": "object"}`), schema(2, 4, `{"type": "string"}`), schema(3, 5, `{"type": "null"}`)},
+			"payments": {schema(1, 2, `{"type": "number"}`)},
+		},
+		configs: map[string]types.SubjectConfig{"payments": {CompatibilityLevel: "FULL"}},
+	}
+	target := &fakeRegistry{
+		versions: map[string][]types.Schema{
+			"orders": {schema(1, 1, `{"type": "object"}`)},
+			"other":  {schema(1, 5, `{"type": "boolean"}`)},
+		},
+		modes: map[string]string{"orders": types.ModeReadWrite},
+	}
+
+	plan, err := Diff(context.Background(), source, target, Options{Subjects: []string{"orders", "payments"}})
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+
+	report := Apply(context.Background(), target, plan)
+
+	// Versions go in ID order, ID 5 is taken so version 3 is registered
+	expectedWrites := []string{
+		"mode payments IMPORT force=true",
+		"import payments v1 id 2",
+		"mode orders IMPORT force=true",
+		"import orders v2 id 4",
+		"mode orders READWRITE force=false",
+		"register orders as v3 id 100",
+		"delete mode payments",
+		"config payments FULL",
+	}
+	if !reflect.DeepEqual(target.writes, expectedWrites) {
+		t.Errorf("writes = %q, want %q", target.writes, expectedWrites)
+	}
+
+	expectedReport := Report{Subjects: []SubjectReport{
+		{Subject: "orders", Pushed: []PushedVersion{
+			{SourceVersion: 2, SourceId: 4, Version: 2, Id: 4},
+			{SourceVersion: 3, SourceId: 5, Version: 3, Id: 100},
+		}},
+		{Subject: "payments", Pushed: []PushedVersion{{SourceVersion: 1, SourceId: 2, Version: 1, Id: 2}}, ConfigUpdated: true},
+	}}
+	if !reflect.DeepEqual(report, expectedReport) {
+		t.Errorf("Apply() = %+v, want %+v", report, expectedReport)
+	}
+
+	var summary strings.Builder
+	if err := report.WriteSummary(&summary); err != nil {
+		t.Fatalf("WriteSummary() unexpected error: %v", err)
+	}
+	if !strings.Contains(summary.String(), "version 3 (ID 5) as version 3 (ID 100)") {
+		t.Errorf("WriteSummary() = %q, want the new ID of version 3", summary.String())
+	}
+
+	// An incremental run afterwards finds nothing to push
+	target.configs = map[string]types.SubjectConfig{"payments": {CompatibilityLevel: "FULL"}}
+	plan, err = Diff(context.Background(), source, target, Options{Subjects: []string{"orders", "payments"}, Incremental: true})
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+
+	var diff strings.Builder
+	if err := plan.WriteDiff(&diff); err != nil {
+		t.Fatalf("WriteDiff() unexpected error: %v", err)
+	}
+	expectedDiff := "= orders: 3 versions in sync\n" +
+		"= payments: 1 version in sync\n" +
+		"2 subjects compared (incremental), 0 versions to push, 0 configs to update, 0 conflicts\n"
+	if diff.String() != expectedDiff {
+		t.Errorf("WriteDiff() = %q, want %q", diff.String(), expectedDiff)
+	}
+}
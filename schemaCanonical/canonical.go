@@ -0,0 +1,111 @@
+// Package schemaCanonical computes canonical forms of schemas and fingerprints of
+// those forms, so schemas differing only in formatting can be recognised as the same.
+//
+// JSON schemas are written with sorted keys and no whitespace, Avro schemas in the
+// Parsing Canonical Form of the Avro specification and Protobuf schemas as .proto
+// text with fully qualified type names and fields in number order. Every schema
+// gets a SHA-256 fingerprint, Avro schemas also get the Rabin (CRC-64-AVRO)
+// fingerprint used by Avro single-object encoding.
+package schemaCanonical
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"kafka-board/helpers"
+	"kafka-board/types"
+
+	"github.com/hamba/avro/v2"
+)
+
+// MinSearchLength is the shortest fingerprint prefix Matches accepts, shorter
+// prefixes match too many schemas to be useful
+const MinSearchLength = 8
+
+// Canonical is the canonical form of a schema with its fingerprints, as hex strings
+type Canonical struct {
+	SchemaType string `json:"schemaType"`
+	Form       string `json:"canonical"`
+	SHA256     string `json:"sha256"`
+	// Only set for Avro schemas
+	Rabin string `json:"rabin,omitempty"`
+}
+
+// Canonicalize returns the canonical form and fingerprints of a schema. References
+// are needed to parse Avro and Protobuf schemas using types of other subjects.
+func Canonicalize(schema types.Schema, references []types.ResolvedReference) (Canonical, error) {
+	canonical := Canonical{SchemaType: schema.GetSchemaType()}
+
+	switch canonical.SchemaType {
+	case types.SchemaTypeJSON:
+		form, err := canonicalJSON(schema.Schema)
+		if err != nil {
+			return Canonical{}, fmt.Errorf("error parsing JSON schema: %w", err)
+		}
+		canonical.Form = form
+	case types.SchemaTypeAvro:
+		parsed, err := helpers.ParseAvroSchema(schema.Schema, references)
+		if err != nil {
+			return Canonical{}, fmt.Errorf("error parsing Avro schema: %w", err)
+		}
+		rabin, err := parsed.FingerprintUsing(avro.CRC64Avro)
+		if err != nil {
+			return Canonical{}, fmt.Errorf("error computing Rabin fingerprint: %w", err)
+		}
+		// hamba/avro writes schemas in Parsing Canonical Form
+		canonical.Form = parsed.String()
+		canonical.Rabin = hex.EncodeToString(rabin)
+	case types.SchemaTypeProtobuf:
+		file, err := helpers.ParseProtobufSchema(schema.Schema, references)
+		if err != nil {
+			return Canonical{}, fmt.Errorf("error parsing Protobuf schema: %w", err)
+		}
+		canonical.Form = canonicalProtobuf(file)
+	default:
+		return Canonical{}, fmt.Errorf("unknown schema type %s", canonical.SchemaType)
+	}
+
+	sum := sha256.Sum256([]byte(canonical.Form))
+	canonical.SHA256 = hex.EncodeToString(sum[:])
+
+	return canonical, nil
+}
+
+// Matches reports whether fingerprint, or a prefix of at least MinSearchLength
+// characters, is one of the fingerprints of the schema. Case is ignored.
+func (c Canonical) Matches(fingerprint string) bool {
+	fingerprint = strings.ToLower(strings.TrimSpace(fingerprint))
+	if len(fingerprint) < MinSearchLength {
+		return false
+	}
+
+	return strings.HasPrefix(c.SHA256, fingerprint) || (c.Rabin != "" && strings.HasPrefix(c.Rabin, fingerprint))
+}
+
+// canonicalJSON writes a JSON document with sorted keys and without whitespace.
+// Numbers are kept as written.
+func canonicalJSON(schema string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(schema))
+	decoder.UseNumber()
+
+	var parsed any
+	if err := decoder.Decode(&parsed); err != nil {
+		return "", err
+	}
+	if decoder.More() {
+		return "", fmt.Errorf("unexpected content after the schema")
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(parsed); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
@@ -0,0 +1,133 @@
+package schemaCanonical
+
+import (
+	"testing"
+
+	"kafka-board/types"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name          string
+		schemaType    string
+		schema        string
+		equivalent    string
+		expectedForm  string
+		expectedRabin string
+	}{
+		{
+			name:         "JSON key order and whitespace",
+			schemaType:   types.SchemaTypeJSON,
+			schema:       `{"type": "object", "properties": {"total": {"type": "number", "minimum": 0.50}, "id": {"type": "string", "pattern": "<[a-z]+>"}}}`,
+			equivalent:   "{\n  \"properties\": {\"id\": {\"pattern\": \"<[a-z]+>\", \"type\": \"string\"},\n  \"total\": {\"minimum\": 0.50, \"type\": \"number\"}}, \"type\": \"object\"}",
+			expectedForm: `{"properties":{"id":{"pattern":"<[a-z]+>","type":"string"},"total":{"minimum":0.50,"type":"number"}},"type":"object"}`,
+		},
+		{
+			name:          "Avro Parsing Canonical Form",
+			schemaType:    types.SchemaTypeAvro,
+			schema:        `{"type": "record", "name": "Order", "namespace": "com.shop", "doc": "An order", "fields": [{"name": "id", "type": "string", "doc": "Order ID"}, {"name": "total", "type": {"type": "double"}, "default": 0}]}`,
+			equivalent:    `{"fields": [{"type": "string", "name": "id"}, {"name": "total", "type": "double"}], "name": "com.shop.Order", "type": "record"}`,
+			expectedForm:  `{"name":"com.shop.Order","type":"record","fields":[{"name":"id","type":"string"},{"name":"total","type":"double"}]}`,
+			expectedRabin: "47e42383aae8a8f1",
+		},
+		{
+			// Fingerprint from the test vectors of the Avro specification
+			name:          "Avro primitive Rabin fingerprint",
+			schemaType:    types.SchemaTypeAvro,
+			schema:        `"int"`,
+			equivalent:    `{"type": "int"}`,
+			expectedForm:  `"int"`,
+			expectedRabin: "7275d51a3f395c8f",
+		},
+		{
+			name:       "Protobuf field order, comments and type names",
+			schemaType: types.SchemaTypeProtobuf,
+			schema: `syntax = "proto3";
+package shop;
+import "google/protobuf/timestamp.proto";
+// An order
+message Order {
+  string id = 1;
+  repeated Line lines = 3;
+  google.protobuf.Timestamp created = 2 [deprecated = true];
+  message Line { int32 quantity = 1; }
+  reserved 8, 10 to max;
+}`,
+			equivalent: `syntax = "proto3";
+package shop;
+import "google/protobuf/timestamp.proto";
+message Order {
+  message Line {
+    int32 quantity = 1;
+  }
+  reserved 8, 10 to max;
+  google.protobuf.Timestamp created = 2 [deprecated=true];
+  string id = 1;
+  repeated .shop.Order.Line lines = 3;
+}`,
+			expectedForm: `syntax = "proto3";
+package shop;
+import "google/protobuf/timestamp.proto";
+message Order {
+  string id = 1;
+  .google.protobuf.Timestamp created = 2 [deprecated = true];
+  repeated .shop.Order.Line lines = 3;
+  message Line {
+    int32 quantity = 1;
+  }
+  reserved 8, 10 to max;
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canonical, err := Canonicalize(types.Schema{SchemaType: tt.schemaType, Schema: tt.schema}, nil)
+			if err != nil {
+				t.Fatalf("Canonicalize() unexpected error: %v", err)
+			}
+			if canonical.Form != tt.expectedForm {
+				t.Errorf("Form = %s, want %s", canonical.Form, tt.expectedForm)
+			}
+			if canonical.Rabin != tt.expectedRabin {
+				t.Errorf("Rabin = %s, want %s", canonical.Rabin, tt.expectedRabin)
+			}
+
+			equivalent, err := Canonicalize(types.Schema{SchemaType: tt.schemaType, Schema: tt.equivalent}, nil)
+			if err != nil {
+				t.Fatalf("Canonicalize() unexpected error: %v", err)
+			}
+			if equivalent != canonical {
+				t.Errorf("equivalent schema gives %+v, want %+v", equivalent, canonical)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	canonical := Canonical{
+		SHA256: "3f1c9a6b7e2d4c5a8b9e0f1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e",
+		Rabin:  "7275d51a3f395c8f",
+	}
+
+	tests := []struct {
+		name        string
+		fingerprint string
+		expected    bool
+	}{
+		{name: "full SHA-256", fingerprint: canonical.SHA256, expected: true},
+		{name: "SHA-256 prefix in upper case", fingerprint: "3F1C9A6B7E", expected: true},
+		{name: "Rabin fingerprint", fingerprint: "7275d51a3f395c8f", expected: true},
+		{name: "prefix too short", fingerprint: "3f1c9a", expected: false},
+		{name: "other fingerprint", fingerprint: "0000000000000000", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canonical.Matches(tt.fingerprint); got != tt.expected {
+				t.Errorf("Matches(%q) = %t, want %t", tt.fingerprint, got, tt.expected)
+			}
+		})
+	}
+}
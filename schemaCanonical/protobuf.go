@@ -0,0 +1,435 @@
+package schemaCanonical
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxFieldNumber is the largest field number, written "max" in ranges
+const maxFieldNumber = 536870911
+
+// canonicalProtobuf writes a compiled .proto file back as text without comments or
+// formatting choices. Imports and options are sorted, type names are fully qualified
+// and fields are listed by number, oneofs after the other fields. Messages, enums,
+// enum values and methods keep their declaration order, as it carries meaning: the
+// first message is the default message type and the first enum value the default value.
+func canonicalProtobuf(file protoreflect.FileDescriptor) string {
+	p := &protoPrinter{}
+
+	switch file.Syntax() {
+	case protoreflect.Proto2, protoreflect.Proto3:
+		p.line("syntax = %q;", file.Syntax().String())
+	case protoreflect.Editions:
+		edition := strings.TrimPrefix(protodesc.ToFileDescriptorProto(file).GetEdition().String(), "EDITION_")
+		p.line("edition = %q;", edition)
+	}
+	if file.Package() != "" {
+		p.line("package %s;", file.Package())
+	}
+
+	imports := make([]string, 0, file.Imports().Len())
+	for i := 0; i < file.Imports().Len(); i++ {
+		imported := file.Imports().Get(i)
+		modifier := ""
+		if imported.IsPublic {
+			modifier = "public "
+		} else if imported.IsWeak {
+			modifier = "weak "
+		}
+		imports = append(imports, fmt.Sprintf("import %s%q;", modifier, imported.Path()))
+	}
+	sort.Strings(imports)
+	for _, imported := range imports {
+		p.line("%s", imported)
+	}
+
+	p.options(file.Options())
+	for i := 0; i < file.Messages().Len(); i++ {
+		p.message(file.Messages().Get(i))
+	}
+	for i := 0; i < file.Enums().Len(); i++ {
+		p.enum(file.Enums().Get(i))
+	}
+	p.extensions(file.Extensions())
+	for i := 0; i < file.Services().Len(); i++ {
+		p.service(file.Services().Get(i))
+	}
+
+	return p.String()
+}
+
+// protoPrinter writes .proto text one line at a time, indented by block depth
+type protoPrinter struct {
+	strings.Builder
+	depth int
+}
+
+func (p *protoPrinter) line(format string, args ...any) {
+	p.WriteString(strings.Repeat("  ", p.depth))
+	fmt.Fprintf(p, format, args...)
+	p.WriteString("\n")
+}
+
+func (p *protoPrinter) open(format string, args ...any) {
+	p.line(format+" {", args...)
+	p.depth++
+}
+
+func (p *protoPrinter) close() {
+	p.depth--
+	p.line("}")
+}
+
+// options writes the options set on a descriptor as option statements
+func (p *protoPrinter) options(options protoreflect.ProtoMessage) {
+	for _, option := range setOptions(options) {
+		p.line("option %s;", option)
+	}
+}
+
+func (p *protoPrinter) message(message protoreflect.MessageDescriptor) {
+	p.open("message %s", message.Name())
+	p.options(message.Options())
+
+	fields := sortedFields(message.Fields())
+	for _, field := range fields {
+		if oneof := field.ContainingOneof(); oneof == nil || oneof.IsSynthetic() {
+			p.field(field)
+		}
+	}
+	for i := 0; i < message.Oneofs().Len(); i++ {
+		oneof := message.Oneofs().Get(i)
+		if oneof.IsSynthetic() {
+			continue
+		}
+		p.open("oneof %s", oneof.Name())
+		p.options(oneof.Options())
+		for _, field := range sortedFields(oneof.Fields()) {
+			p.field(field)
+		}
+		p.close()
+	}
+
+	for i := 0; i < message.Messages().Len(); i++ {
+		// Map entries are generated from map fields
+		if nested := message.Messages().Get(i); !nested.IsMapEntry() {
+			p.message(nested)
+		}
+	}
+	for i := 0; i < message.Enums().Len(); i++ {
+		p.enum(message.Enums().Get(i))
+	}
+	p.extensions(message.Extensions())
+
+	if ranges := fieldRanges(message.ExtensionRanges()); ranges != "" {
+		p.line("extensions %s;", ranges)
+	}
+	if ranges := fieldRanges(message.ReservedRanges()); ranges != "" {
+		p.line("reserved %s;", ranges)
+	}
+	if names := reservedNames(message.ReservedNames()); names != "" {
+		p.line("reserved %s;", names)
+	}
+
+	p.close()
+}
+
+// field writes a field with its label, type, pseudo-options and options
+func (p *protoPrinter) field(field protoreflect.FieldDescriptor) {
+	label := ""
+	switch {
+	case field.IsMap():
+	case field.Cardinality() == protoreflect.Repeated:
+		label = "repeated "
+	case field.ContainingOneof() != nil && !field.ContainingOneof().IsSynthetic():
+	case field.Cardinality() == protoreflect.Required:
+		label = "required "
+	case field.ParentFile().Syntax() == protoreflect.Proto2 || field.HasOptionalKeyword():
+		label = "optional "
+	}
+
+	var options []string
+	// The compiler fills in json_name, only names set in the schema differ from the default
+	if !field.IsExtension() && field.HasJSONName() && field.JSONName() != defaultJSONName(field.Name()) {
+		options = append(options, fmt.Sprintf("json_name = %q", field.JSONName()))
+	}
+	if field.HasDefault() {
+		options = append(options, "default = "+formatValue(field, field.Default()))
+	}
+	options = append(options, setOptions(field.Options())...)
+
+	suffix := ""
+	if len(options) > 0 {
+		suffix = " [" + strings.Join(options, ", ") + "]"
+	}
+
+	p.line("%s%s %s = %d%s;", label, fieldType(field), field.Name(), field.Number(), suffix)
+}
+
+func (p *protoPrinter) enum(enum protoreflect.EnumDescriptor) {
+	p.open("enum %s", enum.Name())
+	p.options(enum.Options())
+
+	for i := 0; i < enum.Values().Len(); i++ {
+		value := enum.Values().Get(i)
+		suffix := ""
+		if options := setOptions(value.Options()); len(options) > 0 {
+			suffix = " [" + strings.Join(options, ", ") + "]"
+		}
+		p.line("%s = %d%s;", value.Name(), value.Number(), suffix)
+	}
+
+	var ranges []string
+	for i := 0; i < enum.ReservedRanges().Len(); i++ {
+		r := enum.ReservedRanges().Get(i)
+		ranges = append(ranges, numberRange(int64(r[0]), int64(r[1]), math.MaxInt32))
+	}
+	if len(ranges) > 0 {
+		p.line("reserved %s;", strings.Join(ranges, ", "))
+	}
+	if names := reservedNames(enum.ReservedNames()); names != "" {
+		p.line("reserved %s;", names)
+	}
+
+	p.close()
+}
+
+// extensions writes extension fields grouped by the message they extend
+func (p *protoPrinter) extensions(extensions protoreflect.ExtensionDescriptors) {
+	var extendees []protoreflect.FullName
+	byExtendee := make(map[protoreflect.FullName][]protoreflect.FieldDescriptor)
+	for i := 0; i < extensions.Len(); i++ {
+		extension := extensions.Get(i)
+		extendee := extension.ContainingMessage().FullName()
+		if _, ok := byExtendee[extendee]; !ok {
+			extendees = append(extendees, extendee)
+		}
+		byExtendee[extendee] = append(byExtendee[extendee], extension)
+	}
+	sort.Slice(extendees, func(i, j int) bool { return extendees[i] < extendees[j] })
+
+	for _, extendee := range extendees {
+		fields := byExtendee[extendee]
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Number() < fields[j].Number() })
+
+		p.open("extend .%s", extendee)
+		for _, field := range fields {
+			p.field(field)
+		}
+		p.close()
+	}
+}
+
+func (p *protoPrinter) service(service protoreflect.ServiceDescriptor) {
+	p.open("service %s", service.Name())
+	p.options(service.Options())
+
+	for i := 0; i < service.Methods().Len(); i++ {
+		method := service.Methods().Get(i)
+		input, output := "."+string(method.Input().FullName()), "."+string(method.Output().FullName())
+		if method.IsStreamingClient() {
+			input = "stream " + input
+		}
+		if method.IsStreamingServer() {
+			output = "stream " + output
+		}
+
+		options := setOptions(method.Options())
+		if len(options) == 0 {
+			p.line("rpc %s (%s) returns (%s);", method.Name(), input, output)
+			continue
+		}
+		p.open("rpc %s (%s) returns (%s)", method.Name(), input, output)
+		for _, option := range options {
+			p.line("option %s;", option)
+		}
+		p.close()
+	}
+
+	p.close()
+}
+
+// fieldType returns the type of a field, with message and enum types fully qualified
+func fieldType(field protoreflect.FieldDescriptor) string {
+	if field.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", fieldType(field.MapKey()), fieldType(field.MapValue()))
+	}
+
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "." + string(field.Message().FullName())
+	case protoreflect.EnumKind:
+		return "." + string(field.Enum().FullName())
+	}
+	return field.Kind().String()
+}
+
+// setOptions returns the options set on a descriptor as "name = value", sorted by
+// field number. Unset options, left at their default, are not listed.
+func setOptions(options protoreflect.ProtoMessage) []string {
+	if options == nil {
+		return nil
+	}
+
+	message := options.ProtoReflect()
+	if !message.IsValid() {
+		return nil
+	}
+
+	var fields []protoreflect.FieldDescriptor
+	message.Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, field)
+		return true
+	})
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Number() < fields[j].Number() })
+
+	var set []string
+	for _, field := range fields {
+		value := message.Get(field)
+		if field.IsList() {
+			// Repeated options are written once per value
+			for i := 0; i < value.List().Len(); i++ {
+				set = append(set, optionName(field)+" = "+formatValue(field, value.List().Get(i)))
+			}
+			continue
+		}
+		set = append(set, optionName(field)+" = "+formatValue(field, value))
+	}
+	return set
+}
+
+// optionName returns the name of an option, in parentheses for custom options
+func optionName(field protoreflect.FieldDescriptor) string {
+	if field.IsExtension() {
+		return "(" + string(field.FullName()) + ")"
+	}
+	return string(field.Name())
+}
+
+// formatValue writes a single value of a field as a .proto literal. Messages are
+// written as aggregate values, with their fields sorted by number.
+func formatValue(field protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch field.Kind() {
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+		return strconv.Itoa(int(value.Enum()))
+	case protoreflect.StringKind:
+		return strconv.Quote(value.String())
+	case protoreflect.BytesKind:
+		return strconv.Quote(string(value.Bytes()))
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		number := value.Float()
+		switch {
+		case math.IsInf(number, 1):
+			return "inf"
+		case math.IsInf(number, -1):
+			return "-inf"
+		case math.IsNaN(number):
+			return "nan"
+		}
+		return strconv.FormatFloat(number, 'g', -1, 64)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return formatMessage(value.Message())
+	}
+	return value.String()
+}
+
+// formatMessage writes a message as an aggregate value, e.g. { min: 1 max: 5 }
+func formatMessage(message protoreflect.Message) string {
+	var fields []protoreflect.FieldDescriptor
+	message.Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, field)
+		return true
+	})
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Number() < fields[j].Number() })
+
+	parts := []string{"{"}
+	for _, field := range fields {
+		name := string(field.Name())
+		if field.IsExtension() {
+			name = "[" + string(field.FullName()) + "]"
+		}
+
+		value := message.Get(field)
+		if field.IsList() {
+			values := make([]string, 0, value.List().Len())
+			for i := 0; i < value.List().Len(); i++ {
+				values = append(values, formatValue(field, value.List().Get(i)))
+			}
+			parts = append(parts, name+": ["+strings.Join(values, ", ")+"]")
+			continue
+		}
+		parts = append(parts, name+": "+formatValue(field, value))
+	}
+	parts = append(parts, "}")
+
+	return strings.Join(parts, " ")
+}
+
+// defaultJSONName returns the JSON name protoc derives from a field name: underscores
+// are dropped and the letter after each one is upper-cased
+func defaultJSONName(name protoreflect.Name) string {
+	var b strings.Builder
+	upper := false
+	for _, c := range name {
+		switch {
+		case c == '_':
+			upper = true
+		case upper && 'a' <= c && c <= 'z':
+			b.WriteRune(c - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteRune(c)
+			upper = false
+		}
+	}
+	return b.String()
+}
+
+// sortedFields returns fields sorted by number
+func sortedFields(fields protoreflect.FieldDescriptors) []protoreflect.FieldDescriptor {
+	sorted := make([]protoreflect.FieldDescriptor, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		sorted = append(sorted, fields.Get(i))
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Number() < sorted[j].Number() })
+	return sorted
+}
+
+// fieldRanges writes field number ranges, whose end is exclusive, e.g. "5, 8 to max"
+func fieldRanges(ranges protoreflect.FieldRanges) string {
+	parts := make([]string, 0, ranges.Len())
+	for i := 0; i < ranges.Len(); i++ {
+		r := ranges.Get(i)
+		parts = append(parts, numberRange(int64(r[0]), int64(r[1])-1, maxFieldNumber))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// numberRange writes an inclusive range of numbers
+func numberRange(start int64, end int64, max int64) string {
+	switch {
+	case start == end:
+		return strconv.FormatInt(start, 10)
+	case end == max:
+		return fmt.Sprintf("%d to max", start)
+	}
+	return fmt.Sprintf("%d to %d", start, end)
+}
+
+// reservedNames writes reserved names as quoted strings, e.g. `"foo", "bar"`
+func reservedNames(names protoreflect.Names) string {
+	quoted := make([]string, 0, names.Len())
+	for i := 0; i < names.Len(); i++ {
+		quoted = append(quoted, strconv.Quote(string(names.Get(i))))
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,62 @@
+package schemaCompatibility
+
+import (
+	"strings"
+
+	"github.com/hamba/avro/v2"
+)
+
+// avroDifferences maps the errors of the hamba/avro compatibility check to the
+// Confluent difference types of Avro schemas
+var avroDifferences = []struct {
+	match          string
+	differenceType string
+	rule           string
+}{
+	{"reader union lacking writer schema", "MISSING_UNION_BRANCH", "The reader union must hold every type the writer may write"},
+	{"has no default", "READER_FIELD_MISSING_DEFAULT_VALUE", "A field added to the reader needs a default value for data written without it"},
+	{"is missing symbol", "MISSING_ENUM_SYMBOLS", "The reader enum must hold every symbol the writer may write, unless it has a default"},
+	{"names do not match", "NAME_MISMATCH", "Named types must keep their name, or list the old one as an alias"},
+	{"fixed sizes do not match", "FIXED_SIZE_MISMATCH", "Fixed types must keep their size"},
+	{"not compatible with writer schema", "TYPE_MISMATCH", "The reader type must be the writer type or a promotion of it, e.g. int to long"},
+}
+
+// CheckAvroSchema checks a proposed Avro schema against the earlier versions of a
+// subject, oldest first. Non-transitive levels only check against the latest one.
+// Schemas referencing other subjects cannot be parsed on their own and are
+// reported as invalid.
+func CheckAvroSchema(level string, proposed string, previous []string) (Result, error) {
+	return check(level, proposed, previous, parseAvroSchema, compareAvroSchemas)
+}
+
+// parseAvroSchema parses each schema with its own cache, so named types of
+// different versions do not clash
+func parseAvroSchema(schema string) (avro.Schema, error) {
+	return avro.ParseWithCache(schema, "", &avro.SchemaCache{})
+}
+
+// compareAvroSchemas reports the first incompatibility found when data written with
+// original is read with update. The Avro check stops at the first one and does not
+// give its location.
+func compareAvroSchemas(original, update avro.Schema) []Difference {
+	err := avro.NewSchemaCompatibility().Compatible(update, original)
+	if err == nil {
+		return []Difference{}
+	}
+
+	difference := Difference{
+		Type:        "INCOMPATIBLE",
+		Path:        "/",
+		Description: err.Error(),
+		Rule:        "The reader schema must be able to read data written with the writer schema",
+	}
+	for _, known := range avroDifferences {
+		if strings.Contains(err.Error(), known.match) {
+			difference.Type = known.differenceType
+			difference.Rule = known.rule
+			break
+		}
+	}
+
+	return []Difference{difference}
+}
@@ -0,0 +1,205 @@
+// Package schemaCompatibility checks schema compatibility locally, without a registry.
+//
+// It follows the rules of the Confluent Schema Registry: a schema change is described
+// as a list of differences, each of a Confluent difference type such as TYPE_NARROWED,
+// and the change is compatible when every difference is. Each difference carries the
+// rule that was applied, so results can be explained.
+package schemaCompatibility
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"kafka-board/types"
+)
+
+// Compatibility levels, as configured on the registry
+const (
+	LevelNone               = "NONE"
+	LevelBackward           = "BACKWARD"
+	LevelBackwardTransitive = "BACKWARD_TRANSITIVE"
+	LevelForward            = "FORWARD"
+	LevelForwardTransitive  = "FORWARD_TRANSITIVE"
+	LevelFull               = "FULL"
+	LevelFullTransitive     = "FULL_TRANSITIVE"
+)
+
+// Directions of a check. A BACKWARD check makes sure the proposed schema reads data
+// written with an earlier one, a FORWARD check that earlier schemas read data
+// written with the proposed one.
+const (
+	DirectionBackward = "BACKWARD"
+	DirectionForward  = "FORWARD"
+)
+
+// Difference is one change between two schemas
+type Difference struct {
+	// Confluent difference type, e.g. TYPE_NARROWED
+	Type string `json:"type"`
+	// JSON pointer to the changed schema, e.g. #/properties/age
+	Path       string `json:"path"`
+	Compatible bool   `json:"compatible"`
+	// What changed
+	Description string `json:"description"`
+	// Why a change of this type is compatible or not
+	Rule string `json:"rule"`
+}
+
+// Check is the comparison of the proposed schema with one earlier version
+type Check struct {
+	// Position of the earlier schema in the list checked against, 1 being the oldest
+	Version     int          `json:"version"`
+	Direction   string       `json:"direction"`
+	Compatible  bool         `json:"compatible"`
+	Differences []Difference `json:"differences"`
+}
+
+// Result is the outcome of checking a proposed schema at a compatibility level
+type Result struct {
+	Level      string  `json:"level"`
+	Compatible bool    `json:"compatible"`
+	Checks     []Check `json:"checks"`
+}
+
+// Incompatibilities lists the incompatible differences of every check, in the
+// format returned by the registry in verbose mode
+func (r Result) Incompatibilities() []types.Incompatibility {
+	incompatibilities := []types.Incompatibility{}
+	for _, check := range r.Checks {
+		for _, difference := range check.Differences {
+			if difference.Compatible {
+				continue
+			}
+			incompatibilities = append(incompatibilities, types.Incompatibility{
+				Type:        difference.Type,
+				Path:        difference.Path,
+				Description: fmt.Sprintf("%s (%s check against version %d)", difference.Description, strings.ToLower(check.Direction), check.Version),
+			})
+		}
+	}
+	return incompatibilities
+}
+
+// Levels lists the compatibility levels the registry accepts
+var Levels = []string{
+	LevelNone,
+	LevelBackward,
+	LevelBackwardTransitive,
+	LevelForward,
+	LevelForwardTransitive,
+	LevelFull,
+	LevelFullTransitive,
+}
+
+// ErrUnsupportedSchemaType is returned by CheckSchema for schema types it cannot check
+var ErrUnsupportedSchemaType = errors.New("schema type not supported")
+
+// CheckSchema checks a proposed schema of the given type against the earlier versions of
+// a subject, oldest first. JSON schemas and Avro schemas without references are
+// supported.
+func CheckSchema(schemaType string, level string, proposed string, previous []string) (Result, error) {
+	switch schemaType {
+	case types.SchemaTypeJSON:
+		return CheckJSONSchema(level, proposed, previous)
+	case types.SchemaTypeAvro, "":
+		return CheckAvroSchema(level, proposed, previous)
+	default:
+		return Result{}, fmt.Errorf("%w: %s", ErrUnsupportedSchemaType, schemaType)
+	}
+}
+
+// CheckJSONSchema checks a proposed JSON schema against the earlier versions of a
+// subject, oldest first. Non-transitive levels only check against the latest one.
+func CheckJSONSchema(level string, proposed string, previous []string) (Result, error) {
+	return check(level, proposed, previous, parseJSONSchema, func(original, update any) []Difference {
+		return newJSONSchemaDiff(original, update).compare("#", original, update)
+	})
+}
+
+// parseLevel returns the directions checked at a compatibility level and whether
+// every earlier version is checked
+func parseLevel(level string) (directions []string, transitive bool, err error) {
+	transitive = strings.HasSuffix(level, "_TRANSITIVE")
+	switch strings.TrimSuffix(level, "_TRANSITIVE") {
+	case LevelNone:
+		if transitive {
+			return nil, false, fmt.Errorf("unknown compatibility level %s", level)
+		}
+	case LevelBackward:
+		directions = []string{DirectionBackward}
+	case LevelForward:
+		directions = []string{DirectionForward}
+	case LevelFull:
+		directions = []string{DirectionBackward, DirectionForward}
+	default:
+		return nil, false, fmt.Errorf("unknown compatibility level %s", level)
+	}
+
+	return directions, transitive, nil
+}
+
+// check runs the checks of a compatibility level with the parser and comparison of
+// a schema type. compare lists the differences when data written with original is
+// read with update.
+func check[T any](level string, proposed string, previous []string, parse func(string) (T, error), compare func(original, update T) []Difference) (Result, error) {
+	level = strings.ToUpper(strings.TrimSpace(level))
+
+	directions, transitive, err := parseLevel(level)
+	if err != nil {
+		return Result{}, err
+	}
+
+	proposedSchema, err := parse(proposed)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid proposed schema: %w", err)
+	}
+
+	first := 0
+	if !transitive && len(previous) > 0 {
+		first = len(previous) - 1
+	}
+
+	result := Result{Level: level, Compatible: true, Checks: []Check{}}
+	for i := first; i < len(previous) && len(directions) > 0; i++ {
+		previousSchema, err := parse(previous[i])
+		if err != nil {
+			return Result{}, fmt.Errorf("invalid schema of version %d: %w", i+1, err)
+		}
+
+		for _, direction := range directions {
+			// The reader of the data is the update, the writer the original
+			original, update := previousSchema, proposedSchema
+			if direction == DirectionForward {
+				original, update = proposedSchema, previousSchema
+			}
+
+			check := Check{Version: i + 1, Direction: direction, Compatible: true}
+			check.Differences = compare(original, update)
+			for _, difference := range check.Differences {
+				check.Compatible = check.Compatible && difference.Compatible
+			}
+
+			result.Compatible = result.Compatible && check.Compatible
+			result.Checks = append(result.Checks, check)
+		}
+	}
+
+	return result, nil
+}
+
+// CompareJSONSchemas lists the differences between two JSON schemas, where data
+// written with original is read with update
+func CompareJSONSchemas(original string, update string) ([]Difference, error) {
+	originalSchema, err := parseJSONSchema(original)
+	if err != nil {
+		return nil, fmt.Errorf("invalid original schema: %w", err)
+	}
+
+	updateSchema, err := parseJSONSchema(update)
+	if err != nil {
+		return nil, fmt.Errorf("invalid updated schema: %w", err)
+	}
+
+	return newJSONSchemaDiff(originalSchema, updateSchema).compare("#", originalSchema, updateSchema), nil
+}
@@ -0,0 +1,299 @@
+package schemaCompatibility
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCompareJSONSchemas(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		update   string
+		expected []string
+	}{
+		{
+			name:     "identical schemas",
+			original: `{"type":"object","properties":{"id":{"type":"integer"}}}`,
+			update:   `{"type":"object","properties":{"id":{"type":"integer"}}}`,
+		},
+		{
+			name:     "integer widened to number",
+			original: `{"type":"object","properties":{"id":{"type":"integer"}}}`,
+			update:   `{"type":"object","properties":{"id":{"type":"number"}}}`,
+			expected: []string{"TYPE_EXTENDED #/properties/id/type"},
+		},
+		{
+			name:     "number narrowed to integer",
+			original: `{"type":"number"}`,
+			update:   `{"type":"integer"}`,
+			expected: []string{"TYPE_NARROWED #/type"},
+		},
+		{
+			name:     "string changed to boolean",
+			original: `{"type":"string","maxLength":5}`,
+			update:   `{"type":"boolean"}`,
+			expected: []string{"TYPE_CHANGED #/type"},
+		},
+		{
+			name:     "required property added to a closed content model",
+			original: `{"type":"object","properties":{"id":{"type":"integer"}},"additionalProperties":false}`,
+			update:   `{"type":"object","properties":{"id":{"type":"integer"},"email":{"type":"string"}},"required":["email"],"additionalProperties":false}`,
+			expected: []string{
+				"REQUIRED_PROPERTY_ADDED_TO_UNOPEN_CONTENT_MODEL #/properties/email",
+				"REQUIRED_ATTRIBUTE_ADDED #/required/email",
+			},
+		},
+		{
+			name:     "optional property added to an open content model",
+			original: `{"type":"object","properties":{"id":{"type":"integer"}}}`,
+			update:   `{"type":"object","properties":{"id":{"type":"integer"},"email":{"type":"string"}}}`,
+			expected: []string{"PROPERTY_ADDED_TO_OPEN_CONTENT_MODEL #/properties/email"},
+		},
+		{
+			name:     "property removed from a closed content model",
+			original: `{"type":"object","properties":{"id":{"type":"integer"},"age":{"type":"integer"}},"additionalProperties":false}`,
+			update:   `{"type":"object","properties":{"id":{"type":"integer"}},"additionalProperties":false}`,
+			expected: []string{"PROPERTY_REMOVED_FROM_CLOSED_CONTENT_MODEL #/properties/age"},
+		},
+		{
+			name:     "property added covered by patternProperties",
+			original: `{"type":"object","patternProperties":{"^x-":{"type":"string"}},"additionalProperties":false}`,
+			update:   `{"type":"object","properties":{"x-trace":{"type":"string","maxLength":10}},"patternProperties":{"^x-":{"type":"string"}},"additionalProperties":false}`,
+			expected: []string{"PROPERTY_ADDED_NOT_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL #/properties/x-trace"},
+		},
+		{
+			name:     "enum extended and narrowed",
+			original: `{"type":"object","properties":{"status":{"enum":["new","paid"]},"currency":{"enum":["EUR","USD"]}}}`,
+			update:   `{"type":"object","properties":{"status":{"enum":["new","paid","shipped"]},"currency":{"enum":["EUR"]}}}`,
+			expected: []string{
+				"ENUM_ARRAY_NARROWED #/properties/currency/enum",
+				"ENUM_ARRAY_EXTENDED #/properties/status/enum",
+			},
+		},
+		{
+			name:     "bounds relaxed and tightened",
+			original: `{"type":"string","maxLength":10,"minLength":2}`,
+			update:   `{"type":"string","maxLength":20,"minLength":3}`,
+			expected: []string{"MAX_LENGTH_INCREASED #/maxLength", "MIN_LENGTH_INCREASED #/minLength"},
+		},
+		{
+			name:     "local references are resolved",
+			original: `{"type":"object","properties":{"address":{"$ref":"#/definitions/address"}},"definitions":{"address":{"type":"object","properties":{"zip":{"type":"string"}}}}}`,
+			update:   `{"type":"object","properties":{"address":{"$ref":"#/$defs/address"}},"$defs":{"address":{"type":"object","properties":{"zip":{"type":"integer"}}}}}`,
+			expected: []string{"TYPE_CHANGED #/$defs/address/properties/zip/type"},
+		},
+		{
+			name:     "differences in a definition are located in it",
+			original: `{"type":"object","properties":{"billing":{"$ref":"#/definitions/address"},"id":{"type":"integer"}},"definitions":{"address":{"type":"object","properties":{"zip":{"type":"integer"}}}}}`,
+			update:   `{"type":"object","properties":{"billing":{"$ref":"#/definitions/address"},"id":{"type":"number"}},"definitions":{"address":{"type":"object","properties":{"zip":{"type":"number"}}}}}`,
+			expected: []string{
+				"TYPE_EXTENDED #/definitions/address/properties/zip/type",
+				"TYPE_EXTENDED #/properties/id/type",
+			},
+		},
+		{
+			name:     "recursive schemas terminate",
+			original: `{"$ref":"#/definitions/node","definitions":{"node":{"type":"object","properties":{"next":{"$ref":"#/definitions/node"}}}}}`,
+			update:   `{"$ref":"#/definitions/node","definitions":{"node":{"type":"object","properties":{"next":{"$ref":"#/definitions/node"}}}}}`,
+		},
+		{
+			name:     "schema became a union including the original",
+			original: `{"type":"string"}`,
+			update:   `{"oneOf":[{"type":"string"},{"type":"null"}]}`,
+			expected: []string{"SUM_TYPE_EXTENDED #"},
+		},
+		{
+			name:     "union lost an alternative",
+			original: `{"anyOf":[{"type":"string"},{"type":"integer"}]}`,
+			update:   `{"anyOf":[{"type":"string"},{"type":"boolean"}]}`,
+			expected: []string{"COMBINED_TYPE_SUBSCHEMAS_CHANGED #"},
+		},
+		{
+			name:     "tuple item added to a closed content model",
+			original: `{"type":"array","items":[{"type":"string"}],"additionalItems":false}`,
+			update:   `{"type":"array","items":[{"type":"string"},{"type":"integer"}],"additionalItems":false}`,
+			expected: []string{"ITEM_ADDED_TO_CLOSED_CONTENT_MODEL #/items/1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			differences, err := CompareJSONSchemas(tt.original, tt.update)
+			if err != nil {
+				t.Fatalf("CompareJSONSchemas() unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, difference := range differences {
+				got = append(got, difference.Type+" "+difference.Path)
+				if difference.Rule == "" {
+					t.Errorf("difference %s has no rule", difference.Type)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("CompareJSONSchemas() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckJSONSchema(t *testing.T) {
+	v1 := `{"type":"object","properties":{"id":{"type":"integer"}},"additionalProperties":false}`
+	v2 := `{"type":"object","properties":{"id":{"type":"integer"},"email":{"type":"string"}},"additionalProperties":false}`
+	openV2 := `{"type":"object","properties":{"id":{"type":"integer"},"email":{"type":"string"}}}`
+
+	tests := []struct {
+		name               string
+		level              string
+		proposed           string
+		previous           []string
+		expectedCompatible bool
+		expectedChecks     int
+	}{
+		{
+			name:               "optional property added to a closed content model is backward compatible",
+			level:              LevelBackward,
+			proposed:           v2,
+			previous:           []string{v1},
+			expectedCompatible: true,
+			expectedChecks:     1,
+		},
+		{
+			name:               "optional property added to a closed content model is not forward compatible",
+			level:              LevelForward,
+			proposed:           v2,
+			previous:           []string{v1},
+			expectedCompatible: false,
+			expectedChecks:     1,
+		},
+		{
+			name:               "property added to an open content model is not backward compatible",
+			level:              LevelBackward,
+			proposed:           openV2,
+			previous:           []string{`{"type":"object","properties":{"id":{"type":"integer"}}}`},
+			expectedCompatible: false,
+			expectedChecks:     1,
+		},
+		{
+			name:               "full checks both directions",
+			level:              LevelFull,
+			proposed:           v2,
+			previous:           []string{v1},
+			expectedCompatible: false,
+			expectedChecks:     2,
+		},
+		{
+			name:               "non transitive levels only check the latest version",
+			level:              LevelBackward,
+			proposed:           `{"type":"object","properties":{"id":{"type":"string"}}}`,
+			previous:           []string{v1, `{"type":"object","properties":{"id":{"type":"string"}}}`},
+			expectedCompatible: true,
+			expectedChecks:     1,
+		},
+		{
+			name:               "transitive levels check every version",
+			level:              LevelBackwardTransitive,
+			proposed:           `{"type":"object","properties":{"id":{"type":"string"}}}`,
+			previous:           []string{v1, `{"type":"object","properties":{"id":{"type":"string"}}}`},
+			expectedCompatible: false,
+			expectedChecks:     2,
+		},
+		{
+			name:               "none checks nothing",
+			level:              LevelNone,
+			proposed:           `{"type":"string"}`,
+			previous:           []string{v1},
+			expectedCompatible: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CheckJSONSchema(tt.level, tt.proposed, tt.previous)
+			if err != nil {
+				t.Fatalf("CheckJSONSchema() unexpected error: %v", err)
+			}
+			if result.Compatible != tt.expectedCompatible {
+				t.Errorf("Compatible = %t, want %t (%+v)", result.Compatible, tt.expectedCompatible, result.Checks)
+			}
+			if len(result.Checks) != tt.expectedChecks {
+				t.Errorf("len(Checks) = %d, want %d", len(result.Checks), tt.expectedChecks)
+			}
+			if !result.Compatible && len(result.Incompatibilities()) == 0 {
+				t.Errorf("Incompatibilities() is empty for an incompatible result")
+			}
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, err := CheckJSONSchema("SIDEWAYS", v1, nil); err == nil {
+			t.Errorf("CheckJSONSchema() with an unknown level returned no error")
+		}
+		if _, err := CheckJSONSchema(LevelBackward, `{"type":`, []string{v1}); err == nil {
+			t.Errorf("CheckJSONSchema() with an invalid schema returned no error")
+		}
+	})
+}
+
+func TestCheckAvroSchema(t *testing.T) {
+	v1 := `{"type":"record","name":"Order","fields":[{"name":"id","type":"int"}]}`
+
+	tests := []struct {
+		name               string
+		level              string
+		proposed           string
+		expectedCompatible bool
+		expectedDifference string
+	}{
+		{
+			name:               "field added with a default is backward compatible",
+			level:              LevelBackward,
+			proposed:           `{"type":"record","name":"Order","fields":[{"name":"id","type":"int"},{"name":"note","type":"string","default":""}]}`,
+			expectedCompatible: true,
+		},
+		{
+			name:               "field added without a default is not backward compatible",
+			level:              LevelBackward,
+			proposed:           `{"type":"record","name":"Order","fields":[{"name":"id","type":"int"},{"name":"note","type":"string"}]}`,
+			expectedDifference: "READER_FIELD_MISSING_DEFAULT_VALUE",
+		},
+		{
+			name:               "int promoted to long is backward compatible",
+			level:              LevelBackward,
+			proposed:           `{"type":"record","name":"Order","fields":[{"name":"id","type":"long"}]}`,
+			expectedCompatible: true,
+		},
+		{
+			name:               "int promoted to long is not forward compatible",
+			level:              LevelForward,
+			proposed:           `{"type":"record","name":"Order","fields":[{"name":"id","type":"long"}]}`,
+			expectedDifference: "TYPE_MISMATCH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CheckSchema("", tt.level, tt.proposed, []string{v1})
+			if err != nil {
+				t.Fatalf("CheckSchema() unexpected error: %v", err)
+			}
+			if result.Compatible != tt.expectedCompatible {
+				t.Errorf("Compatible = %t, want %t (%+v)", result.Compatible, tt.expectedCompatible, result.Checks)
+			}
+			if tt.expectedDifference != "" {
+				incompatibilities := result.Incompatibilities()
+				if len(incompatibilities) != 1 || incompatibilities[0].Type != tt.expectedDifference {
+					t.Errorf("Incompatibilities() = %+v, want one of type %s", incompatibilities, tt.expectedDifference)
+				}
+			}
+		})
+	}
+
+	t.Run("protobuf is not supported", func(t *testing.T) {
+		if _, err := CheckSchema("PROTOBUF", LevelBackward, `syntax = "proto3";`, nil); !errors.Is(err, ErrUnsupportedSchemaType) {
+			t.Errorf("CheckSchema() error = %v, want ErrUnsupportedSchemaType", err)
+		}
+	})
+}
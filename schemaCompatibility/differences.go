@@ -0,0 +1,196 @@
+package schemaCompatibility
+
+import "strings"
+
+// compatibleChanges are the difference types that keep data written with the original
+// schema readable with the updated one, as defined by the Confluent Schema Registry
+var compatibleChanges = map[string]bool{
+	"ID_CHANGED":          true,
+	"DESCRIPTION_CHANGED": true,
+	"TITLE_CHANGED":       true,
+	"DEFAULT_CHANGED":     true,
+	"SCHEMA_REMOVED":      true,
+	"TYPE_EXTENDED":       true,
+
+	"MAX_LENGTH_INCREASED":        true,
+	"MAX_LENGTH_REMOVED":          true,
+	"MIN_LENGTH_DECREASED":        true,
+	"MIN_LENGTH_REMOVED":          true,
+	"PATTERN_REMOVED":             true,
+	"MAXIMUM_INCREASED":           true,
+	"MAXIMUM_REMOVED":             true,
+	"MINIMUM_DECREASED":           true,
+	"MINIMUM_REMOVED":             true,
+	"EXCLUSIVE_MAXIMUM_INCREASED": true,
+	"EXCLUSIVE_MAXIMUM_REMOVED":   true,
+	"EXCLUSIVE_MINIMUM_DECREASED": true,
+	"EXCLUSIVE_MINIMUM_REMOVED":   true,
+	"MULTIPLE_OF_REDUCED":         true,
+	"MULTIPLE_OF_REMOVED":         true,
+
+	"REQUIRED_ATTRIBUTE_WITH_DEFAULT_ADDED": true,
+	"REQUIRED_ATTRIBUTE_REMOVED":            true,
+	"DEPENDENCY_ARRAY_NARROWED":             true,
+	"DEPENDENCY_ARRAY_REMOVED":              true,
+	"DEPENDENCY_SCHEMA_REMOVED":             true,
+	"MAX_PROPERTIES_INCREASED":              true,
+	"MAX_PROPERTIES_REMOVED":                true,
+	"MIN_PROPERTIES_DECREASED":              true,
+	"MIN_PROPERTIES_REMOVED":                true,
+	"ADDITIONAL_PROPERTIES_ADDED":           true,
+	"ADDITIONAL_PROPERTIES_EXTENDED":        true,
+
+	"PROPERTY_WITH_EMPTY_SCHEMA_ADDED_TO_OPEN_CONTENT_MODEL":       true,
+	"REQUIRED_PROPERTY_WITH_DEFAULT_ADDED_TO_UNOPEN_CONTENT_MODEL": true,
+	"OPTIONAL_PROPERTY_ADDED_TO_UNOPEN_CONTENT_MODEL":              true,
+	"PROPERTY_WITH_FALSE_REMOVED_FROM_CLOSED_CONTENT_MODEL":        true,
+	"PROPERTY_REMOVED_FROM_OPEN_CONTENT_MODEL":                     true,
+	"PROPERTY_ADDED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL":    true,
+	"PROPERTY_REMOVED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL":  true,
+
+	"MAX_ITEMS_INCREASED":                                     true,
+	"MAX_ITEMS_REMOVED":                                       true,
+	"MIN_ITEMS_DECREASED":                                     true,
+	"MIN_ITEMS_REMOVED":                                       true,
+	"UNIQUE_ITEMS_REMOVED":                                    true,
+	"ADDITIONAL_ITEMS_ADDED":                                  true,
+	"ADDITIONAL_ITEMS_EXTENDED":                               true,
+	"ITEM_WITH_EMPTY_SCHEMA_ADDED_TO_OPEN_CONTENT_MODEL":      true,
+	"ITEM_ADDED_TO_CLOSED_CONTENT_MODEL":                      true,
+	"ITEM_WITH_FALSE_REMOVED_FROM_CLOSED_CONTENT_MODEL":       true,
+	"ITEM_REMOVED_FROM_OPEN_CONTENT_MODEL":                    true,
+	"ITEM_ADDED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL":   true,
+	"ITEM_REMOVED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL": true,
+
+	"ENUM_ARRAY_EXTENDED":    true,
+	"COMBINED_TYPE_EXTENDED": true,
+	"PRODUCT_TYPE_NARROWED":  true,
+	"SUM_TYPE_EXTENDED":      true,
+	"NOT_TYPE_NARROWED":      true,
+}
+
+// rules explains why each difference type is compatible or not. Bounds such as
+// MAX_LENGTH_* are explained by boundRule.
+var rules = map[string]string{
+	"ID_CHANGED":          "Identifiers do not constrain data",
+	"DESCRIPTION_CHANGED": "Descriptions do not constrain data",
+	"TITLE_CHANGED":       "Titles do not constrain data",
+	"DEFAULT_CHANGED":     "Defaults do not constrain data",
+	"SCHEMA_REMOVED":      "A schema without constraints accepts any value",
+	"SCHEMA_ADDED":        "Constraints added where there were none may reject values accepted before",
+	"TYPE_EXTENDED":       "A wider type accepts every value of the previous type",
+	"TYPE_NARROWED":       "A narrower type rejects values of the previous type",
+	"TYPE_CHANGED":        "Values of the previous type are rejected",
+
+	"PATTERN_ADDED":   "Strings that do not match the new pattern are rejected",
+	"PATTERN_REMOVED": "Strings are no longer checked against a pattern",
+	"PATTERN_CHANGED": "Strings matching the previous pattern may not match the new one",
+
+	"MULTIPLE_OF_ADDED":    "Numbers that are not multiples of the new value are rejected",
+	"MULTIPLE_OF_REMOVED":  "Numbers are no longer required to be multiples of a value",
+	"MULTIPLE_OF_REDUCED":  "Every multiple of the previous value is a multiple of the new one",
+	"MULTIPLE_OF_EXPANDED": "Multiples of the previous value are not all multiples of the new one",
+	"MULTIPLE_OF_CHANGED":  "Multiples of the previous value are not all multiples of the new one",
+
+	"REQUIRED_ATTRIBUTE_ADDED":              "Data written without the property is rejected",
+	"REQUIRED_ATTRIBUTE_WITH_DEFAULT_ADDED": "Data written without the property is filled in with the default",
+	"REQUIRED_ATTRIBUTE_REMOVED":            "A property that is no longer required is still accepted",
+
+	"DEPENDENCY_ARRAY_ADDED":    "Data without the newly dependent properties is rejected",
+	"DEPENDENCY_ARRAY_REMOVED":  "Properties no longer depend on others",
+	"DEPENDENCY_ARRAY_EXTENDED": "Data without the newly dependent properties is rejected",
+	"DEPENDENCY_ARRAY_NARROWED": "Fewer properties depend on others",
+	"DEPENDENCY_ARRAY_CHANGED":  "Data without the newly dependent properties is rejected",
+	"DEPENDENCY_SCHEMA_ADDED":   "Data not matching the new dependent schema is rejected",
+	"DEPENDENCY_SCHEMA_REMOVED": "Data is no longer checked against a dependent schema",
+
+	"ADDITIONAL_PROPERTIES_ADDED":    "Properties not listed in the schema are now accepted",
+	"ADDITIONAL_PROPERTIES_REMOVED":  "Properties not listed in the schema are now rejected",
+	"ADDITIONAL_PROPERTIES_EXTENDED": "Properties not listed in the schema are accepted with more values",
+	"ADDITIONAL_PROPERTIES_NARROWED": "Properties not listed in the schema are accepted with fewer values",
+
+	"PROPERTY_ADDED_TO_OPEN_CONTENT_MODEL":                         "The open content model accepted the property with any value, the new schema constrains it",
+	"PROPERTY_WITH_EMPTY_SCHEMA_ADDED_TO_OPEN_CONTENT_MODEL":       "The property accepts any value, as the open content model did",
+	"REQUIRED_PROPERTY_ADDED_TO_UNOPEN_CONTENT_MODEL":              "Data written before never has the property, yet it is required",
+	"REQUIRED_PROPERTY_WITH_DEFAULT_ADDED_TO_UNOPEN_CONTENT_MODEL": "Data written before never has the property, the default fills it in",
+	"OPTIONAL_PROPERTY_ADDED_TO_UNOPEN_CONTENT_MODEL":              "Data written before never has the property, which is optional",
+	"PROPERTY_REMOVED_FROM_OPEN_CONTENT_MODEL":                     "The open content model still accepts the property",
+	"PROPERTY_REMOVED_FROM_CLOSED_CONTENT_MODEL":                   "The closed content model rejects the property found in data written before",
+	"PROPERTY_WITH_FALSE_REMOVED_FROM_CLOSED_CONTENT_MODEL":        "The property could never be set, removing it changes nothing",
+	"PROPERTY_ADDED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL":    "The property accepts every value the previous additional or pattern properties accepted",
+	"PROPERTY_ADDED_NOT_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL":   "The property rejects values the previous additional or pattern properties accepted",
+	"PROPERTY_REMOVED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL":  "The new additional or pattern properties accept every value of the removed property",
+	"PROPERTY_REMOVED_NOT_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL": "The new additional or pattern properties reject values of the removed property",
+
+	"UNIQUE_ITEMS_ADDED":        "Arrays with duplicate items are rejected",
+	"UNIQUE_ITEMS_REMOVED":      "Arrays with duplicate items are accepted",
+	"ADDITIONAL_ITEMS_ADDED":    "Items beyond the listed ones are now accepted",
+	"ADDITIONAL_ITEMS_REMOVED":  "Items beyond the listed ones are now rejected",
+	"ADDITIONAL_ITEMS_EXTENDED": "Items beyond the listed ones are accepted with more values",
+	"ADDITIONAL_ITEMS_NARROWED": "Items beyond the listed ones are accepted with fewer values",
+
+	"ITEM_ADDED_TO_OPEN_CONTENT_MODEL":                         "The open content model accepted the item with any value, the new schema constrains it",
+	"ITEM_WITH_EMPTY_SCHEMA_ADDED_TO_OPEN_CONTENT_MODEL":       "The item accepts any value, as the open content model did",
+	"ITEM_ADDED_TO_CLOSED_CONTENT_MODEL":                       "Data written before never has the item",
+	"ITEM_REMOVED_FROM_OPEN_CONTENT_MODEL":                     "The open content model still accepts the item",
+	"ITEM_REMOVED_FROM_CLOSED_CONTENT_MODEL":                   "The closed content model rejects the item found in data written before",
+	"ITEM_WITH_FALSE_REMOVED_FROM_CLOSED_CONTENT_MODEL":        "The item could never be set, removing it changes nothing",
+	"ITEM_ADDED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL":    "The item accepts every value the previous additional items accepted",
+	"ITEM_ADDED_NOT_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL":   "The item rejects values the previous additional items accepted",
+	"ITEM_REMOVED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL":  "The new additional items accept every value of the removed item",
+	"ITEM_REMOVED_NOT_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL": "The new additional items reject values of the removed item",
+
+	"ENUM_ARRAY_EXTENDED": "Every value allowed before is still allowed",
+	"ENUM_ARRAY_NARROWED": "Values allowed before are rejected",
+	"ENUM_ARRAY_CHANGED":  "Values allowed before are rejected",
+
+	"COMBINED_TYPE_EXTENDED":           "The new combination accepts every value the previous one accepted",
+	"COMBINED_TYPE_CHANGED":            "The new combination rejects values the previous one accepted",
+	"COMBINED_TYPE_SUBSCHEMAS_CHANGED": "Subschemas of the previous combination have no compatible counterpart",
+	"PRODUCT_TYPE_EXTENDED":            "Values must match more subschemas of allOf",
+	"PRODUCT_TYPE_NARROWED":            "Values must match fewer subschemas of allOf",
+	"SUM_TYPE_EXTENDED":                "Values may match more alternatives",
+	"SUM_TYPE_NARROWED":                "Values matching a removed alternative are rejected",
+	"NOT_TYPE_EXTENDED":                "More values are excluded by not",
+	"NOT_TYPE_NARROWED":                "Fewer values are excluded by not",
+	"REFERENCE_CHANGED":                "The schema refers to another schema that is not checked here",
+}
+
+// boundRule explains the changes of bounds such as MAX_LENGTH_INCREASED
+func boundRule(differenceType string) string {
+	for _, suffix := range []string{"_ADDED", "_REMOVED", "_INCREASED", "_DECREASED"} {
+		if !strings.HasSuffix(differenceType, suffix) {
+			continue
+		}
+
+		bound := strings.ToLower(strings.ReplaceAll(strings.TrimSuffix(differenceType, suffix), "_", " "))
+		switch {
+		case suffix == "_ADDED":
+			return "Values beyond the new " + bound + " are rejected"
+		case suffix == "_REMOVED":
+			return "Values are no longer limited by a " + bound
+		case compatibleChanges[differenceType]:
+			return "Every value within the previous " + bound + " is within the new one"
+		default:
+			return "Values within the previous " + bound + " may be beyond the new one"
+		}
+	}
+
+	return ""
+}
+
+// newDifference builds a difference of the given type, with its rule
+func newDifference(differenceType string, path string, description string) Difference {
+	rule, ok := rules[differenceType]
+	if !ok {
+		rule = boundRule(differenceType)
+	}
+
+	return Difference{
+		Type:        differenceType,
+		Path:        path,
+		Compatible:  compatibleChanges[differenceType],
+		Description: description,
+		Rule:        rule,
+	}
+}
@@ -0,0 +1,1066 @@
+package schemaCompatibility
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Keywords that constrain the values accepted by a schema. A schema with none of
+// them, only describing values, accepts any value.
+var validationKeywords = map[string]bool{
+	"$ref": true, "type": true, "enum": true, "const": true, "not": true,
+	"allOf": true, "anyOf": true, "oneOf": true,
+	"maxLength": true, "minLength": true, "pattern": true, "format": true,
+	"maximum": true, "minimum": true, "exclusiveMaximum": true, "exclusiveMinimum": true, "multipleOf": true,
+	"properties": true, "required": true, "additionalProperties": true, "patternProperties": true,
+	"maxProperties": true, "minProperties": true, "dependencies": true, "dependentRequired": true,
+	"dependentSchemas": true, "propertyNames": true,
+	"items": true, "prefixItems": true, "additionalItems": true, "maxItems": true, "minItems": true,
+	"uniqueItems": true, "contains": true,
+}
+
+// Keywords combining subschemas, in the order they are checked
+var combinedKeywords = []string{"allOf", "anyOf", "oneOf"}
+
+// bound is a keyword limiting a value, with the prefix of its difference types
+type bound struct {
+	keyword string
+	name    string
+}
+
+var (
+	stringBounds = []bound{{"maxLength", "MAX_LENGTH"}, {"minLength", "MIN_LENGTH"}}
+	numberBounds = []bound{
+		{"maximum", "MAXIMUM"},
+		{"minimum", "MINIMUM"},
+		{"exclusiveMaximum", "EXCLUSIVE_MAXIMUM"},
+		{"exclusiveMinimum", "EXCLUSIVE_MINIMUM"},
+	}
+	objectBounds = []bound{{"maxProperties", "MAX_PROPERTIES"}, {"minProperties", "MIN_PROPERTIES"}}
+	arrayBounds  = []bound{{"maxItems", "MAX_ITEMS"}, {"minItems", "MIN_ITEMS"}}
+)
+
+// parseJSONSchema decodes a JSON schema, which is either an object or a boolean
+func parseJSONSchema(schema string) (any, error) {
+	var parsed any
+	if err := json.Unmarshal([]byte(schema), &parsed); err != nil {
+		return nil, err
+	}
+
+	switch parsed.(type) {
+	case map[string]any, bool:
+		return parsed, nil
+	default:
+		return nil, fmt.Errorf("a schema must be an object or a boolean")
+	}
+}
+
+// jsonSchemaDiff compares two JSON schemas. Local references are resolved against
+// the root of each schema.
+type jsonSchemaDiff struct {
+	originalRoot any
+	updateRoot   any
+	// Pairs of references being compared, to stop on recursive schemas
+	comparing map[string]bool
+}
+
+func newJSONSchemaDiff(original any, update any) *jsonSchemaDiff {
+	return &jsonSchemaDiff{
+		originalRoot: original,
+		updateRoot:   update,
+		comparing:    make(map[string]bool),
+	}
+}
+
+// compare lists the differences between the schemas at path, where data written with
+// original is read with update. Differences inside a schema the update references
+// are located in the referenced definition, e.g. #/definitions/address/type.
+func (d *jsonSchemaDiff) compare(path string, original any, update any) []Difference {
+	original, originalRef := resolveRef(d.originalRoot, original)
+	update, updateRef := resolveRef(d.updateRoot, update)
+	if updateRef != "" {
+		path = updateRef
+	}
+
+	if originalRef != "" || updateRef != "" {
+		key := originalRef + " " + updateRef
+		if d.comparing[key] {
+			return nil
+		}
+		d.comparing[key] = true
+		defer delete(d.comparing, key)
+	}
+
+	if differences, done := compareExternalRefs(path, original, update); done {
+		return differences
+	}
+
+	// true accepts any value, as the empty schema does
+	if original == true {
+		original = map[string]any{}
+	}
+	if update == true {
+		update = map[string]any{}
+	}
+
+	switch {
+	case original == false && update == false:
+		return nil
+	case update == false:
+		return []Difference{newDifference("TYPE_NARROWED", path, "The schema no longer accepts any value")}
+	case original == false:
+		return []Difference{newDifference("TYPE_EXTENDED", path, "The schema accepts values where it accepted none")}
+	}
+
+	originalSchema := asSchemaObject(original)
+	updateSchema := asSchemaObject(update)
+
+	// A combination of a single schema is that schema
+	if _, subschemas, ok := combined(originalSchema); ok && len(subschemas) == 1 {
+		return d.compare(path, subschemas[0], update)
+	}
+	if criterion, subschemas, ok := combined(updateSchema); ok && len(subschemas) == 1 {
+		return d.compare(childPath(path, criterion, "0"), original, subschemas[0])
+	}
+
+	differences := compareMetadata(path, originalSchema, updateSchema)
+
+	switch {
+	case isEmptySchema(originalSchema) && isEmptySchema(updateSchema):
+		return differences
+	case isEmptySchema(updateSchema):
+		return append(differences, newDifference("SCHEMA_REMOVED", path, "The schema no longer constrains the value"))
+	case isEmptySchema(originalSchema):
+		return append(differences, newDifference("SCHEMA_ADDED", path, "The schema constrains a value that accepted anything"))
+	}
+
+	originalCriterion, originalSubschemas, originalCombined := combined(originalSchema)
+	updateCriterion, updateSubschemas, updateCombined := combined(updateSchema)
+
+	switch {
+	case originalCombined && updateCombined:
+		return append(differences, d.compareCombined(path, originalCriterion, originalSubschemas, updateCriterion, updateSubschemas)...)
+	case updateCombined:
+		// The update is a sum including a schema compatible with the original
+		if updateCriterion != "allOf" {
+			for i, subschema := range updateSubschemas {
+				subdifferences := d.compare(childPath(path, updateCriterion, strconv.Itoa(i)), originalSchema, subschema)
+				if allCompatible(subdifferences) {
+					differences = append(differences, subdifferences...)
+					return append(differences, newDifference("SUM_TYPE_EXTENDED", path, fmt.Sprintf("The schema became an %s including the original schema", updateCriterion)))
+				}
+			}
+		}
+		return append(differences, newDifference("TYPE_CHANGED", path, fmt.Sprintf("The schema became an %s of other schemas", updateCriterion)))
+	case originalCombined:
+		// The original is a product including a schema compatible with the update
+		if originalCriterion == "allOf" {
+			for _, subschema := range originalSubschemas {
+				subdifferences := d.compare(path, subschema, updateSchema)
+				if allCompatible(subdifferences) {
+					differences = append(differences, subdifferences...)
+					return append(differences, newDifference("PRODUCT_TYPE_NARROWED", path, "The schema is one of the schemas of the original allOf"))
+				}
+			}
+		}
+		return append(differences, newDifference("TYPE_CHANGED", path, fmt.Sprintf("The schema is no longer an %s of schemas", originalCriterion)))
+	}
+
+	return append(differences, d.compareKeywords(path, originalSchema, updateSchema)...)
+}
+
+// compareKeywords compares the validation keywords of two schemas
+func (d *jsonSchemaDiff) compareKeywords(path string, original map[string]any, update map[string]any) []Difference {
+	differences := d.compareNot(path, original, update)
+	differences = append(differences, compareEnums(path, original, update)...)
+
+	typeDifferences, disjoint := compareTypes(path, original, update)
+	differences = append(differences, typeDifferences...)
+	if disjoint {
+		return differences
+	}
+
+	differences = append(differences, compareBounds(path, stringBounds, original, update)...)
+	differences = append(differences, comparePatterns(path, original, update)...)
+	differences = append(differences, compareBounds(path, numberBounds, original, update)...)
+	differences = append(differences, compareMultipleOf(path, original, update)...)
+	differences = append(differences, d.compareObjects(path, original, update)...)
+	differences = append(differences, d.compareArrays(path, original, update)...)
+	return differences
+}
+
+// compareCombined compares two combinations of schemas. Every subschema of the
+// smaller combination must have a compatible counterpart in the other one.
+func (d *jsonSchemaDiff) compareCombined(path string, originalCriterion string, originalSubschemas []any, updateCriterion string, updateSubschemas []any) []Difference {
+	if originalCriterion != updateCriterion {
+		if updateCriterion == "anyOf" {
+			return []Difference{newDifference("COMBINED_TYPE_EXTENDED", path, fmt.Sprintf("%s changed to %s", originalCriterion, updateCriterion))}
+		}
+		return []Difference{newDifference("COMBINED_TYPE_CHANGED", path, fmt.Sprintf("%s changed to %s", originalCriterion, updateCriterion))}
+	}
+
+	originalSubschemas = uniqueSchemas(originalSubschemas)
+	updateSubschemas = uniqueSchemas(updateSubschemas)
+
+	var differences []Difference
+	switch {
+	case len(originalSubschemas) < len(updateSubschemas) && updateCriterion == "allOf":
+		differences = append(differences, newDifference("PRODUCT_TYPE_EXTENDED", path, fmt.Sprintf("allOf grew from %d to %d schemas", len(originalSubschemas), len(updateSubschemas))))
+	case len(originalSubschemas) < len(updateSubschemas):
+		differences = append(differences, newDifference("SUM_TYPE_EXTENDED", path, fmt.Sprintf("%s grew from %d to %d schemas", updateCriterion, len(originalSubschemas), len(updateSubschemas))))
+	case len(originalSubschemas) > len(updateSubschemas) && updateCriterion == "allOf":
+		differences = append(differences, newDifference("PRODUCT_TYPE_NARROWED", path, fmt.Sprintf("allOf shrank from %d to %d schemas", len(originalSubschemas), len(updateSubschemas))))
+	case len(originalSubschemas) > len(updateSubschemas):
+		differences = append(differences, newDifference("SUM_TYPE_NARROWED", path, fmt.Sprintf("%s shrank from %d to %d schemas", updateCriterion, len(originalSubschemas), len(updateSubschemas))))
+	}
+
+	compatible := make([][]bool, len(originalSubschemas))
+	for i, originalSubschema := range originalSubschemas {
+		compatible[i] = make([]bool, len(updateSubschemas))
+		for j, updateSubschema := range updateSubschemas {
+			compatible[i][j] = allCompatible(d.compare(childPath(path, updateCriterion, strconv.Itoa(j)), originalSubschema, updateSubschema))
+		}
+	}
+
+	if matched := maximumMatching(compatible, len(updateSubschemas)); matched < min(len(originalSubschemas), len(updateSubschemas)) {
+		differences = append(differences, newDifference("COMBINED_TYPE_SUBSCHEMAS_CHANGED", path, fmt.Sprintf("Only %d schemas of %s have a compatible counterpart", matched, updateCriterion)))
+	}
+
+	return differences
+}
+
+// compareNot compares the schemas excluded by not. The update may exclude fewer values.
+func (d *jsonSchemaDiff) compareNot(path string, original map[string]any, update map[string]any) []Difference {
+	originalNot, inOriginal := original["not"]
+	updateNot, inUpdate := update["not"]
+
+	switch {
+	case !inOriginal && !inUpdate:
+		return nil
+	case !inOriginal:
+		return []Difference{newDifference("NOT_TYPE_EXTENDED", childPath(path, "not"), "not added")}
+	case !inUpdate:
+		return []Difference{newDifference("NOT_TYPE_NARROWED", childPath(path, "not"), "not removed")}
+	}
+
+	// Values excluded by the update must have been excluded by the original
+	differences := d.compare(childPath(path, "not"), updateNot, originalNot)
+	switch {
+	case len(differences) == 0:
+		return nil
+	case allCompatible(differences):
+		return []Difference{newDifference("NOT_TYPE_NARROWED", childPath(path, "not"), "not excludes fewer values")}
+	default:
+		return []Difference{newDifference("NOT_TYPE_EXTENDED", childPath(path, "not"), "not excludes other values")}
+	}
+}
+
+// compareObjects compares the keywords applying to objects
+func (d *jsonSchemaDiff) compareObjects(path string, original map[string]any, update map[string]any) []Difference {
+	differences := compareBounds(path, objectBounds, original, update)
+	differences = append(differences, d.compareProperties(path, original, update)...)
+	differences = append(differences, d.compareRequired(path, original, update)...)
+	differences = append(differences, d.compareAdditional(path, "ADDITIONAL_PROPERTIES", original, "additionalProperties", update, "additionalProperties")...)
+	differences = append(differences, d.compareDependencies(path, original, update)...)
+	return differences
+}
+
+// compareProperties compares the properties of two objects. Added and removed
+// properties are judged by the content model of the other schema: open when any
+// property is accepted, partially open when other properties must match
+// patternProperties or additionalProperties, closed otherwise.
+func (d *jsonSchemaDiff) compareProperties(path string, original map[string]any, update map[string]any) []Difference {
+	originalProperties := schemaObject(original, "properties")
+	updateProperties := schemaObject(update, "properties")
+	required := stringSet(update["required"])
+
+	var differences []Difference
+	for _, name := range unionKeys(originalProperties, updateProperties) {
+		propertyPath := childPath(path, "properties", name)
+		originalProperty, inOriginal := originalProperties[name]
+		updateProperty, inUpdate := updateProperties[name]
+
+		switch {
+		case inOriginal && inUpdate:
+			differences = append(differences, d.compare(propertyPath, originalProperty, updateProperty)...)
+
+		case !inOriginal:
+			description := fmt.Sprintf("Property %s added", name)
+			switch partial := partiallyOpenSchema(original, name); {
+			case isOpenContentModel(original) && isEmptySchema(asSchemaObject(updateProperty)) && updateProperty != false:
+				differences = append(differences, newDifference("PROPERTY_WITH_EMPTY_SCHEMA_ADDED_TO_OPEN_CONTENT_MODEL", propertyPath, description))
+			case isOpenContentModel(original):
+				differences = append(differences, newDifference("PROPERTY_ADDED_TO_OPEN_CONTENT_MODEL", propertyPath, description))
+			case partial != nil && allCompatible(d.compare(propertyPath, partial, updateProperty)):
+				differences = append(differences, newDifference("PROPERTY_ADDED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL", propertyPath, description))
+			case partial != nil:
+				differences = append(differences, newDifference("PROPERTY_ADDED_NOT_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL", propertyPath, description))
+			case required[name] && hasDefault(d.updateRoot, updateProperty):
+				differences = append(differences, newDifference("REQUIRED_PROPERTY_WITH_DEFAULT_ADDED_TO_UNOPEN_CONTENT_MODEL", propertyPath, description))
+			case required[name]:
+				differences = append(differences, newDifference("REQUIRED_PROPERTY_ADDED_TO_UNOPEN_CONTENT_MODEL", propertyPath, description))
+			default:
+				differences = append(differences, newDifference("OPTIONAL_PROPERTY_ADDED_TO_UNOPEN_CONTENT_MODEL", propertyPath, description))
+			}
+
+		default:
+			description := fmt.Sprintf("Property %s removed", name)
+			switch partial := partiallyOpenSchema(update, name); {
+			case isOpenContentModel(update):
+				differences = append(differences, newDifference("PROPERTY_REMOVED_FROM_OPEN_CONTENT_MODEL", propertyPath, description))
+			case partial != nil && allCompatible(d.compare(propertyPath, originalProperty, partial)):
+				differences = append(differences, newDifference("PROPERTY_REMOVED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL", propertyPath, description))
+			case partial != nil:
+				differences = append(differences, newDifference("PROPERTY_REMOVED_NOT_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL", propertyPath, description))
+			case originalProperty == false:
+				differences = append(differences, newDifference("PROPERTY_WITH_FALSE_REMOVED_FROM_CLOSED_CONTENT_MODEL", propertyPath, description))
+			default:
+				differences = append(differences, newDifference("PROPERTY_REMOVED_FROM_CLOSED_CONTENT_MODEL", propertyPath, description))
+			}
+		}
+	}
+
+	return differences
+}
+
+// compareRequired compares the required properties of two objects
+func (d *jsonSchemaDiff) compareRequired(path string, original map[string]any, update map[string]any) []Difference {
+	originalRequired := stringSet(original["required"])
+	updateRequired := stringSet(update["required"])
+	updateProperties := schemaObject(update, "properties")
+
+	var differences []Difference
+	for _, name := range unionKeys(originalRequired, updateRequired) {
+		propertyPath := childPath(path, "required", name)
+		switch {
+		case originalRequired[name] && updateRequired[name]:
+		case originalRequired[name]:
+			differences = append(differences, newDifference("REQUIRED_ATTRIBUTE_REMOVED", propertyPath, fmt.Sprintf("Property %s is no longer required", name)))
+		case hasDefault(d.updateRoot, updateProperties[name]):
+			differences = append(differences, newDifference("REQUIRED_ATTRIBUTE_WITH_DEFAULT_ADDED", propertyPath, fmt.Sprintf("Property %s, with a default, is now required", name)))
+		default:
+			differences = append(differences, newDifference("REQUIRED_ATTRIBUTE_ADDED", propertyPath, fmt.Sprintf("Property %s is now required", name)))
+		}
+	}
+
+	return differences
+}
+
+// compareAdditional compares the values accepted beyond the listed properties or
+// items, read from originalKeyword and updateKeyword, with name the prefix of the
+// difference types
+func (d *jsonSchemaDiff) compareAdditional(path string, name string, original map[string]any, originalKeyword string, update map[string]any, updateKeyword string) []Difference {
+	originalPermitted, originalSchema := additionalSchema(original, originalKeyword)
+	updatePermitted, updateSchema := additionalSchema(update, updateKeyword)
+	keywordPath := childPath(path, updateKeyword)
+	label := strings.ToLower(strings.ReplaceAll(name, "_", " "))
+
+	switch {
+	case originalPermitted && !updatePermitted:
+		return []Difference{newDifference(name+"_REMOVED", keywordPath, label+" no longer allowed")}
+	case !originalPermitted && updatePermitted:
+		return []Difference{newDifference(name+"_ADDED", keywordPath, label+" allowed")}
+	case originalSchema == nil && updateSchema == nil:
+		return nil
+	case originalSchema == nil:
+		return []Difference{newDifference(name+"_NARROWED", keywordPath, label+" restricted to a schema")}
+	case updateSchema == nil:
+		return []Difference{newDifference(name+"_EXTENDED", keywordPath, label+" no longer restricted to a schema")}
+	default:
+		return d.compare(keywordPath, originalSchema, updateSchema)
+	}
+}
+
+// compareDependencies compares the properties and schemas that properties depend on
+func (d *jsonSchemaDiff) compareDependencies(path string, original map[string]any, update map[string]any) []Difference {
+	originalArrays, originalSchemas := dependencies(original)
+	updateArrays, updateSchemas := dependencies(update)
+
+	var differences []Difference
+	for _, name := range unionKeys(originalArrays, updateArrays) {
+		dependencyPath := childPath(path, "dependencies", name)
+		originalArray, inOriginal := originalArrays[name]
+		updateArray, inUpdate := updateArrays[name]
+
+		switch {
+		case !inOriginal:
+			differences = append(differences, newDifference("DEPENDENCY_ARRAY_ADDED", dependencyPath, fmt.Sprintf("Properties required with %s added", name)))
+		case !inUpdate:
+			differences = append(differences, newDifference("DEPENDENCY_ARRAY_REMOVED", dependencyPath, fmt.Sprintf("Properties required with %s removed", name)))
+		case isSubset(originalArray, updateArray) && isSubset(updateArray, originalArray):
+		case isSubset(originalArray, updateArray):
+			differences = append(differences, newDifference("DEPENDENCY_ARRAY_EXTENDED", dependencyPath, fmt.Sprintf("More properties are required with %s", name)))
+		case isSubset(updateArray, originalArray):
+			differences = append(differences, newDifference("DEPENDENCY_ARRAY_NARROWED", dependencyPath, fmt.Sprintf("Fewer properties are required with %s", name)))
+		default:
+			differences = append(differences, newDifference("DEPENDENCY_ARRAY_CHANGED", dependencyPath, fmt.Sprintf("Other properties are required with %s", name)))
+		}
+	}
+
+	for _, name := range unionKeys(originalSchemas, updateSchemas) {
+		dependencyPath := childPath(path, "dependencies", name)
+		originalSchema, inOriginal := originalSchemas[name]
+		updateSchema, inUpdate := updateSchemas[name]
+
+		switch {
+		case !inOriginal:
+			differences = append(differences, newDifference("DEPENDENCY_SCHEMA_ADDED", dependencyPath, fmt.Sprintf("Schema applying with %s added", name)))
+		case !inUpdate:
+			differences = append(differences, newDifference("DEPENDENCY_SCHEMA_REMOVED", dependencyPath, fmt.Sprintf("Schema applying with %s removed", name)))
+		default:
+			differences = append(differences, d.compare(dependencyPath, originalSchema, updateSchema)...)
+		}
+	}
+
+	return differences
+}
+
+// compareArrays compares the keywords applying to arrays. Tuples, listed with an
+// items array or prefixItems, are judged like properties: items beyond the tuple are
+// governed by additionalItems, or items with prefixItems.
+func (d *jsonSchemaDiff) compareArrays(path string, original map[string]any, update map[string]any) []Difference {
+	differences := compareBounds(path, arrayBounds, original, update)
+
+	switch originalUnique, updateUnique := original["uniqueItems"] == true, update["uniqueItems"] == true; {
+	case originalUnique && !updateUnique:
+		differences = append(differences, newDifference("UNIQUE_ITEMS_REMOVED", childPath(path, "uniqueItems"), "uniqueItems removed"))
+	case !originalUnique && updateUnique:
+		differences = append(differences, newDifference("UNIQUE_ITEMS_ADDED", childPath(path, "uniqueItems"), "uniqueItems added"))
+	}
+
+	originalTuple, originalKeyword, originalAdditional := tupleItems(original)
+	updateTuple, updateKeyword, updateAdditional := tupleItems(update)
+
+	if originalTuple == nil && updateTuple == nil {
+		if _, ok := original["items"]; ok {
+			return append(differences, d.compare(childPath(path, "items"), original["items"], orEmpty(update["items"]))...)
+		}
+		if _, ok := update["items"]; ok {
+			return append(differences, d.compare(childPath(path, "items"), map[string]any{}, update["items"])...)
+		}
+		return differences
+	}
+
+	for i := range max(len(originalTuple), len(updateTuple)) {
+		index := strconv.Itoa(i)
+		switch {
+		case i < len(originalTuple) && i < len(updateTuple):
+			differences = append(differences, d.compare(childPath(path, updateKeyword, index), originalTuple[i], updateTuple[i])...)
+
+		case i < len(updateTuple):
+			itemPath := childPath(path, updateKeyword, index)
+			description := fmt.Sprintf("Item %d added", i)
+			permitted, partial := additionalSchema(original, originalAdditional)
+			switch {
+			case permitted && partial == nil && isEmptySchema(asSchemaObject(updateTuple[i])) && updateTuple[i] != false:
+				differences = append(differences, newDifference("ITEM_WITH_EMPTY_SCHEMA_ADDED_TO_OPEN_CONTENT_MODEL", itemPath, description))
+			case permitted && partial == nil:
+				differences = append(differences, newDifference("ITEM_ADDED_TO_OPEN_CONTENT_MODEL", itemPath, description))
+			case partial != nil && allCompatible(d.compare(itemPath, partial, updateTuple[i])):
+				differences = append(differences, newDifference("ITEM_ADDED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL", itemPath, description))
+			case partial != nil:
+				differences = append(differences, newDifference("ITEM_ADDED_NOT_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL", itemPath, description))
+			default:
+				differences = append(differences, newDifference("ITEM_ADDED_TO_CLOSED_CONTENT_MODEL", itemPath, description))
+			}
+
+		default:
+			itemPath := childPath(path, originalKeyword, index)
+			description := fmt.Sprintf("Item %d removed", i)
+			permitted, partial := additionalSchema(update, updateAdditional)
+			switch {
+			case permitted && partial == nil:
+				differences = append(differences, newDifference("ITEM_REMOVED_FROM_OPEN_CONTENT_MODEL", itemPath, description))
+			case partial != nil && allCompatible(d.compare(itemPath, originalTuple[i], partial)):
+				differences = append(differences, newDifference("ITEM_REMOVED_IS_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL", itemPath, description))
+			case partial != nil:
+				differences = append(differences, newDifference("ITEM_REMOVED_NOT_COVERED_BY_PARTIALLY_OPEN_CONTENT_MODEL", itemPath, description))
+			case originalTuple[i] == false:
+				differences = append(differences, newDifference("ITEM_WITH_FALSE_REMOVED_FROM_CLOSED_CONTENT_MODEL", itemPath, description))
+			default:
+				differences = append(differences, newDifference("ITEM_REMOVED_FROM_CLOSED_CONTENT_MODEL", itemPath, description))
+			}
+		}
+	}
+
+	// Items beyond the tuples, governed by items when the other side has no tuple
+	return append(differences, d.compareAdditional(path, "ADDITIONAL_ITEMS", original, originalAdditional, update, updateAdditional)...)
+}
+
+// compareMetadata reports changes of the keywords that only describe a schema
+func compareMetadata(path string, original map[string]any, update map[string]any) []Difference {
+	var differences []Difference
+	for _, metadata := range []struct {
+		keywords       []string
+		differenceType string
+	}{
+		{[]string{"$id", "id"}, "ID_CHANGED"},
+		{[]string{"title"}, "TITLE_CHANGED"},
+		{[]string{"description"}, "DESCRIPTION_CHANGED"},
+		{[]string{"default"}, "DEFAULT_CHANGED"},
+	} {
+		for _, keyword := range metadata.keywords {
+			originalValue, inOriginal := original[keyword]
+			updateValue, inUpdate := update[keyword]
+			if !inOriginal && !inUpdate {
+				continue
+			}
+			if inOriginal != inUpdate || canonicalJSON(originalValue) != canonicalJSON(updateValue) {
+				differences = append(differences, newDifference(metadata.differenceType, childPath(path, keyword), fmt.Sprintf("%s changed from %s to %s", keyword, describeValue(originalValue, inOriginal), describeValue(updateValue, inUpdate))))
+			}
+			break
+		}
+	}
+	return differences
+}
+
+// compareEnums compares the values allowed by enum or const
+func compareEnums(path string, original map[string]any, update map[string]any) []Difference {
+	originalValues, inOriginal := enumValues(original)
+	updateValues, inUpdate := enumValues(update)
+	enumPath := childPath(path, "enum")
+
+	switch {
+	case !inOriginal && !inUpdate:
+		return nil
+	case !inOriginal:
+		return []Difference{newDifference("ENUM_ARRAY_NARROWED", enumPath, fmt.Sprintf("Values restricted to %s", strings.Join(slices.Sorted(maps.Keys(updateValues)), ", ")))}
+	case !inUpdate:
+		return []Difference{newDifference("ENUM_ARRAY_EXTENDED", enumPath, "Values no longer restricted")}
+	}
+
+	originalInUpdate := isSubset(originalValues, updateValues)
+	updateInOriginal := isSubset(updateValues, originalValues)
+	switch {
+	case originalInUpdate && updateInOriginal:
+		return nil
+	case originalInUpdate:
+		return []Difference{newDifference("ENUM_ARRAY_EXTENDED", enumPath, fmt.Sprintf("Values added: %s", strings.Join(missing(updateValues, originalValues), ", ")))}
+	case updateInOriginal:
+		return []Difference{newDifference("ENUM_ARRAY_NARROWED", enumPath, fmt.Sprintf("Values removed: %s", strings.Join(missing(originalValues, updateValues), ", ")))}
+	default:
+		return []Difference{newDifference("ENUM_ARRAY_CHANGED", enumPath, fmt.Sprintf("Values removed: %s", strings.Join(missing(originalValues, updateValues), ", ")))}
+	}
+}
+
+// compareTypes compares the types of two schemas, an integer being a number. It
+// reports whether the types have nothing in common, in which case the keywords of
+// the types are not compared.
+func compareTypes(path string, original map[string]any, update map[string]any) ([]Difference, bool) {
+	originalTypes := schemaTypes(original)
+	updateTypes := schemaTypes(update)
+	typePath := childPath(path, "type")
+
+	switch {
+	case originalTypes == nil && updateTypes == nil:
+		return nil, false
+	case originalTypes == nil:
+		return []Difference{newDifference("TYPE_NARROWED", typePath, fmt.Sprintf("Type restricted to %s", strings.Join(updateTypes, ", ")))}, false
+	case updateTypes == nil:
+		return []Difference{newDifference("TYPE_EXTENDED", typePath, "Type no longer restricted")}, false
+	}
+
+	description := fmt.Sprintf("Type changed from %s to %s", strings.Join(originalTypes, ", "), strings.Join(updateTypes, ", "))
+	originalInUpdate := typesCovered(originalTypes, updateTypes)
+	updateInOriginal := typesCovered(updateTypes, originalTypes)
+	single := len(originalTypes) == 1 && len(updateTypes) == 1
+
+	switch {
+	case originalInUpdate && updateInOriginal:
+		return nil, false
+	case originalInUpdate && single:
+		return []Difference{newDifference("TYPE_EXTENDED", typePath, description)}, false
+	case updateInOriginal && single:
+		return []Difference{newDifference("TYPE_NARROWED", typePath, description)}, false
+	case originalInUpdate:
+		return []Difference{newDifference("SUM_TYPE_EXTENDED", typePath, description)}, false
+	case updateInOriginal:
+		return []Difference{newDifference("SUM_TYPE_NARROWED", typePath, description)}, false
+	default:
+		return []Difference{newDifference("TYPE_CHANGED", typePath, description)}, !typesOverlap(originalTypes, updateTypes)
+	}
+}
+
+// compareBounds compares limits such as maxLength. A larger maximum or a smaller
+// minimum accepts more values.
+func compareBounds(path string, bounds []bound, original map[string]any, update map[string]any) []Difference {
+	var differences []Difference
+	for _, b := range bounds {
+		originalValue, inOriginal := number(original, b.keyword)
+		updateValue, inUpdate := number(update, b.keyword)
+		boundPath := childPath(path, b.keyword)
+
+		switch {
+		case !inOriginal && !inUpdate, originalValue == updateValue && inOriginal && inUpdate:
+		case !inOriginal:
+			differences = append(differences, newDifference(b.name+"_ADDED", boundPath, fmt.Sprintf("%s of %s added", b.keyword, formatNumber(updateValue))))
+		case !inUpdate:
+			differences = append(differences, newDifference(b.name+"_REMOVED", boundPath, fmt.Sprintf("%s of %s removed", b.keyword, formatNumber(originalValue))))
+		case updateValue > originalValue:
+			differences = append(differences, newDifference(b.name+"_INCREASED", boundPath, fmt.Sprintf("%s increased from %s to %s", b.keyword, formatNumber(originalValue), formatNumber(updateValue))))
+		default:
+			differences = append(differences, newDifference(b.name+"_DECREASED", boundPath, fmt.Sprintf("%s decreased from %s to %s", b.keyword, formatNumber(originalValue), formatNumber(updateValue))))
+		}
+	}
+	return differences
+}
+
+// comparePatterns compares the patterns strings must match
+func comparePatterns(path string, original map[string]any, update map[string]any) []Difference {
+	originalPattern, inOriginal := original["pattern"].(string)
+	updatePattern, inUpdate := update["pattern"].(string)
+	patternPath := childPath(path, "pattern")
+
+	switch {
+	case !inOriginal && !inUpdate, inOriginal && inUpdate && originalPattern == updatePattern:
+		return nil
+	case !inOriginal:
+		return []Difference{newDifference("PATTERN_ADDED", patternPath, fmt.Sprintf("pattern %s added", updatePattern))}
+	case !inUpdate:
+		return []Difference{newDifference("PATTERN_REMOVED", patternPath, fmt.Sprintf("pattern %s removed", originalPattern))}
+	default:
+		return []Difference{newDifference("PATTERN_CHANGED", patternPath, fmt.Sprintf("pattern changed from %s to %s", originalPattern, updatePattern))}
+	}
+}
+
+// compareMultipleOf compares the values numbers must be multiples of
+func compareMultipleOf(path string, original map[string]any, update map[string]any) []Difference {
+	originalValue, inOriginal := number(original, "multipleOf")
+	updateValue, inUpdate := number(update, "multipleOf")
+	multiplePath := childPath(path, "multipleOf")
+
+	switch {
+	case !inOriginal && !inUpdate, inOriginal && inUpdate && originalValue == updateValue:
+		return nil
+	case !inOriginal:
+		return []Difference{newDifference("MULTIPLE_OF_ADDED", multiplePath, fmt.Sprintf("multipleOf %s added", formatNumber(updateValue)))}
+	case !inUpdate:
+		return []Difference{newDifference("MULTIPLE_OF_REMOVED", multiplePath, fmt.Sprintf("multipleOf %s removed", formatNumber(originalValue)))}
+	}
+
+	description := fmt.Sprintf("multipleOf changed from %s to %s", formatNumber(originalValue), formatNumber(updateValue))
+	switch {
+	case isMultiple(originalValue, updateValue):
+		return []Difference{newDifference("MULTIPLE_OF_REDUCED", multiplePath, description)}
+	case isMultiple(updateValue, originalValue):
+		return []Difference{newDifference("MULTIPLE_OF_EXPANDED", multiplePath, description)}
+	default:
+		return []Difference{newDifference("MULTIPLE_OF_CHANGED", multiplePath, description)}
+	}
+}
+
+// resolveRef follows local references, such as #/definitions/address, from root. It
+// returns the referenced schema and the last reference followed. External
+// references are left as they are.
+func resolveRef(root any, schema any) (any, string) {
+	ref := ""
+	// A bound on the references followed stops on references to themselves
+	for range 32 {
+		object, ok := schema.(map[string]any)
+		if !ok {
+			return schema, ref
+		}
+
+		target, ok := object["$ref"].(string)
+		if !ok || !strings.HasPrefix(target, "#") {
+			return schema, ref
+		}
+
+		resolved, ok := lookupPointer(root, strings.TrimPrefix(target, "#"))
+		if !ok {
+			return schema, ref
+		}
+		schema, ref = resolved, target
+	}
+	return schema, ref
+}
+
+// compareExternalRefs compares references that could not be resolved locally, which
+// are equal when they point to the same schema
+func compareExternalRefs(path string, original any, update any) ([]Difference, bool) {
+	originalRef, inOriginal := asSchemaObject(original)["$ref"].(string)
+	updateRef, inUpdate := asSchemaObject(update)["$ref"].(string)
+
+	switch {
+	case !inOriginal && !inUpdate:
+		return nil, false
+	case originalRef == updateRef:
+		return nil, true
+	default:
+		return []Difference{newDifference("REFERENCE_CHANGED", childPath(path, "$ref"), fmt.Sprintf("Reference changed from %s to %s", describeValue(originalRef, inOriginal), describeValue(updateRef, inUpdate)))}, true
+	}
+}
+
+// lookupPointer finds the value at a JSON pointer, such as /definitions/address
+func lookupPointer(root any, pointer string) (any, bool) {
+	if pointer == "" {
+		return root, true
+	}
+
+	current := root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch value := current.(type) {
+		case map[string]any:
+			next, ok := value[token]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, false
+			}
+			current = value[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// combined returns the combination a schema stands for. A schema combining
+// subschemas alongside other keywords is the allOf of its parts.
+func combined(schema map[string]any) (string, []any, bool) {
+	var criteria []string
+	for _, keyword := range combinedKeywords {
+		if _, ok := schema[keyword].([]any); ok {
+			criteria = append(criteria, keyword)
+		}
+	}
+	if len(criteria) == 0 {
+		return "", nil, false
+	}
+
+	rest := make(map[string]any)
+	for keyword, value := range schema {
+		if !slices.Contains(criteria, keyword) {
+			rest[keyword] = value
+		}
+	}
+
+	if len(criteria) == 1 && isEmptySchema(rest) {
+		return criteria[0], schema[criteria[0]].([]any), true
+	}
+
+	var parts []any
+	if !isEmptySchema(rest) {
+		parts = append(parts, rest)
+	}
+	for _, criterion := range criteria {
+		parts = append(parts, map[string]any{criterion: schema[criterion]})
+	}
+	return "allOf", parts, true
+}
+
+// maximumMatching counts the pairs of a maximum matching between the rows and the
+// columns of compatible, where a pair can match when compatible is true
+func maximumMatching(compatible [][]bool, columns int) int {
+	matchedRow := make([]int, columns)
+	for i := range matchedRow {
+		matchedRow[i] = -1
+	}
+
+	var augment func(row int, seen []bool) bool
+	augment = func(row int, seen []bool) bool {
+		for column := range columns {
+			if !compatible[row][column] || seen[column] {
+				continue
+			}
+			seen[column] = true
+			if matchedRow[column] < 0 || augment(matchedRow[column], seen) {
+				matchedRow[column] = row
+				return true
+			}
+		}
+		return false
+	}
+
+	matched := 0
+	for row := range compatible {
+		if augment(row, make([]bool, columns)) {
+			matched++
+		}
+	}
+	return matched
+}
+
+// isOpenContentModel reports whether an object accepts any property it does not list
+func isOpenContentModel(schema map[string]any) bool {
+	permitted, additional := additionalSchema(schema, "additionalProperties")
+	return permitted && additional == nil && len(schemaObject(schema, "patternProperties")) == 0
+}
+
+// partiallyOpenSchema returns the schema an unlisted property must match, from
+// patternProperties or additionalProperties, or nil when there is none
+func partiallyOpenSchema(schema map[string]any, name string) any {
+	patternProperties := schemaObject(schema, "patternProperties")
+	for _, pattern := range slices.Sorted(maps.Keys(patternProperties)) {
+		if matcher, err := regexp.Compile(pattern); err == nil && matcher.MatchString(name) {
+			return patternProperties[pattern]
+		}
+	}
+
+	_, additional := additionalSchema(schema, "additionalProperties")
+	return additional
+}
+
+// additionalSchema reads additionalProperties or additionalItems: whether other
+// values are permitted, and the schema they must match if any
+func additionalSchema(schema map[string]any, keyword string) (bool, any) {
+	value, ok := schema[keyword]
+	switch {
+	case !ok || value == true:
+		return true, nil
+	case value == false:
+		return false, nil
+	case isEmptySchema(asSchemaObject(value)):
+		return true, nil
+	default:
+		return true, value
+	}
+}
+
+// tupleItems returns the items of a tuple, the keyword listing them and the keyword
+// for the items beyond them. The tuple is nil when the array is not a tuple.
+func tupleItems(schema map[string]any) ([]any, string, string) {
+	if prefixItems, ok := schema["prefixItems"].([]any); ok {
+		return prefixItems, "prefixItems", "items"
+	}
+	if items, ok := schema["items"].([]any); ok {
+		return items, "items", "additionalItems"
+	}
+	return nil, "items", "items"
+}
+
+// dependencies returns the properties and the schemas that properties depend on
+func dependencies(schema map[string]any) (map[string]map[string]bool, map[string]any) {
+	arrays := make(map[string]map[string]bool)
+	schemas := make(map[string]any)
+
+	for _, keyword := range []string{"dependencies", "dependentRequired", "dependentSchemas"} {
+		for name, value := range schemaObject(schema, keyword) {
+			if _, ok := value.([]any); ok {
+				arrays[name] = stringSet(value)
+			} else {
+				schemas[name] = value
+			}
+		}
+	}
+	return arrays, schemas
+}
+
+// schemaTypes returns the types a schema is restricted to, or nil for any type
+func schemaTypes(schema map[string]any) []string {
+	switch value := schema["type"].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		var types []string
+		for _, item := range value {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	default:
+		return nil
+	}
+}
+
+// typesCovered reports whether every type of types is accepted by by
+func typesCovered(types []string, by []string) bool {
+	for _, name := range types {
+		if !slices.Contains(by, name) && !(name == "integer" && slices.Contains(by, "number")) {
+			return false
+		}
+	}
+	return true
+}
+
+// typesOverlap reports whether some values are of a type of both lists
+func typesOverlap(original []string, update []string) bool {
+	for _, name := range original {
+		if typesCovered([]string{name}, update) || (name == "number" && slices.Contains(update, "integer")) {
+			return true
+		}
+	}
+	return false
+}
+
+// enumValues returns the values allowed by enum or const, in canonical JSON
+func enumValues(schema map[string]any) (map[string]bool, bool) {
+	if value, ok := schema["const"]; ok {
+		return map[string]bool{canonicalJSON(value): true}, true
+	}
+
+	values, ok := schema["enum"].([]any)
+	if !ok {
+		return nil, false
+	}
+
+	set := make(map[string]bool)
+	for _, value := range values {
+		set[canonicalJSON(value)] = true
+	}
+	return set, true
+}
+
+// hasDefault reports whether a property schema has a default value
+func hasDefault(root any, schema any) bool {
+	schema, _ = resolveRef(root, schema)
+	_, ok := asSchemaObject(schema)["default"]
+	return ok
+}
+
+// isEmptySchema reports whether a schema accepts any value, having no validation keyword
+func isEmptySchema(schema map[string]any) bool {
+	for keyword := range schema {
+		if validationKeywords[keyword] {
+			return false
+		}
+	}
+	return true
+}
+
+// allCompatible reports whether every difference is compatible
+func allCompatible(differences []Difference) bool {
+	for _, difference := range differences {
+		if !difference.Compatible {
+			return false
+		}
+	}
+	return true
+}
+
+// uniqueSchemas drops the duplicates of a list of schemas
+func uniqueSchemas(schemas []any) []any {
+	seen := make(map[string]bool)
+	var unique []any
+	for _, schema := range schemas {
+		key := canonicalJSON(schema)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, schema)
+		}
+	}
+	return unique
+}
+
+// asSchemaObject returns the keywords of a schema, none for a boolean
+func asSchemaObject(schema any) map[string]any {
+	if object, ok := schema.(map[string]any); ok {
+		return object
+	}
+	return map[string]any{}
+}
+
+// orEmpty returns the empty schema in place of a missing one
+func orEmpty(schema any) any {
+	if schema == nil {
+		return map[string]any{}
+	}
+	return schema
+}
+
+// schemaObject returns a keyword whose value is an object, such as properties
+func schemaObject(schema map[string]any, keyword string) map[string]any {
+	object, _ := schema[keyword].(map[string]any)
+	return object
+}
+
+// stringSet returns the strings of a JSON array, such as required
+func stringSet(value any) map[string]bool {
+	set := make(map[string]bool)
+	items, _ := value.([]any)
+	for _, item := range items {
+		if name, ok := item.(string); ok {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+func number(schema map[string]any, keyword string) (float64, bool) {
+	value, ok := schema[keyword].(float64)
+	return value, ok
+}
+
+// isMultiple reports whether value is a multiple of divisor
+func isMultiple(value float64, divisor float64) bool {
+	if divisor == 0 {
+		return false
+	}
+	quotient := value / divisor
+	return math.Abs(quotient-math.Round(quotient)) < 1e-9
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func canonicalJSON(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+func describeValue(value any, present bool) string {
+	if !present {
+		return "none"
+	}
+	if text, ok := value.(string); ok {
+		return text
+	}
+	return canonicalJSON(value)
+}
+
+// childPath appends JSON pointer tokens to a path, escaping them
+func childPath(path string, tokens ...string) string {
+	for _, token := range tokens {
+		path += "/" + strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+	}
+	return path
+}
+
+// unionKeys returns the keys of both maps, sorted
+func unionKeys[V any](a map[string]V, b map[string]V) []string {
+	keys := slices.Sorted(maps.Keys(a))
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func isSubset(subset map[string]bool, set map[string]bool) bool {
+	for key := range subset {
+		if !set[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// missing returns the keys of set not in other, sorted
+func missing(set map[string]bool, other map[string]bool) []string {
+	var keys []string
+	for key := range set {
+		if !other[key] {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,209 @@
+// Package schemaDiff compares two versions of a schema.
+//
+// JSON schemas are compared structurally: fields added or removed, types, required
+// fields, enums and other keywords changed, each located by a JSON pointer. Every
+// comparison also carries a line by line diff of the formatted schemas, which is the
+// only diff for schema types without a structural comparison.
+package schemaDiff
+
+import (
+	"encoding/json"
+	"strings"
+
+	"kafka-board/types"
+)
+
+// Kinds of structural changes
+const (
+	FieldAdded        = "FIELD_ADDED"
+	FieldRemoved      = "FIELD_REMOVED"
+	DefinitionAdded   = "DEFINITION_ADDED"
+	DefinitionRemoved = "DEFINITION_REMOVED"
+	TypeChanged       = "TYPE_CHANGED"
+	RequiredAdded     = "REQUIRED_ADDED"
+	RequiredRemoved   = "REQUIRED_REMOVED"
+	EnumChanged       = "ENUM_CHANGED"
+	KeywordChanged    = "KEYWORD_CHANGED"
+)
+
+// maxDiffCells caps the size of the longest common subsequence table, in lines of
+// the original times lines of the update left once the common start and end are set
+// aside, about 8 MB
+const maxDiffCells = 1 << 20
+
+// Operations of a text diff line
+const (
+	LineKept    = " "
+	LineAdded   = "+"
+	LineRemoved = "-"
+)
+
+// Change is one structural change between two schemas
+type Change struct {
+	Kind string `json:"kind"`
+	// JSON pointer to the changed schema or keyword, e.g. #/properties/age/type
+	Path string `json:"path"`
+	// Values before and after the change, empty when absent
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// Line is one line of a text diff
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Diff is the comparison of two schemas
+type Diff struct {
+	SchemaType string `json:"schemaType"`
+	// Set when the schemas were compared structurally
+	Structural bool     `json:"structural"`
+	Changes    []Change `json:"changes"`
+	Lines      []Line   `json:"lines"`
+	// Why there is no structural comparison, when there is none
+	Fallback string `json:"fallback,omitempty"`
+	// Why the changed lines are listed as a block rather than diffed line by line
+	TextFallback string `json:"textFallback,omitempty"`
+}
+
+// Compare compares the original and update versions of a schema. Schemas that cannot
+// be compared structurally, e.g. Avro and Protobuf schemas or invalid JSON, get the
+// text diff alone, with the reason in Fallback.
+func Compare(original types.Schema, update types.Schema) Diff {
+	lines, textFallback := compareLines(formatSchema(original), formatSchema(update))
+	diff := Diff{
+		SchemaType:   update.GetSchemaType(),
+		Changes:      []Change{},
+		Lines:        lines,
+		TextFallback: textFallback,
+	}
+
+	switch {
+	case original.GetSchemaType() != update.GetSchemaType():
+		diff.Fallback = "The versions have different schema types"
+	case update.GetSchemaType() != types.SchemaTypeJSON:
+		diff.Fallback = "Structural diffs are only available for JSON schemas"
+	default:
+		changes, err := compareJSONSchemas(original.Schema, update.Schema)
+		if err != nil {
+			diff.Fallback = err.Error()
+			break
+		}
+		diff.Structural = true
+		diff.Changes = append(diff.Changes, changes...)
+	}
+
+	return diff
+}
+
+// formatSchema formats JSON and Avro schemas as the schema page does, indented and
+// with sorted keys, so that the text diff is line by line and ignores the key order.
+// Other schemas are compared as they are.
+func formatSchema(schema types.Schema) string {
+	if schema.GetSchemaType() == types.SchemaTypeProtobuf {
+		return schema.Schema
+	}
+
+	var parsed any
+	if err := json.Unmarshal([]byte(schema.Schema), &parsed); err != nil {
+		return schema.Schema
+	}
+	formatted, err := json.MarshalIndent(parsed, "", "    ")
+	if err != nil {
+		return schema.Schema
+	}
+	return string(formatted)
+}
+
+// compareLines lists the lines of both texts, marking the lines only in original as
+// removed and the lines only in update as added. When too many lines changed to diff
+// them, the changed lines are all removed then added, and the reason is returned.
+func compareLines(original string, update string) ([]Line, string) {
+	originalLines := strings.Split(original, "\n")
+	updateLines := strings.Split(update, "\n")
+
+	// Most versions differ in a few lines, keep the common start and end out of the
+	// longest common subsequence table
+	prefix := 0
+	for prefix < len(originalLines) && prefix < len(updateLines) && originalLines[prefix] == updateLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(originalLines)-prefix && suffix < len(updateLines)-prefix &&
+		originalLines[len(originalLines)-1-suffix] == updateLines[len(updateLines)-1-suffix] {
+		suffix++
+	}
+
+	originalChanged := originalLines[prefix : len(originalLines)-suffix]
+	updateChanged := updateLines[prefix : len(updateLines)-suffix]
+
+	lines := make([]Line, 0, len(originalLines)+len(updateLines))
+	for _, text := range originalLines[:prefix] {
+		lines = append(lines, Line{Op: LineKept, Text: text})
+	}
+
+	fallback := ""
+	if len(originalChanged)*len(updateChanged) > maxDiffCells {
+		fallback = "The versions differ in too many lines to diff, the changed lines are shown as a block"
+		for _, text := range originalChanged {
+			lines = append(lines, Line{Op: LineRemoved, Text: text})
+		}
+		for _, text := range updateChanged {
+			lines = append(lines, Line{Op: LineAdded, Text: text})
+		}
+	} else {
+		lines = append(lines, longestCommonSubsequence(originalChanged, updateChanged)...)
+	}
+
+	for _, text := range originalLines[len(originalLines)-suffix:] {
+		lines = append(lines, Line{Op: LineKept, Text: text})
+	}
+
+	return lines, fallback
+}
+
+// longestCommonSubsequence diffs two lists of lines, keeping their longest common
+// subsequence and marking the other lines as removed or added
+func longestCommonSubsequence(original []string, update []string) []Line {
+	// lengths[i][j] is the length of the longest common subsequence of original[i:]
+	// and update[j:]
+	lengths := make([][]int, len(original)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(update)+1)
+	}
+	for i := len(original) - 1; i >= 0; i-- {
+		for j := len(update) - 1; j >= 0; j-- {
+			if original[i] == update[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var lines []Line
+	i, j := 0, 0
+	for i < len(original) && j < len(update) {
+		switch {
+		case original[i] == update[j]:
+			lines = append(lines, Line{Op: LineKept, Text: original[i]})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			lines = append(lines, Line{Op: LineRemoved, Text: original[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: LineAdded, Text: update[j]})
+			j++
+		}
+	}
+	for ; i < len(original); i++ {
+		lines = append(lines, Line{Op: LineRemoved, Text: original[i]})
+	}
+	for ; j < len(update); j++ {
+		lines = append(lines, Line{Op: LineAdded, Text: update[j]})
+	}
+
+	return lines
+}
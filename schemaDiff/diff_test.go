@@ -0,0 +1,164 @@
+package schemaDiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"kafka-board/types"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name               string
+		original           types.Schema
+		update             types.Schema
+		expectedStructural bool
+		expectedChanges    []Change
+	}{
+		{
+			name:               "identical schemas",
+			original:           types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object", "properties": {"id": {"type": "string"}}}`},
+			update:             types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"properties": {"id": {"type": "string"}}, "type": "object"}`},
+			expectedStructural: true,
+			expectedChanges:    []Change{},
+		},
+		{
+			name:               "fields added and removed",
+			original:           types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object", "properties": {"id": {"type": "string"}, "legacy": {"type": "integer"}}}`},
+			update:             types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object", "properties": {"id": {"type": "string"}, "email": {"type": "string"}}}`},
+			expectedStructural: true,
+			expectedChanges: []Change{
+				{Kind: FieldAdded, Path: "#/properties/email", After: "string"},
+				{Kind: FieldRemoved, Path: "#/properties/legacy", Before: "integer"},
+			},
+		},
+		{
+			name:               "type changed in a nested field",
+			original:           types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object", "properties": {"address": {"type": "object", "properties": {"zip": {"type": "integer"}}}}}`},
+			update:             types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object", "properties": {"address": {"type": "object", "properties": {"zip": {"type": ["string", "null"]}}}}}`},
+			expectedStructural: true,
+			expectedChanges: []Change{
+				{Kind: TypeChanged, Path: "#/properties/address/properties/zip/type", Before: "integer", After: "null|string"},
+			},
+		},
+		{
+			name:               "required and enum changed",
+			original:           types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "status": {"enum": ["NEW", "PAID"]}}}`},
+			update:             types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object", "required": ["status"], "properties": {"id": {"type": "string"}, "status": {"enum": ["NEW", "PAID", "SHIPPED"]}}}`},
+			expectedStructural: true,
+			expectedChanges: []Change{
+				{Kind: EnumChanged, Path: "#/properties/status/enum", Before: `["NEW","PAID"]`, After: `["NEW","PAID","SHIPPED"]`},
+				{Kind: RequiredAdded, Path: "#/properties/status"},
+				{Kind: RequiredRemoved, Path: "#/properties/id"},
+			},
+		},
+		{
+			name:               "keywords and items changed",
+			original:           types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type": "array", "items": {"type": "string", "maxLength": 10}}`},
+			update:             types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type": "array", "items": {"type": "string", "format": "email"}}`},
+			expectedStructural: true,
+			expectedChanges: []Change{
+				{Kind: KeywordChanged, Path: "#/items/format", After: `"email"`},
+				{Kind: KeywordChanged, Path: "#/items/maxLength", Before: "10"},
+			},
+		},
+		{
+			name:            "avro schemas get the text diff only",
+			original:        types.Schema{SchemaType: types.SchemaTypeAvro, Schema: `{"type": "record", "name": "Order", "fields": []}`},
+			update:          types.Schema{SchemaType: types.SchemaTypeAvro, Schema: `{"type": "record", "name": "Order", "fields": [{"name": "id", "type": "string"}]}`},
+			expectedChanges: []Change{},
+		},
+		{
+			name:            "invalid JSON schemas get the text diff only",
+			original:        types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type": "object"}`},
+			update:          types.Schema{SchemaType: types.SchemaTypeJSON, Schema: `{"type": `},
+			expectedChanges: []Change{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := Compare(tt.original, tt.update)
+
+			if diff.Structural != tt.expectedStructural {
+				t.Errorf("Structural = %t, want %t (fallback %q)", diff.Structural, tt.expectedStructural, diff.Fallback)
+			}
+			if !diff.Structural && diff.Fallback == "" {
+				t.Errorf("Fallback is empty for a text diff")
+			}
+			if !reflect.DeepEqual(diff.Changes, tt.expectedChanges) {
+				t.Errorf("Changes = %+v, want %+v", diff.Changes, tt.expectedChanges)
+			}
+		})
+	}
+}
+
+func TestCompareLines(t *testing.T) {
+	// Blocks of lines too large to diff line by line
+	var removedBlock, addedBlock []string
+	for range 800 {
+		removedBlock = append(removedBlock, "b", "c")
+		addedBlock = append(addedBlock, "c", "b")
+	}
+
+	tests := []struct {
+		name     string
+		original string
+		update   string
+		expected []Line
+		// Set when the changed lines are too many to diff
+		expectedFallback bool
+	}{
+		{
+			name:     "line changed between kept lines",
+			original: "a\nb\nc",
+			update:   "a\nx\nc",
+			expected: []Line{{LineKept, "a"}, {LineRemoved, "b"}, {LineAdded, "x"}, {LineKept, "c"}},
+		},
+		{
+			name:     "lines added and removed",
+			original: "a\nb\nc\nd",
+			update:   "b\nc\ne\nd",
+			expected: []Line{{LineRemoved, "a"}, {LineKept, "b"}, {LineKept, "c"}, {LineAdded, "e"}, {LineKept, "d"}},
+		},
+		{
+			name:     "identical texts",
+			original: "a\nb",
+			update:   "a\nb",
+			expected: []Line{{LineKept, "a"}, {LineKept, "b"}},
+		},
+		{
+			name:             "too many changed lines",
+			original:         "a\n" + strings.Join(removedBlock, "\n") + "\nd",
+			update:           "a\n" + strings.Join(addedBlock, "\n") + "\nd",
+			expected:         changedBlock("a", removedBlock, addedBlock, "d"),
+			expectedFallback: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, fallback := compareLines(tt.original, tt.update)
+			if !reflect.DeepEqual(lines, tt.expected) {
+				t.Errorf("compareLines() = %v, want %v", lines, tt.expected)
+			}
+			if (fallback != "") != tt.expectedFallback {
+				t.Errorf("compareLines() fallback = %q, want one: %t", fallback, tt.expectedFallback)
+			}
+		})
+	}
+}
+
+// changedBlock is the diff of texts with a kept first and last line and every line
+// between them changed
+func changedBlock(first string, removed []string, added []string, last string) []Line {
+	lines := []Line{{LineKept, first}}
+	for _, text := range removed {
+		lines = append(lines, Line{LineRemoved, text})
+	}
+	for _, text := range added {
+		lines = append(lines, Line{LineAdded, text})
+	}
+	return append(lines, Line{LineKept, last})
+}
@@ -0,0 +1,243 @@
+package schemaDiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Keywords holding a map of named subschemas, compared entry by entry
+var namedSchemaKeywords = map[string][2]string{
+	"properties":        {FieldAdded, FieldRemoved},
+	"patternProperties": {FieldAdded, FieldRemoved},
+	"definitions":       {DefinitionAdded, DefinitionRemoved},
+	"$defs":             {DefinitionAdded, DefinitionRemoved},
+}
+
+// Keywords holding a subschema, or a list of subschemas compared by position
+var subschemaKeywords = map[string]bool{
+	"items":                true,
+	"additionalItems":      true,
+	"additionalProperties": true,
+	"contains":             true,
+	"propertyNames":        true,
+	"not":                  true,
+	"if":                   true,
+	"then":                 true,
+	"else":                 true,
+	"allOf":                true,
+	"anyOf":                true,
+	"oneOf":                true,
+	"prefixItems":          true,
+}
+
+// compareJSONSchemas lists the structural changes from the original to the update
+// JSON schema
+func compareJSONSchemas(original string, update string) ([]Change, error) {
+	var originalSchema, updateSchema any
+	if err := json.Unmarshal([]byte(original), &originalSchema); err != nil {
+		return nil, fmt.Errorf("invalid original schema: %w", err)
+	}
+	if err := json.Unmarshal([]byte(update), &updateSchema); err != nil {
+		return nil, fmt.Errorf("invalid updated schema: %w", err)
+	}
+
+	return compareSchemas("#", originalSchema, updateSchema), nil
+}
+
+// compareSchemas compares two subschemas found at path
+func compareSchemas(path string, original any, update any) []Change {
+	originalObject, originalIsObject := original.(map[string]any)
+	updateObject, updateIsObject := update.(map[string]any)
+	if !originalIsObject || !updateIsObject {
+		// Boolean schemas, or a schema replaced by a boolean
+		if canonicalJSON(original) == canonicalJSON(update) {
+			return nil
+		}
+		return []Change{{Kind: KeywordChanged, Path: path, Before: canonicalJSON(original), After: canonicalJSON(update)}}
+	}
+
+	var changes []Change
+	for _, keyword := range unionKeys(originalObject, updateObject) {
+		originalValue, inOriginal := originalObject[keyword]
+		updateValue, inUpdate := updateObject[keyword]
+		keywordPath := childPath(path, keyword)
+
+		switch {
+		case keyword == "type":
+			if describeType(originalValue) != describeType(updateValue) {
+				changes = append(changes, Change{Kind: TypeChanged, Path: keywordPath, Before: describeType(originalValue), After: describeType(updateValue)})
+			}
+		case keyword == "required":
+			changes = append(changes, compareRequired(path, originalValue, updateValue)...)
+		case keyword == "enum":
+			if canonicalJSON(originalValue) != canonicalJSON(updateValue) {
+				changes = append(changes, Change{Kind: EnumChanged, Path: keywordPath, Before: describeValue(originalValue, inOriginal), After: describeValue(updateValue, inUpdate)})
+			}
+		case namedSchemaKeywords[keyword] != [2]string{}:
+			changes = append(changes, compareNamedSchemas(keywordPath, namedSchemaKeywords[keyword], originalValue, updateValue)...)
+		case subschemaKeywords[keyword] && inOriginal && inUpdate:
+			changes = append(changes, compareSubschemas(keywordPath, originalValue, updateValue)...)
+		default:
+			if canonicalJSON(originalValue) != canonicalJSON(updateValue) {
+				changes = append(changes, Change{Kind: KeywordChanged, Path: keywordPath, Before: describeValue(originalValue, inOriginal), After: describeValue(updateValue, inUpdate)})
+			}
+		}
+	}
+
+	return changes
+}
+
+// compareNamedSchemas compares maps of named subschemas such as properties, where
+// entries are added, removed or changed
+func compareNamedSchemas(path string, kinds [2]string, original any, update any) []Change {
+	originalSchemas, _ := original.(map[string]any)
+	updateSchemas, _ := update.(map[string]any)
+
+	var changes []Change
+	for _, name := range unionKeys(originalSchemas, updateSchemas) {
+		originalSchema, inOriginal := originalSchemas[name]
+		updateSchema, inUpdate := updateSchemas[name]
+		namePath := childPath(path, name)
+
+		switch {
+		case !inOriginal:
+			changes = append(changes, Change{Kind: kinds[0], Path: namePath, After: describeSchema(updateSchema)})
+		case !inUpdate:
+			changes = append(changes, Change{Kind: kinds[1], Path: namePath, Before: describeSchema(originalSchema)})
+		default:
+			changes = append(changes, compareSchemas(namePath, originalSchema, updateSchema)...)
+		}
+	}
+
+	return changes
+}
+
+// compareSubschemas compares a subschema, or lists of subschemas position by position
+func compareSubschemas(path string, original any, update any) []Change {
+	originalList, originalIsList := original.([]any)
+	updateList, updateIsList := update.([]any)
+	if !originalIsList || !updateIsList {
+		if originalIsList != updateIsList {
+			return []Change{{Kind: KeywordChanged, Path: path, Before: canonicalJSON(original), After: canonicalJSON(update)}}
+		}
+		return compareSchemas(path, original, update)
+	}
+
+	var changes []Change
+	for i := range max(len(originalList), len(updateList)) {
+		itemPath := childPath(path, fmt.Sprintf("%d", i))
+		switch {
+		case i >= len(originalList):
+			changes = append(changes, Change{Kind: KeywordChanged, Path: itemPath, After: describeSchema(updateList[i])})
+		case i >= len(updateList):
+			changes = append(changes, Change{Kind: KeywordChanged, Path: itemPath, Before: describeSchema(originalList[i])})
+		default:
+			changes = append(changes, compareSchemas(itemPath, originalList[i], updateList[i])...)
+		}
+	}
+
+	return changes
+}
+
+// compareRequired lists the properties that became required or optional
+func compareRequired(path string, original any, update any) []Change {
+	originalRequired := stringList(original)
+	updateRequired := stringList(update)
+
+	var changes []Change
+	for _, name := range updateRequired {
+		if !slices.Contains(originalRequired, name) {
+			changes = append(changes, Change{Kind: RequiredAdded, Path: childPath(path, "properties", name)})
+		}
+	}
+	for _, name := range originalRequired {
+		if !slices.Contains(updateRequired, name) {
+			changes = append(changes, Change{Kind: RequiredRemoved, Path: childPath(path, "properties", name)})
+		}
+	}
+
+	return changes
+}
+
+// describeType describes a type keyword, e.g. "string" or "null|string"
+func describeType(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case []any:
+		names := stringList(value)
+		sort.Strings(names)
+		return strings.Join(names, "|")
+	default:
+		return ""
+	}
+}
+
+// describeSchema describes an added or removed subschema by its type or reference,
+// or by its JSON when it has neither
+func describeSchema(schema any) string {
+	if object, ok := schema.(map[string]any); ok {
+		if schemaType := describeType(object["type"]); schemaType != "" {
+			return schemaType
+		}
+		if ref, ok := object["$ref"].(string); ok {
+			return ref
+		}
+	}
+	return canonicalJSON(schema)
+}
+
+// describeValue describes a keyword value, empty when the keyword is absent
+func describeValue(value any, present bool) string {
+	if !present {
+		return ""
+	}
+	return canonicalJSON(value)
+}
+
+// canonicalJSON encodes a value with sorted keys, so equal values encode the same
+func canonicalJSON(value any) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// childPath appends tokens to a JSON pointer, escaping them
+func childPath(path string, tokens ...string) string {
+	for _, token := range tokens {
+		path += "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+	}
+	return path
+}
+
+// stringList returns the strings of a JSON array
+func stringList(value any) []string {
+	list, _ := value.([]any)
+	var strs []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+// unionKeys returns the keys of both maps, sorted
+func unionKeys(a map[string]any, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,295 @@
+package schemaDirectory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"kafka-board/helpers"
+	"kafka-board/schemaCanonical"
+	"kafka-board/types"
+)
+
+// Registry is the registry a schema directory is compared with
+type Registry interface {
+	ReturnSubjects(ctx context.Context) ([]string, error)
+	ReturnSubjectConfigs(ctx context.Context, subjectNames []string) ([]types.SubjectConfigInterface, error)
+	GetGlobalConfig(ctx context.Context) (types.GlobalConfig, error)
+	GetSchemas(ctx context.Context, subjectName string, includeDeleted bool) ([]types.Schema, error)
+	GetSubjectVersion(ctx context.Context, subjectName string, version int, includeDeleted bool) (types.Schema, error)
+	ResolveReferences(ctx context.Context, schema types.Schema) ([]types.ResolvedReference, error)
+	TestSchemaAgainstVersions(ctx context.Context, subjectName string, latestOnly bool, proposed types.Schema) (types.Response, error)
+}
+
+// Statuses of a subject compared with the registry
+const (
+	StatusNewSubject = "new subject"
+	// The local schema differs from every version in the registry
+	StatusNewVersion = "new version"
+	StatusInSync     = "in sync"
+	// The local schema matches a version older than the latest one
+	StatusBehind         = "behind"
+	StatusOnlyInRegistry = "only in registry"
+)
+
+// defaultLevel is the compatibility level of registries without one configured
+const defaultLevel = "BACKWARD"
+
+// Report lists the differences between a schema directory and a registry
+type Report struct {
+	Subjects []SubjectDiff `json:"subjects"`
+}
+
+// SubjectDiff is the difference between a schema file and its subject
+type SubjectDiff struct {
+	Subject string `json:"subject"`
+	// Path of the schema file, empty for subjects only in the registry
+	Path   string `json:"path,omitempty"`
+	Status string `json:"status"`
+	// Latest version of the subject in the registry
+	LatestVersion int `json:"latestVersion,omitempty"`
+	// Version of the registry the schema file matches
+	MatchedVersion int `json:"matchedVersion,omitempty"`
+	// Set for new subjects and new versions
+	Verdict *Verdict `json:"verdict,omitempty"`
+	// Config settings of the sidecar differing from the registry
+	ConfigDrift []ConfigDrift `json:"configDrift,omitempty"`
+}
+
+// Verdict tells whether a proposed schema would be accepted by the registry
+type Verdict struct {
+	// Nil when the compatibility could not be determined
+	IsCompatible *bool  `json:"isCompatible"`
+	Message      string `json:"message"`
+	// Compatibility level the schema was checked at, empty for new subjects
+	Level             string                  `json:"level,omitempty"`
+	Incompatibilities []types.Incompatibility `json:"incompatibilities,omitempty"`
+}
+
+// ConfigDrift is a config setting whose value in the sidecar differs from the
+// registry
+type ConfigDrift struct {
+	Setting string `json:"setting"`
+	Local   string `json:"local"`
+	// "unset" when neither the subject nor the registry sets it
+	Registry string `json:"registry"`
+	// Set when the subject does not set it and the registry value is the global one
+	Global bool `json:"global"`
+}
+
+// Failed returns the number of proposed schemas that are not compatible, or whose
+// compatibility could not be determined
+func (r Report) Failed() int {
+	failed := 0
+	for _, subject := range r.Subjects {
+		if subject.Verdict != nil && (subject.Verdict.IsCompatible == nil || !*subject.Verdict.IsCompatible) {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Compare compares schema files with their subjects in the registry. Schemas are
+// compared by their canonical form, so formatting does not count as a difference.
+// Proposed schemas are checked against the latest version, or against every
+// version when the subject has a *_TRANSITIVE compatibility level. Subjects of the
+// registry without a schema file are listed last.
+func Compare(ctx context.Context, registry Registry, schemas []LocalSchema) (Report, error) {
+	subjects, err := registry.ReturnSubjects(ctx)
+	if helpers.CheckErr(err) {
+		return Report{}, fmt.Errorf("error listing subjects: %w", err)
+	}
+	inRegistry := make(map[string]bool, len(subjects))
+	for _, subjectName := range subjects {
+		inRegistry[subjectName] = true
+	}
+
+	globalConfig, err := registry.GetGlobalConfig(ctx)
+	if helpers.CheckErr(err) {
+		return Report{}, fmt.Errorf("error fetching the global config: %w", err)
+	}
+	global := types.SubjectConfig(globalConfig).Payload().Settings()
+
+	names := make([]string, len(schemas))
+	for i, schema := range schemas {
+		names[i] = schema.Subject
+	}
+	configs, err := registry.ReturnSubjectConfigs(ctx, names)
+	if helpers.CheckErr(err) {
+		return Report{}, fmt.Errorf("error fetching subject configs: %w", err)
+	}
+
+	report := Report{Subjects: make([]SubjectDiff, 0, len(schemas))}
+	local := make(map[string]bool, len(schemas))
+	for i, schema := range schemas {
+		local[schema.Subject] = true
+
+		own := map[string]string{}
+		switch config := configs[i].(type) {
+		case types.SubjectConfig:
+			own = config.Payload().Settings()
+		case types.SubjectConfigError:
+			return Report{}, fmt.Errorf("error fetching the config of %s: %s", schema.Subject, config.Error)
+		}
+
+		diff, err := compareSubject(ctx, registry, schema, inRegistry[schema.Subject], effectiveLevel(own, global))
+		if helpers.CheckErr(err) {
+			return Report{}, err
+		}
+		if schema.Config != nil {
+			diff.ConfigDrift = configDrift(*schema.Config, own, global)
+		}
+
+		report.Subjects = append(report.Subjects, diff)
+	}
+
+	sort.Strings(subjects)
+	for _, subjectName := range subjects {
+		if !local[subjectName] {
+			report.Subjects = append(report.Subjects, SubjectDiff{Subject: subjectName, Status: StatusOnlyInRegistry})
+		}
+	}
+
+	return report, nil
+}
+
+// compareSubject looks for the version of the subject matching a schema file,
+// newest first, and checks the schema when it matches none
+func compareSubject(ctx context.Context, registry Registry, schema LocalSchema, exists bool, level string) (SubjectDiff, error) {
+	diff := SubjectDiff{Subject: schema.Subject, Path: schema.Path}
+
+	var versions []types.Schema
+	if exists {
+		var err error
+		versions, err = registry.GetSchemas(ctx, schema.Subject, false)
+		if helpers.CheckErr(err) {
+			return diff, fmt.Errorf("error listing the versions of %s: %w", schema.Subject, err)
+		}
+	}
+
+	// Subjects whose versions are all soft-deleted take a new first version
+	if len(versions) == 0 {
+		diff.Status = StatusNewSubject
+		diff.Verdict = parseVerdict(ctx, registry, schema.Schema)
+		return diff, nil
+	}
+
+	diff.LatestVersion = versions[len(versions)-1].Version
+	localForm := comparableForm(ctx, registry, schema.Schema)
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		version, err := registry.GetSubjectVersion(ctx, schema.Subject, versions[i].Version, false)
+		if helpers.CheckErr(err) {
+			return diff, fmt.Errorf("error fetching version %d of %s: %w", versions[i].Version, schema.Subject, err)
+		}
+
+		if version.GetSchemaType() == schema.Schema.GetSchemaType() && comparableForm(ctx, registry, version) == localForm {
+			diff.MatchedVersion = version.Version
+			diff.Status = StatusBehind
+			if version.Version == diff.LatestVersion {
+				diff.Status = StatusInSync
+			}
+			return diff, nil
+		}
+	}
+
+	diff.Status = StatusNewVersion
+	diff.Verdict = compatibilityVerdict(ctx, registry, schema.Schema, level)
+	return diff, nil
+}
+
+// comparableForm returns the SHA-256 fingerprint of the canonical form of a schema,
+// or its text when it has none
+func comparableForm(ctx context.Context, registry Registry, schema types.Schema) string {
+	references, err := registry.ResolveReferences(ctx, schema)
+	if helpers.CheckErr(err) {
+		return schema.Schema
+	}
+
+	canonical, err := schemaCanonical.Canonicalize(schema, references)
+	if helpers.CheckErr(err) {
+		return schema.Schema
+	}
+
+	return canonical.SHA256
+}
+
+// parseVerdict checks the schema of a new subject, which has no versions to be
+// compatible with but must parse
+func parseVerdict(ctx context.Context, registry Registry, schema types.Schema) *Verdict {
+	isCompatible := false
+
+	references, err := registry.ResolveReferences(ctx, schema)
+	if helpers.CheckErr(err) {
+		return &Verdict{IsCompatible: &isCompatible, Message: fmt.Sprintf("Error resolving schema references: %v", err)}
+	}
+
+	if _, err := schemaCanonical.Canonicalize(schema, references); helpers.CheckErr(err) {
+		return &Verdict{IsCompatible: &isCompatible, Message: fmt.Sprintf("The schema does not parse: %v", err)}
+	}
+
+	isCompatible = true
+	return &Verdict{IsCompatible: &isCompatible, Message: "No versions to check against, the schema parses"}
+}
+
+// compatibilityVerdict asks the registry whether a new version would be accepted
+func compatibilityVerdict(ctx context.Context, registry Registry, schema types.Schema, level string) *Verdict {
+	latestOnly := !strings.HasSuffix(level, "_TRANSITIVE")
+
+	response, err := registry.TestSchemaAgainstVersions(ctx, schema.Subject, latestOnly, schema)
+	if helpers.CheckErr(err) {
+		message := response.Message
+		if message == "" {
+			message = err.Error()
+		}
+		return &Verdict{Message: message, Level: level}
+	}
+
+	verdict := &Verdict{IsCompatible: response.IsCompatible, Message: response.Message, Level: level}
+	for _, version := range response.Versions {
+		verdict.Incompatibilities = append(verdict.Incompatibilities, version.Incompatibilities...)
+	}
+	return verdict
+}
+
+// effectiveLevel returns the compatibility level of a subject, its own or the
+// global one
+func effectiveLevel(own map[string]string, global map[string]string) string {
+	if level, ok := own["compatibility"]; ok {
+		return level
+	}
+	if level, ok := global["compatibility"]; ok {
+		return level
+	}
+	return defaultLevel
+}
+
+// configDrift lists the settings of a sidecar config whose value differs from the
+// one applying to the subject in the registry
+func configDrift(local types.ConfigPayload, own map[string]string, global map[string]string) []ConfigDrift {
+	settings := local.Settings()
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var drift []ConfigDrift
+	for _, name := range names {
+		registryValue, ok := own[name]
+		fromGlobal := false
+		if !ok {
+			registryValue, fromGlobal = global[name]
+		}
+		if !ok && !fromGlobal {
+			registryValue = "unset"
+		}
+
+		if settings[name] != registryValue {
+			drift = append(drift, ConfigDrift{Setting: name, Local: settings[name], Registry: registryValue, Global: fromGlobal})
+		}
+	}
+	return drift
+}
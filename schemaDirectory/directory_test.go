@@ -0,0 +1,283 @@
+package schemaDirectory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kafka-board/types"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            map[string]string
+		naming           string
+		expectedSubjects []string
+		expectedTypes    []string
+		expectError      bool
+	}{
+		{
+			name: "Naming convention - File names are put into the convention",
+			files: map[string]string{
+				"orders.avsc":           `{"type": "string"}`,
+				"billing/payments.json": `{"type": "object"}`,
+				"users.proto":           `syntax = "proto3";`,
+				"README.md":             "schemas",
+				".git/config.json":      `{}`,
+			},
+			naming:           "{name}-value",
+			expectedSubjects: []string{"orders-value", "payments-value", "users-value"},
+			expectedTypes:    []string{types.SchemaTypeAvro, types.SchemaTypeJSON, types.SchemaTypeProtobuf},
+		},
+		{
+			name: "Sidecar - Not a schema of its own",
+			files: map[string]string{
+				"orders.json":         `{"type": "object"}`,
+				"orders.subject.json": `{"config": {"compatibility": "FULL"}}`,
+			},
+			naming:           DefaultNaming,
+			expectedSubjects: []string{"orders"},
+			expectedTypes:    []string{types.SchemaTypeJSON},
+		},
+		{
+			name: "Two files for one subject - Error",
+			files: map[string]string{
+				"orders.json":     `{"type": "object"}`,
+				"old/orders.avsc": `{"type": "string"}`,
+			},
+			naming:      DefaultNaming,
+			expectError: true,
+		},
+		{
+			name: "Sidecar without a schema file - Error",
+			files: map[string]string{
+				"orders.subject.json": `{"config": {"compatibility": "FULL"}}`,
+			},
+			naming:      DefaultNaming,
+			expectError: true,
+		},
+		{
+			name:        "Naming convention without placeholder - Error",
+			files:       map[string]string{"orders.json": `{}`},
+			naming:      "orders-value",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemas, err := Load(writeFiles(t, tt.files), tt.naming)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Load() expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+
+			var subjects, schemaTypes []string
+			for _, schema := range schemas {
+				subjects = append(subjects, schema.Subject)
+				schemaTypes = append(schemaTypes, schema.Schema.SchemaType)
+			}
+			if !reflect.DeepEqual(subjects, tt.expectedSubjects) {
+				t.Errorf("Load() subjects = %v, want %v", subjects, tt.expectedSubjects)
+			}
+			if !reflect.DeepEqual(schemaTypes, tt.expectedTypes) {
+				t.Errorf("Load() schema types = %v, want %v", schemaTypes, tt.expectedTypes)
+			}
+		})
+	}
+}
+
+// fakeRegistry answers from subjects kept in memory, refusing proposed schemas
+// holding "incompatible"
+type fakeRegistry struct {
+	versions map[string][]types.Schema
+	configs  map[string]types.SubjectConfig
+	global   types.GlobalConfig
+	// Whether each compatibility check was against the latest version only
+	latestOnly map[string]bool
+}
+
+func (f *fakeRegistry) ReturnSubjects(ctx context.Context) ([]string, error) {
+	var subjects []string
+	for subjectName := range f.versions {
+		subjects = append(subjects, subjectName)
+	}
+	return subjects, nil
+}
+
+func (f *fakeRegistry) ReturnSubjectConfigs(ctx context.Context, subjectNames []string) ([]types.SubjectConfigInterface, error) {
+	configs := make([]types.SubjectConfigInterface, len(subjectNames))
+	for i, subjectName := range subjectNames {
+		configs[i] = types.SubjectGlobalConfig{Name: subjectName, TakesGlobalDefault: true}
+		if config, ok := f.configs[subjectName]; ok {
+			config.SetDefaultNone()
+			configs[i] = config
+		}
+	}
+	return configs, nil
+}
+
+func (f *fakeRegistry) GetGlobalConfig(ctx context.Context) (types.GlobalConfig, error) {
+	global := f.global
+	global.SetDefaultNone()
+	return global, nil
+}
+
+func (f *fakeRegistry) GetSchemas(ctx context.Context, subjectName string, includeDeleted bool) ([]types.Schema, error) {
+	var listed []types.Schema
+	for _, version := range f.versions[subjectName] {
+		listed = append(listed, types.Schema{Subject: subjectName, Version: version.Version})
+	}
+	return listed, nil
+}
+
+func (f *fakeRegistry) GetSubjectVersion(ctx context.Context, subjectName string, version int, includeDeleted bool) (types.Schema, error) {
+	for _, schema := range f.versions[subjectName] {
+		if schema.Version == version {
+			return schema, nil
+		}
+	}
+	return types.Schema{}, fmt.Errorf("version %d of %s not found", version, subjectName)
+}
+
+func (f *fakeRegistry) ResolveReferences(ctx context.Context, schema types.Schema) ([]types.ResolvedReference, error) {
+	return nil, nil
+}
+
+func (f *fakeRegistry) TestSchemaAgainstVersions(ctx context.Context, subjectName string, latestOnly bool, proposed types.Schema) (types.Response, error) {
+	f.latestOnly[subjectName] = latestOnly
+
+	isCompatible := !strings.Contains(proposed.Schema, "incompatible")
+	response := types.Response{IsCompatible: &isCompatible, Message: "Compatible with version 1"}
+	if !isCompatible {
+		response.Message = "Incompatible with version 1"
+		response.Versions = []types.VersionCompatibility{{Version: 1, Response: types.Response{
+			Incompatibilities: []types.Incompatibility{{Type: "TYPE_CHANGED", Path: "#/type", Description: "The type changed"}},
+		}}}
+	}
+	return response, nil
+}
+
+func TestCompare(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		// Formatting differs from the registry, the schema does not
+		"orders.json":           "{\n  \"type\": \"object\"\n}\n",
+		"payments.json":         `{"type": "number"}`,
+		"users.json":            `{"type": "string", "description": "incompatible"}`,
+		"users.subject.json":    `{"config": {"compatibility": "FULL_TRANSITIVE"}}`,
+		"items.json":            `{"type": "array"}`,
+		"items.subject.json":    `{"config": {"compatibility": "BACKWARD", "normalize": true}}`,
+		"legacy.json":           `{"type": "boolean"}`,
+		"broken.json":           `{"type": `,
+		"invoices.json":         `{"type": "integer"}`,
+		"invoices.subject.json": `{"config": {"compatibility": "FULL"}}`,
+	})
+	schemas, err := Load(dir, DefaultNaming)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	jsonSchema := func(version int, text string) types.Schema {
+		return types.Schema{Version: version, Id: version, SchemaType: types.SchemaTypeJSON, Schema: text}
+	}
+	registry := &fakeRegistry{
+		versions: map[string][]types.Schema{
+			"orders":   {jsonSchema(1, `{"type":"object"}`)},
+			"payments": {jsonSchema(1, `{"type": "integer"}`)},
+			"users":    {jsonSchema(1, `{"type": "string"}`)},
+			"items":    {jsonSchema(1, `{"type": "string"}`), jsonSchema(2, `{"type": "array"}`)},
+			"legacy":   {jsonSchema(1, `{"type": "boolean"}`), jsonSchema(2, `{"type": "null"}`)},
+			"old":      {jsonSchema(1, `{"type": "null"}`)},
+		},
+		configs:    map[string]types.SubjectConfig{"users": {CompatibilityLevel: "FULL_TRANSITIVE"}, "items": {CompatibilityLevel: "FULL"}},
+		global:     types.GlobalConfig{CompatibilityLevel: "FULL"},
+		latestOnly: map[string]bool{},
+	}
+
+	report, err := Compare(context.Background(), registry, schemas)
+	if err != nil {
+		t.Fatalf("Compare() unexpected error: %v", err)
+	}
+
+	compatible, incompatible := true, false
+	expected := []SubjectDiff{
+		{Subject: "broken", Path: "broken.json", Status: StatusNewSubject, Verdict: &Verdict{IsCompatible: &incompatible}},
+		{Subject: "invoices", Path: "invoices.json", Status: StatusNewSubject, Verdict: &Verdict{IsCompatible: &compatible}},
+		{Subject: "items", Path: "items.json", Status: StatusInSync, LatestVersion: 2, MatchedVersion: 2,
+			ConfigDrift: []ConfigDrift{
+				{Setting: "compatibility", Local: "BACKWARD", Registry: "FULL"},
+				{Setting: "normalize", Local: "true", Registry: "unset"},
+			}},
+		{Subject: "legacy", Path: "legacy.json", Status: StatusBehind, LatestVersion: 2, MatchedVersion: 1},
+		{Subject: "orders", Path: "orders.json", Status: StatusInSync, LatestVersion: 1, MatchedVersion: 1},
+		{Subject: "payments", Path: "payments.json", Status: StatusNewVersion, LatestVersion: 1,
+			Verdict: &Verdict{IsCompatible: &compatible, Level: "FULL"}},
+		{Subject: "users", Path: "users.json", Status: StatusNewVersion, LatestVersion: 1,
+			Verdict: &Verdict{IsCompatible: &incompatible, Level: "FULL_TRANSITIVE",
+				Incompatibilities: []types.Incompatibility{{Type: "TYPE_CHANGED", Path: "#/type", Description: "The type changed"}}}},
+		{Subject: "old", Status: StatusOnlyInRegistry},
+	}
+
+	// Messages are worded by the registry, only their presence is checked
+	for i := range report.Subjects {
+		if verdict := report.Subjects[i].Verdict; verdict != nil {
+			if verdict.Message == "" {
+				t.Errorf("Compare() verdict of %s has no message", report.Subjects[i].Subject)
+			}
+			verdict.Message = ""
+		}
+	}
+	if !reflect.DeepEqual(report.Subjects, expected) {
+		t.Errorf("Compare() = %+v, want %+v", report.Subjects, expected)
+	}
+
+	if !registry.latestOnly["payments"] || registry.latestOnly["users"] {
+		t.Errorf("latestOnly = %v, want payments checked against the latest version and users against all", registry.latestOnly)
+	}
+	if failed := report.Failed(); failed != 2 {
+		t.Errorf("Failed() = %d, want 2", failed)
+	}
+
+	var text strings.Builder
+	if err := report.WriteText(&text); err != nil {
+		t.Fatalf("WriteText() unexpected error: %v", err)
+	}
+	for _, line := range []string{
+		"! users (users.json): new version after version 1",
+		"    TYPE_CHANGED at #/type: The type changed",
+		"    config normalize: true locally, unset in the registry",
+		"< legacy (legacy.json): matches version 1, the registry is at version 2",
+		"- old: only in the registry",
+		"2 new subjects, 2 new versions, 2 in sync, 1 behind, 1 only in the registry, 1 with config drift, 2 refused or unchecked",
+	} {
+		if !strings.Contains(text.String(), line) {
+			t.Errorf("WriteText() = %q, want a line with %q", text.String(), line)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package schemaDirectory compares a directory of schema files, kept as code, with
+// the subjects of a registry.
+//
+// Schema files are named after their subject: the file name without its extension
+// is put into a naming convention such as "{name}-value", so orders.avsc maps to the
+// subject orders-value. The extension gives the schema type:
+//
+//	.json    JSON Schema
+//	.avsc    Avro
+//	.proto   Protobuf
+//
+// A schema file can have a sidecar named after it with the .subject.json extension,
+// e.g. orders.subject.json, holding the config of the subject and the references of
+// the schema:
+//
+//	{"config": {"compatibility": "FULL"}, "references": [{"name": "Payment", "subject": "payments-value", "version": 2}]}
+package schemaDirectory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"kafka-board/types"
+)
+
+// DefaultNaming maps files to the subject of the same name
+const DefaultNaming = "{name}"
+
+// namePlaceholder is replaced by the file name in a naming convention
+const namePlaceholder = "{name}"
+
+// sidecarExtension ends the files holding the config and references of a schema file
+const sidecarExtension = ".subject.json"
+
+// schemaTypes maps the extensions of schema files to their schema type
+var schemaTypes = map[string]string{
+	".json":  types.SchemaTypeJSON,
+	".avsc":  types.SchemaTypeAvro,
+	".proto": types.SchemaTypeProtobuf,
+}
+
+// LocalSchema is a schema file mapped to its subject
+type LocalSchema struct {
+	Subject string `json:"subject"`
+	// Path of the file, relative to the directory
+	Path   string       `json:"path"`
+	Schema types.Schema `json:"-"`
+	// Config from the sidecar, nil when there is none or it sets no config
+	Config *types.ConfigPayload `json:"config,omitempty"`
+}
+
+// sidecar is the content of a .subject.json file
+type sidecar struct {
+	Config     *types.ConfigPayload    `json:"config"`
+	References []types.SchemaReference `json:"references"`
+}
+
+// ValidateNaming checks that a naming convention holds the {name} placeholder
+func ValidateNaming(naming string) error {
+	if strings.Count(naming, namePlaceholder) != 1 {
+		return fmt.Errorf("naming convention %q must hold %s once", naming, namePlaceholder)
+	}
+	return nil
+}
+
+// Load reads every schema file under a directory and maps it to its subject with
+// the naming convention. Hidden files and directories, e.g. .git, and files of
+// other extensions are skipped. Two files mapping to the same subject, or a sidecar
+// without its schema file, fail the load.
+func Load(dir string, naming string) ([]LocalSchema, error) {
+	if err := ValidateNaming(naming); err != nil {
+		return nil, err
+	}
+
+	var schemas []LocalSchema
+	sidecars := make(map[string]string)
+	bySubject := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), ".") && filePath != dir {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		relative, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(entry.Name(), sidecarExtension) {
+			sidecars[strings.TrimSuffix(relative, sidecarExtension)] = relative
+			return nil
+		}
+
+		extension := filepath.Ext(entry.Name())
+		schemaType, ok := schemaTypes[extension]
+		if !ok {
+			return nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		subject := strings.Replace(naming, namePlaceholder, strings.TrimSuffix(entry.Name(), extension), 1)
+		if other, ok := bySubject[subject]; ok {
+			return fmt.Errorf("%s and %s both map to subject %s", other, relative, subject)
+		}
+		bySubject[subject] = relative
+
+		schemas = append(schemas, LocalSchema{
+			Subject: subject,
+			Path:    relative,
+			Schema: types.Schema{
+				Subject:    subject,
+				SchemaType: schemaType,
+				Schema:     string(content),
+			},
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema directory: %v", err)
+	}
+
+	for i, schema := range schemas {
+		base := strings.TrimSuffix(schema.Path, filepath.Ext(schema.Path))
+		sidecarPath, ok := sidecars[base]
+		if !ok {
+			continue
+		}
+		delete(sidecars, base)
+
+		content, err := os.ReadFile(filepath.Join(dir, sidecarPath))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", sidecarPath, err)
+		}
+		var parsed sidecar
+		if err := json.Unmarshal(content, &parsed); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %v", sidecarPath, err)
+		}
+
+		schemas[i].Config = parsed.Config
+		schemas[i].Schema.References = parsed.References
+	}
+
+	for _, sidecarPath := range sidecars {
+		return nil, fmt.Errorf("%s has no schema file next to it", sidecarPath)
+	}
+
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Subject < schemas[j].Subject })
+
+	return schemas, nil
+}
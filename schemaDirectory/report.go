@@ -0,0 +1,76 @@
+package schemaDirectory
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText writes a report as one line per subject, prefixed with + for a new
+// subject, ~ for a compatible new version, ! for a schema that would be refused,
+// < for a schema behind the registry, = for a subject in sync and - for a subject
+// only in the registry. Incompatibilities and config drift follow on indented
+// lines, and a summary line ends the report.
+func (r Report) WriteText(w io.Writer) error {
+	counts := make(map[string]int)
+	drifted := 0
+
+	var b strings.Builder
+	for _, subject := range r.Subjects {
+		counts[subject.Status]++
+
+		name := subject.Subject
+		if subject.Path != "" {
+			name = fmt.Sprintf("%s (%s)", subject.Subject, subject.Path)
+		}
+
+		switch subject.Status {
+		case StatusNewSubject:
+			fmt.Fprintf(&b, "%s %s: new subject, %s\n", verdictPrefix(subject.Verdict, "+"), name, subject.Verdict.Message)
+		case StatusNewVersion:
+			fmt.Fprintf(&b, "%s %s: new version after version %d, %s (%s)\n",
+				verdictPrefix(subject.Verdict, "~"), name, subject.LatestVersion, subject.Verdict.Message, subject.Verdict.Level)
+		case StatusBehind:
+			fmt.Fprintf(&b, "< %s: matches version %d, the registry is at version %d\n", name, subject.MatchedVersion, subject.LatestVersion)
+		case StatusInSync:
+			fmt.Fprintf(&b, "= %s: in sync with version %d\n", name, subject.MatchedVersion)
+		case StatusOnlyInRegistry:
+			fmt.Fprintf(&b, "- %s: only in the registry\n", name)
+		}
+
+		if subject.Verdict != nil {
+			for _, incompatibility := range subject.Verdict.Incompatibilities {
+				location := ""
+				if incompatibility.Path != "" {
+					location = " at " + incompatibility.Path
+				}
+				fmt.Fprintf(&b, "    %s%s: %s\n", incompatibility.Type, location, incompatibility.Description)
+			}
+		}
+
+		if len(subject.ConfigDrift) > 0 {
+			drifted++
+		}
+		for _, drift := range subject.ConfigDrift {
+			registry := drift.Registry
+			if drift.Global {
+				registry += " (global)"
+			}
+			fmt.Fprintf(&b, "    config %s: %s locally, %s in the registry\n", drift.Setting, drift.Local, registry)
+		}
+	}
+
+	fmt.Fprintf(&b, "%d new subjects, %d new versions, %d in sync, %d behind, %d only in the registry, %d with config drift, %d refused or unchecked\n",
+		counts[StatusNewSubject], counts[StatusNewVersion], counts[StatusInSync], counts[StatusBehind], counts[StatusOnlyInRegistry], drifted, r.Failed())
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// verdictPrefix returns the line prefix of a proposed schema, ! unless it is compatible
+func verdictPrefix(verdict *Verdict, compatible string) string {
+	if verdict != nil && verdict.IsCompatible != nil && *verdict.IsCompatible {
+		return compatible
+	}
+	return "!"
+}
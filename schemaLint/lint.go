@@ -0,0 +1,242 @@
+// Package schemaLint checks schemas against style rules, such as documented and
+// typed properties or a naming convention for field names.
+//
+// Each finding carries the ID of the rule that raised it, the severity configured
+// for that rule and a JSON pointer to the offending schema. Rules are configured
+// with a RuleSet, loaded from a JSON file or the defaults.
+package schemaLint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"kafka-board/types"
+)
+
+// ErrUnsupportedSchemaType is returned for schema types without lint rules
+var ErrUnsupportedSchemaType = errors.New("schema type not supported")
+
+// Kinds of subschemas, rules about properties or documentation only look at some
+const (
+	kindRoot       = "root"
+	kindProperty   = "property"
+	kindDefinition = "definition"
+	kindOther      = "other"
+)
+
+// subschema is a schema found while walking a JSON schema
+type subschema struct {
+	Path string
+	Kind string
+	// Property or definition name
+	Name   string
+	Schema any
+}
+
+// rule checks one subschema, calling report for each problem found
+type rule func(s subschema, config RuleConfig, report func(path string, message string))
+
+// rules holds every rule by ID, run in the order of ruleOrder
+var rules = map[string]rule{
+	RuleDescriptionRequired:     checkDescription,
+	RuleAdditionalPropertiesSet: checkAdditionalProperties,
+	RulePropertyNaming:          checkPropertyNaming,
+	RulePropertyTyped:           checkPropertyTyped,
+}
+
+var ruleOrder = []string{RulePropertyTyped, RulePropertyNaming, RuleDescriptionRequired, RuleAdditionalPropertiesSet}
+
+// Lint checks a schema against the rules of ruleSet. Only JSON schemas have lint
+// rules, other schema types return ErrUnsupportedSchemaType.
+func Lint(schemaType string, schema string, ruleSet RuleSet) (types.LintReport, error) {
+	if schemaType != types.SchemaTypeJSON {
+		return types.LintReport{}, fmt.Errorf("%w: %s", ErrUnsupportedSchemaType, schemaType)
+	}
+
+	var parsed any
+	if err := json.Unmarshal([]byte(schema), &parsed); err != nil {
+		return types.LintReport{}, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	report := types.LintReport{Findings: []types.LintFinding{}}
+	walk(subschema{Path: "#", Kind: kindRoot, Schema: parsed}, func(s subschema) {
+		for _, id := range ruleOrder {
+			config := ruleSet.Rules[id]
+			if config.Severity == "" || config.Severity == SeverityOff {
+				continue
+			}
+
+			rules[id](s, config, func(path string, message string) {
+				report.Findings = append(report.Findings, types.LintFinding{
+					Rule:     id,
+					Severity: config.Severity,
+					Path:     path,
+					Message:  message,
+				})
+				switch config.Severity {
+				case SeverityError:
+					report.Errors++
+				case SeverityWarning:
+					report.Warnings++
+				}
+			})
+		}
+	})
+
+	return report, nil
+}
+
+// walk visits a schema and every subschema it holds
+func walk(s subschema, visit func(subschema)) {
+	visit(s)
+
+	schema, ok := s.Schema.(map[string]any)
+	if !ok {
+		return
+	}
+
+	for _, keyword := range sortedKeys(schema) {
+		value := schema[keyword]
+		path := childPath(s.Path, keyword)
+
+		switch keyword {
+		case "properties", "definitions", "$defs":
+			kind := kindProperty
+			if keyword != "properties" {
+				kind = kindDefinition
+			}
+			named, _ := value.(map[string]any)
+			for _, name := range sortedKeys(named) {
+				walk(subschema{Path: childPath(path, name), Kind: kind, Name: name, Schema: named[name]}, visit)
+			}
+		case "patternProperties", "dependentSchemas":
+			named, _ := value.(map[string]any)
+			for _, name := range sortedKeys(named) {
+				walk(subschema{Path: childPath(path, name), Kind: kindOther, Schema: named[name]}, visit)
+			}
+		case "items", "prefixItems", "allOf", "anyOf", "oneOf":
+			if list, ok := value.([]any); ok {
+				for i, item := range list {
+					walk(subschema{Path: childPath(path, fmt.Sprintf("%d", i)), Kind: kindOther, Schema: item}, visit)
+				}
+				continue
+			}
+			walk(subschema{Path: path, Kind: kindOther, Schema: value}, visit)
+		case "additionalProperties", "additionalItems", "contains", "propertyNames", "not", "if", "then", "else":
+			walk(subschema{Path: path, Kind: kindOther, Schema: value}, visit)
+		}
+	}
+}
+
+// checkDescription requires a description on the root schema, properties and
+// definitions. Properties holding only a reference take the description of the
+// referenced schema.
+func checkDescription(s subschema, config RuleConfig, report func(string, string)) {
+	if s.Kind == kindOther {
+		return
+	}
+	schema, ok := s.Schema.(map[string]any)
+	if !ok {
+		return
+	}
+	if _, ok := schema["description"]; ok {
+		return
+	}
+	if _, ok := schema["$ref"]; ok && s.Kind == kindProperty {
+		return
+	}
+
+	switch s.Kind {
+	case kindRoot:
+		report(s.Path, "Schema has no description")
+	case kindProperty:
+		report(s.Path, fmt.Sprintf("Property %s has no description", s.Name))
+	case kindDefinition:
+		report(s.Path, fmt.Sprintf("Definition %s has no description", s.Name))
+	}
+}
+
+// checkAdditionalProperties requires objects to say whether they accept properties
+// they do not list
+func checkAdditionalProperties(s subschema, config RuleConfig, report func(string, string)) {
+	schema, ok := s.Schema.(map[string]any)
+	if !ok || !isObjectSchema(schema) {
+		return
+	}
+	if _, ok := schema["additionalProperties"]; ok {
+		return
+	}
+	if _, ok := schema["unevaluatedProperties"]; ok {
+		return
+	}
+
+	report(s.Path, "Object does not set additionalProperties, so any other property is accepted")
+}
+
+// checkPropertyNaming requires property names to follow the configured case
+func checkPropertyNaming(s subschema, config RuleConfig, report func(string, string)) {
+	if s.Kind != kindProperty {
+		return
+	}
+	pattern := namingCases[config.Case]
+	if pattern == nil || pattern.MatchString(s.Name) {
+		return
+	}
+
+	report(s.Path, fmt.Sprintf("Property %s is not in %s", s.Name, config.Case))
+}
+
+// checkPropertyTyped requires properties to have a type, or a keyword that
+// constrains their values to known types
+func checkPropertyTyped(s subschema, config RuleConfig, report func(string, string)) {
+	if s.Kind != kindProperty {
+		return
+	}
+	if schema, ok := s.Schema.(map[string]any); ok {
+		for _, keyword := range []string{"type", "$ref", "enum", "const", "allOf", "anyOf", "oneOf"} {
+			if _, ok := schema[keyword]; ok {
+				return
+			}
+		}
+	} else if s.Schema == false {
+		// false forbids the property, there are no values to type
+		return
+	}
+
+	report(s.Path, fmt.Sprintf("Property %s has no type, any value is accepted", s.Name))
+}
+
+// isObjectSchema reports whether a schema describes objects
+func isObjectSchema(schema map[string]any) bool {
+	switch schemaType := schema["type"].(type) {
+	case string:
+		return schemaType == "object"
+	case []any:
+		for _, t := range schemaType {
+			if t == "object" {
+				return true
+			}
+		}
+		return false
+	}
+	_, hasProperties := schema["properties"]
+	return hasProperties
+}
+
+// pointerEscaper escapes a token of a JSON pointer
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// childPath appends a token to a JSON pointer, escaping it
+func childPath(path string, token string) string {
+	return path + "/" + pointerEscaper.Replace(token)
+}
+
+// sortedKeys returns the keys of a map, sorted, so subschemas are walked and
+// reported in a stable order
+func sortedKeys(m map[string]any) []string {
+	return slices.Sorted(maps.Keys(m))
+}
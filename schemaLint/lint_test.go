@@ -0,0 +1,133 @@
+package schemaLint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"kafka-board/types"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name             string
+		schema           string
+		ruleSet          RuleSet
+		expectedFindings []types.LintFinding
+	}{
+		{
+			name:             "schema following every rule",
+			schema:           `{"description": "An order", "type": "object", "additionalProperties": false, "properties": {"orderId": {"type": "string", "description": "Order ID"}}}`,
+			ruleSet:          DefaultRuleSet(),
+			expectedFindings: []types.LintFinding{},
+		},
+		{
+			name:    "undocumented, untyped and badly named properties",
+			schema:  `{"description": "An order", "type": "object", "additionalProperties": false, "properties": {"order_id": {"description": "Order ID"}, "total": {"type": "number"}}}`,
+			ruleSet: DefaultRuleSet(),
+			expectedFindings: []types.LintFinding{
+				{Rule: RulePropertyTyped, Severity: SeverityError, Path: "#/properties/order_id", Message: "Property order_id has no type, any value is accepted"},
+				{Rule: RulePropertyNaming, Severity: SeverityWarning, Path: "#/properties/order_id", Message: "Property order_id is not in camelCase"},
+				{Rule: RuleDescriptionRequired, Severity: SeverityWarning, Path: "#/properties/total", Message: "Property total has no description"},
+			},
+		},
+		{
+			name:    "nested objects without additionalProperties",
+			schema:  `{"description": "An order", "type": "object", "properties": {"address": {"$ref": "#/definitions/address"}}, "definitions": {"address": {"description": "An address", "type": "object", "properties": {}}}}`,
+			ruleSet: DefaultRuleSet(),
+			expectedFindings: []types.LintFinding{
+				{Rule: RuleAdditionalPropertiesSet, Severity: SeverityWarning, Path: "#", Message: "Object does not set additionalProperties, so any other property is accepted"},
+				{Rule: RuleAdditionalPropertiesSet, Severity: SeverityWarning, Path: "#/definitions/address", Message: "Object does not set additionalProperties, so any other property is accepted"},
+			},
+		},
+		{
+			name:   "configured case and rules turned off",
+			schema: `{"type": "object", "properties": {"orderId": {"type": "string"}, "order_total": {"type": "number"}}}`,
+			ruleSet: RuleSet{Rules: map[string]RuleConfig{
+				RulePropertyNaming:          {Severity: SeverityError, Case: "snake_case"},
+				RuleDescriptionRequired:     {Severity: SeverityOff},
+				RuleAdditionalPropertiesSet: {Severity: SeverityOff},
+			}},
+			expectedFindings: []types.LintFinding{
+				{Rule: RulePropertyNaming, Severity: SeverityError, Path: "#/properties/orderId", Message: "Property orderId is not in snake_case"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Lint(types.SchemaTypeJSON, tt.schema, tt.ruleSet)
+			if err != nil {
+				t.Fatalf("Lint() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(report.Findings, tt.expectedFindings) {
+				t.Errorf("Findings = %+v, want %+v", report.Findings, tt.expectedFindings)
+			}
+		})
+	}
+
+	t.Run("avro schemas are not linted", func(t *testing.T) {
+		_, err := Lint(types.SchemaTypeAvro, `{"type": "string"}`, DefaultRuleSet())
+		if !errors.Is(err, ErrUnsupportedSchemaType) {
+			t.Errorf("Lint() error = %v, want %v", err, ErrUnsupportedSchemaType)
+		}
+	})
+}
+
+func TestLoadRuleSet(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		expectedNaming RuleConfig
+		expectedTyped  RuleConfig
+		expectedErr    bool
+	}{
+		{
+			name:           "rules override the defaults",
+			content:        `{"rules": {"property-naming": {"case": "snake_case"}, "property-typed": {"severity": "warning"}}}`,
+			expectedNaming: RuleConfig{Severity: SeverityWarning, Case: "snake_case"},
+			expectedTyped:  RuleConfig{Severity: SeverityWarning},
+		},
+		{
+			name:        "unknown rule",
+			content:     `{"rules": {"no-tabs": {"severity": "error"}}}`,
+			expectedErr: true,
+		},
+		{
+			name:        "unknown severity",
+			content:     `{"rules": {"property-typed": {"severity": "fatal"}}}`,
+			expectedErr: true,
+		},
+		{
+			name:        "unknown case",
+			content:     `{"rules": {"property-naming": {"case": "SCREAMING_CASE"}}}`,
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "lint.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			ruleSet, err := LoadRuleSet(path)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("LoadRuleSet() error = %v, want error %t", err, tt.expectedErr)
+			}
+			if tt.expectedErr {
+				return
+			}
+
+			if ruleSet.Rules[RulePropertyNaming] != tt.expectedNaming {
+				t.Errorf("%s = %+v, want %+v", RulePropertyNaming, ruleSet.Rules[RulePropertyNaming], tt.expectedNaming)
+			}
+			if ruleSet.Rules[RulePropertyTyped] != tt.expectedTyped {
+				t.Errorf("%s = %+v, want %+v", RulePropertyTyped, ruleSet.Rules[RulePropertyTyped], tt.expectedTyped)
+			}
+		})
+	}
+}
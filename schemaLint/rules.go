@@ -0,0 +1,120 @@
+package schemaLint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+)
+
+// Rule IDs
+const (
+	RuleDescriptionRequired     = "description-required"
+	RuleAdditionalPropertiesSet = "additional-properties-set"
+	RulePropertyNaming          = "property-naming"
+	RulePropertyTyped           = "property-typed"
+)
+
+// Severities of a rule. Rules set to off are not run.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+	SeverityOff     = "off"
+)
+
+var severities = []string{SeverityError, SeverityWarning, SeverityInfo, SeverityOff}
+
+// namingCases are the naming conventions property-naming can enforce
+var namingCases = map[string]*regexp.Regexp{
+	"camelCase":  regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	"PascalCase": regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+	"snake_case": regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+	"kebab-case": regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`),
+}
+
+// RuleConfig configures one rule
+type RuleConfig struct {
+	Severity string `json:"severity"`
+	// Naming convention enforced by property-naming: camelCase, PascalCase,
+	// snake_case or kebab-case
+	Case string `json:"case,omitempty"`
+}
+
+// RuleSet holds the configuration of every rule by rule ID
+type RuleSet struct {
+	Rules map[string]RuleConfig `json:"rules"`
+}
+
+// DefaultRuleSet runs every rule, with property names in camelCase
+func DefaultRuleSet() RuleSet {
+	return RuleSet{Rules: map[string]RuleConfig{
+		RuleDescriptionRequired:     {Severity: SeverityWarning},
+		RuleAdditionalPropertiesSet: {Severity: SeverityWarning},
+		RulePropertyNaming:          {Severity: SeverityWarning, Case: "camelCase"},
+		RulePropertyTyped:           {Severity: SeverityError},
+	}}
+}
+
+// GetRuleSet returns the rule set of the JSON file named by SCHEMA_LINT_CONFIG_FILE,
+// or the default rule set when it is not set
+func GetRuleSet() (RuleSet, error) {
+	path := os.Getenv("SCHEMA_LINT_CONFIG_FILE")
+	if path == "" {
+		return DefaultRuleSet(), nil
+	}
+	return LoadRuleSet(path)
+}
+
+// LoadRuleSet reads a rule set from a JSON file, e.g.
+//
+//	{"rules": {"property-naming": {"severity": "error", "case": "snake_case"}}}
+//
+// Rules missing from the file keep their default configuration, and the fields left
+// empty in a rule keep their default value.
+func LoadRuleSet(path string) (RuleSet, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return RuleSet{}, fmt.Errorf("error reading lint config file: %v", err)
+	}
+
+	var file RuleSet
+	if err := json.Unmarshal(content, &file); err != nil {
+		return RuleSet{}, fmt.Errorf("error parsing lint config file: %v", err)
+	}
+
+	ruleSet := DefaultRuleSet()
+	for id, config := range file.Rules {
+		rule, ok := ruleSet.Rules[id]
+		if !ok {
+			return RuleSet{}, fmt.Errorf("unknown lint rule %s in %s", id, path)
+		}
+		if config.Severity != "" {
+			rule.Severity = config.Severity
+		}
+		if config.Case != "" {
+			rule.Case = config.Case
+		}
+		ruleSet.Rules[id] = rule
+	}
+
+	if err := ruleSet.validate(); err != nil {
+		return RuleSet{}, fmt.Errorf("invalid lint config file %s: %w", path, err)
+	}
+
+	return ruleSet, nil
+}
+
+// validate checks the severity and options of every rule
+func (rs RuleSet) validate() error {
+	for id, config := range rs.Rules {
+		if !slices.Contains(severities, config.Severity) {
+			return fmt.Errorf("rule %s has an unknown severity %q", id, config.Severity)
+		}
+		if id == RulePropertyNaming && namingCases[config.Case] == nil {
+			return fmt.Errorf("rule %s has an unknown case %q", id, config.Case)
+		}
+	}
+	return nil
+}
@@ -1,17 +1,94 @@
 package types
 
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Schema types as reported by the schema registry
+const (
+	SchemaTypeAvro     = "AVRO"
+	SchemaTypeJSON     = "JSON"
+	SchemaTypeProtobuf = "PROTOBUF"
+)
+
+// Registry modes. New schemas are only registered in READWRITE, IMPORT takes schemas
+// with their IDs, e.g. during migrations, and the READONLY modes refuse every write.
+const (
+	ModeReadWrite        = "READWRITE"
+	ModeReadOnly         = "READONLY"
+	ModeReadOnlyOverride = "READONLY_OVERRIDE"
+	ModeImport           = "IMPORT"
+)
+
+// Modes lists the modes a registry or subject can be set to
+var Modes = []string{ModeReadWrite, ModeReadOnly, ModeReadOnlyOverride, ModeImport}
+
+// DefaultContext is the schema context of subjects named without one
+const DefaultContext = "."
+
+// SplitContext splits a context-qualified subject name, e.g. ":.team-a:orders", into
+// its context ".team-a" and subject "orders". Names without a context, and names
+// qualified with the default context ":.:orders", are in DefaultContext.
+func SplitContext(subjectName string) (string, string) {
+	if !strings.HasPrefix(subjectName, ":.") {
+		return DefaultContext, subjectName
+	}
+
+	context, subject, found := strings.Cut(subjectName[1:], ":")
+	if !found {
+		return DefaultContext, subjectName
+	}
+	return context, subject
+}
+
 // Schema is the struct for the schema registry schema model
 type Schema struct {
-	Name       string `json:"name"`
-	Subject    string `json:"subject"`
-	Version    int    `json:"version"`
-	Id         int    `json:"id"`
-	SchemaType string `json:"schemaType"`
-	Schema     string `json:"schema"`
+	Name       string            `json:"name"`
+	Subject    string            `json:"subject"`
+	Version    int               `json:"version"`
+	Id         int               `json:"id"`
+	SchemaType string            `json:"schemaType"`
+	Schema     string            `json:"schema"`
+	References []SchemaReference `json:"references,omitempty"`
+	// Set on soft-deleted versions, the registry only lists them with deleted=true
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// SchemaReference is the struct for a reference from a schema to a version of another subject.
+// Name is the import path for Protobuf, the type name for Avro and the $ref URL for JSON Schema.
+type SchemaReference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// ResolvedReference is a schema reached through the references of another schema
+type ResolvedReference struct {
+	Name   string
+	Schema Schema
 }
 
+// GetSchemaType returns the schema type, defaulting to AVRO as the registry
+// omits schemaType for Avro schemas
+func (s Schema) GetSchemaType() string {
+	if s.SchemaType == "" {
+		return SchemaTypeAvro
+	}
+	return s.SchemaType
+}
+
+// ConfigPayload is the body of a config update. Settings left empty are not sent.
 type ConfigPayload struct {
-	Compatibility string `json:"compatibility"`
+	Compatibility      string `json:"compatibility,omitempty"`
+	Normalize          *bool  `json:"normalize,omitempty"`
+	Alias              string `json:"alias,omitempty"`
+	CompatibilityGroup string `json:"compatibilityGroup,omitempty"`
+	DefaultMetadata    any    `json:"defaultMetadata,omitempty"`
+	OverrideMetadata   any    `json:"overrideMetadata,omitempty"`
+	DefaultRuleSet     any    `json:"defaultRuleSet,omitempty"`
+	OverrideRuleSet    any    `json:"overrideRuleSet,omitempty"`
 }
 
 // SubjectConfig is the struct for the subject config model
@@ -33,6 +110,37 @@ type SubjectGlobalConfig struct {
 	TakesGlobalDefault bool   `json:"takesGlobalDefault"`
 }
 
+// BreakerStatus is the state of the circuit breaker of a registry
+type BreakerStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	OpenUntil           *time.Time `json:"openUntil,omitempty"`
+}
+
+// SubjectConfigError is the struct for subjects whose config could not be fetched
+type SubjectConfigError struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// SubjectMode is the mode of a subject, or of the registry when Name is empty
+type SubjectMode struct {
+	Name string `json:"name"`
+	Mode string `json:"mode"`
+	// Set when the subject has no mode of its own and takes the global one
+	TakesGlobalDefault bool `json:"takesGlobalDefault"`
+	// Set when the mode could not be fetched
+	Error string `json:"error,omitempty"`
+}
+
+// EffectiveMode returns the mode applying to the subject, its own or the global one
+func (m SubjectMode) EffectiveMode(global SubjectMode) string {
+	if m.TakesGlobalDefault || m.Mode == "" {
+		return global.Mode
+	}
+	return m.Mode
+}
+
 // GlobalConfig is the struct for the global config model
 type GlobalConfig struct {
 	Name               string `json:"name"`
@@ -62,11 +170,93 @@ func (sgc SubjectGlobalConfig) GetName() string {
 	return sgc.Name
 }
 
+// Implement the interface for SubjectConfigError
+func (sce SubjectConfigError) GetName() string {
+	return sce.Name
+}
+
 type Response struct {
-	IsCompatible *bool  `json:"is_compatible"`
-	ErrorCode    int    `json:"error_code"`
-	Message      string `json:"message"`
-	StatusCode   int    `json:"http_status"`
+	IsCompatible      *bool             `json:"is_compatible"`
+	ErrorCode         int               `json:"error_code"`
+	Message           string            `json:"message"`
+	StatusCode        int               `json:"http_status"`
+	Incompatibilities []Incompatibility `json:"incompatibilities,omitempty"`
+	// Result for each version when testing against several versions of a subject
+	Versions []VersionCompatibility `json:"versions,omitempty"`
+	// Set when the schema was registered as a new version
+	Registered *RegisteredSchema `json:"registered,omitempty"`
+	// Versions removed by a delete
+	Deleted []int `json:"deleted,omitempty"`
+	// Lint findings of a tested schema
+	Lint *LintReport `json:"lint,omitempty"`
+}
+
+// LintReport lists the lint findings of a schema
+type LintReport struct {
+	Findings []LintFinding `json:"findings"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+	// Why the schema was not linted, e.g. for schema types without lint rules
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// LintFinding is one problem found by a lint rule
+type LintFinding struct {
+	// Rule ID, e.g. property-naming
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	// JSON pointer to the offending schema, e.g. #/properties/user_id
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// RegisteredSchema identifies a newly registered version of a subject
+type RegisteredSchema struct {
+	Subject string `json:"subject"`
+	Id      int    `json:"id"`
+	Version int    `json:"version"`
+}
+
+// VersionCompatibility is the result of testing a schema against one version of a subject
+type VersionCompatibility struct {
+	Version int `json:"version"`
+	Response
+}
+
+// LevelPreview lists the existing versions that would violate a compatibility level,
+// for one subject or for every subject taking the global default
+type LevelPreview struct {
+	Level    string           `json:"level"`
+	Subjects []SubjectPreview `json:"subjects"`
+	Message  string           `json:"message"`
+	// Subjects taking the global default left out, past the cap of a preview
+	Remaining int `json:"remaining,omitempty"`
+}
+
+// SubjectPreview lists the versions of a subject violating a compatibility level
+type SubjectPreview struct {
+	Subject    string           `json:"subject"`
+	Violations []LevelViolation `json:"violations"`
+	// Why the subject could not be checked, e.g. Protobuf schemas or a failed registry call
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// LevelViolation is a version that would not have been accepted at a compatibility level
+type LevelViolation struct {
+	Version int `json:"version"`
+	// Earlier versions the version is incompatible with
+	IncompatibleWith  []int             `json:"incompatibleWith"`
+	Incompatibilities []Incompatibility `json:"incompatibilities"`
+}
+
+// Incompatibility is one reason a schema is incompatible, as reported by the
+// registry when checking compatibility in verbose mode
+type Incompatibility struct {
+	// Kind of incompatibility, e.g. READER_FIELD_MISSING_DEFAULT_VALUE
+	Type string `json:"type"`
+	// Location of the incompatibility in the schema, when the registry gives one
+	Path        string `json:"path,omitempty"`
+	Description string `json:"description"`
 }
 
 // SetDefaultNone sets "None" for any unpopulated string fields in the SubjectConfig
@@ -93,3 +283,50 @@ func (sc *GlobalConfig) SetDefaultNone() {
 		sc.CompatibilityGroup = "None set"
 	}
 }
+
+// Payload returns the config as the body setting it, leaving out the placeholders
+// SetDefaultNone puts in for unset settings
+func (sc SubjectConfig) Payload() ConfigPayload {
+	unset := func(value string) string {
+		if value == "None" || value == "None set" {
+			return ""
+		}
+		return value
+	}
+
+	return ConfigPayload{
+		Compatibility:      unset(sc.CompatibilityLevel),
+		Normalize:          sc.Normalize,
+		Alias:              unset(sc.Alias),
+		CompatibilityGroup: unset(sc.CompatibilityGroup),
+		DefaultMetadata:    sc.DefaultMetadata,
+		OverrideMetadata:   sc.OverrideMetadata,
+		DefaultRuleSet:     sc.DefaultRuleSet,
+		OverrideRuleSet:    sc.OverrideRuleSet,
+	}
+}
+
+// Settings returns the settings of the config by JSON name, unset ones left out.
+// Strings are returned as they are, other values as JSON.
+func (c ConfigPayload) Settings() map[string]string {
+	settings := make(map[string]string)
+
+	content, err := json.Marshal(c)
+	if err != nil {
+		return settings
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return settings
+	}
+
+	for name, value := range fields {
+		var text string
+		if json.Unmarshal(value, &text) == nil {
+			settings[name] = text
+			continue
+		}
+		settings[name] = string(value)
+	}
+	return settings
+}